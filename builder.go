@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"os"
 	"time"
 )
 
@@ -15,6 +16,7 @@ type Builder struct {
 	pendingCmds []*Command
 	validators  []func(*Config) error
 	buildErrs   []error
+	warnings    []string
 	built       bool
 	service     ServiceRunner // 新增：支持ServiceRunner接口
 	initHooks   []InitHook
@@ -159,6 +161,13 @@ func (b *Builder) WithServiceUser(username string) *Builder {
 	return b
 }
 
+// WithCreateUser 设置安装时是否创建锁定的系统用户与同名用户组。
+// 未指定运行用户时使用服务名作为用户名。
+func (b *Builder) WithCreateUser(enabled bool) *Builder {
+	b.config.service.CreateUser = enabled
+	return b
+}
+
 // WithExecutable 设置系统服务安装时使用的可执行文件路径。
 func (b *Builder) WithExecutable(path string) *Builder {
 	b.config.service.Executable = path
@@ -178,6 +187,42 @@ func (b *Builder) WithChRoot(dir string) *Builder {
 	return b
 }
 
+// WithLayout 设置安装时由 zcli 创建、purge 时清理的目录布局。
+func (b *Builder) WithLayout(layout ServiceLayout) *Builder {
+	b.config.service.Layout = layout
+	return b
+}
+
+// WithStateDir 设置服务状态目录。
+func (b *Builder) WithStateDir(dir string) *Builder {
+	b.config.service.Layout.StateDir.Path = dir
+	return b
+}
+
+// WithLogDir 设置服务日志目录。
+func (b *Builder) WithLogDir(dir string) *Builder {
+	b.config.service.Layout.LogDir.Path = dir
+	return b
+}
+
+// WithRuntimeDir 设置服务运行时目录。
+func (b *Builder) WithRuntimeDir(dir string) *Builder {
+	b.config.service.Layout.RuntimeDir.Path = dir
+	return b
+}
+
+// WithConfigDir 设置服务配置目录。
+func (b *Builder) WithConfigDir(dir string) *Builder {
+	b.config.service.Layout.ConfigDir.Path = dir
+	return b
+}
+
+// WithBinaryPrefix 设置安装时复制可执行文件的目标目录，如 /usr/local/bin。
+func (b *Builder) WithBinaryPrefix(prefix string) *Builder {
+	b.config.service.Layout.BinaryPrefix = prefix
+	return b
+}
+
 // WithBuildTime 设置构建时间
 func (b *Builder) WithBuildTime(buildTime string) *Builder {
 	parsedTime, err := time.Parse(time.DateTime, buildTime)
@@ -196,6 +241,14 @@ func (b *Builder) WithEnvVar(key, value string) *Builder {
 	return b
 }
 
+// WithEnvFile 追加 dotenv 格式的环境变量文件，支持引号、注释、export 前缀与 ${VAR} 展开。
+// 优先级由低到高：环境变量文件、WithEnvVar、install --env-file、install --env；
+// 路径以 "-" 开头时文件不存在不视为错误。
+func (b *Builder) WithEnvFile(paths ...string) *Builder {
+	b.config.service.EnvFiles = append(b.config.service.EnvFiles, paths...)
+	return b
+}
+
 // WithDependencies 设置依赖
 func (b *Builder) WithDependencies(deps ...string) *Builder {
 	structured := make([]Dependency, 0, len(deps))
@@ -237,6 +290,12 @@ func (b *Builder) WithDependency(name string, depType DependencyType) *Builder {
 	return b
 }
 
+// WithDependencyCheck 设置 start/run 前是否检查 DependencyRequire 依赖已在运行。
+func (b *Builder) WithDependencyCheck(enabled bool) *Builder {
+	b.config.service.CheckDependencies = enabled
+	return b
+}
+
 // WithServiceOption 设置单个 daemon 平台选项。
 func (b *Builder) WithServiceOption(key string, value any) *Builder {
 	if key == "" {
@@ -261,6 +320,25 @@ func (b *Builder) WithServiceOptionsMap(options ServiceOptions) *Builder {
 	return b
 }
 
+// WithSystemdOptions 设置 systemd 平台的类型化选项，取值在构建时校验。
+func (b *Builder) WithSystemdOptions(opts SystemdOptions) *Builder {
+	opts.ReadWritePaths = append([]string(nil), opts.ReadWritePaths...)
+	b.config.service.Systemd = &opts
+	return b
+}
+
+// WithLaunchdOptions 设置 launchd 平台的类型化选项。
+func (b *Builder) WithLaunchdOptions(opts LaunchdOptions) *Builder {
+	b.config.service.Launchd = &opts
+	return b
+}
+
+// WithWindowsOptions 设置 Windows 服务的类型化选项，取值在构建时校验。
+func (b *Builder) WithWindowsOptions(opts WindowsOptions) *Builder {
+	b.config.service.Windows = &opts
+	return b
+}
+
 // WithAllowSudoFallback 设置 daemon 的 sudo/su 回退策略。
 func (b *Builder) WithAllowSudoFallback(enabled bool) *Builder {
 	b.config.service.AllowSudoFallback = enabled
@@ -285,6 +363,7 @@ func (b *Builder) WithService(run RunFunc, stop ...StopFunc) *Builder {
 	}
 	// 重置 ServiceRunner，保持与新的 run/stop 同步
 	b.service = nil
+	b.config.runtime.Pause, b.config.runtime.Resume = nil, nil
 	return b
 }
 
@@ -295,14 +374,31 @@ func (b *Builder) WithServiceRunner(service ServiceRunner) *Builder {
 		b.service = nil
 		b.config.runtime.Run = nil
 		b.config.runtime.Stop = nil
+		b.config.runtime.Pause, b.config.runtime.Resume = nil, nil
 		return b
 	}
 	b.service = service
+	b.syncServiceRunner()
+	return b
+}
 
-	// 将 ServiceRunner 直接赋值为新的标准签名
-	b.config.runtime.Run = service.Run
-	b.config.runtime.Stop = service.Stop
+// syncServiceRunner 将 ServiceRunner 赋值为运行时的标准签名，实现 Pausable 时同时接入暂停与恢复，
+// ManagedService 按其包装的 runner 判断
+func (b *Builder) syncServiceRunner() {
+	b.config.runtime.Run = b.service.Run
+	b.config.runtime.Stop = b.service.Stop
+	b.config.runtime.Pause, b.config.runtime.Resume = nil, nil
+	if pausable, ok := pausableOf(b.service); ok {
+		b.config.runtime.Pause = pausable.Pause
+		b.config.runtime.Resume = pausable.Resume
+	}
+}
 
+// WithPauseSignals 设置暂停与恢复信号，服务实现 Pausable 时运行期间收到即切换维护模式；
+// 控制通道不可用时 pause、resume 命令也会向 run --detach 的后台进程发送这些信号。nil 表示不使用
+func (b *Builder) WithPauseSignals(pause, resume os.Signal) *Builder {
+	b.config.runtime.PauseSignal = pause
+	b.config.runtime.ResumeSignal = resume
 	return b
 }
 
@@ -335,6 +431,114 @@ func (b *Builder) WithErrorHandler(handler ErrorHandler) *Builder {
 	return b
 }
 
+// WithWaitFor 追加调用 Run 前需要满足的前置条件，按声明顺序依次等待
+func (b *Builder) WithWaitFor(conditions ...WaitCondition) *Builder {
+	b.config.runtime.WaitFor = append(b.config.runtime.WaitFor, conditions...)
+	return b
+}
+
+// WithPrivilegedSetup 追加降权前执行的特权准备函数。
+// 以 root 运行 run 且配置了 WithServiceUser 时，这些函数执行完毕后进程切换到该用户再调用 Run。
+func (b *Builder) WithPrivilegedSetup(setup ...SetupFunc) *Builder {
+	for _, fn := range setup {
+		if fn != nil {
+			b.config.runtime.PrivilegedSetup = append(b.config.runtime.PrivilegedSetup, fn)
+		}
+	}
+	return b
+}
+
+// WithResourceTuning 设置调用 Run 前的进程级资源调优
+func (b *Builder) WithResourceTuning(tuning ResourceTuning) *Builder {
+	b.config.runtime.Tuning = &tuning
+	return b
+}
+
+// WithLogOutput 追加以服务方式运行时的日志输出，如 NewRotatingFile、NewSyslogSink、NewJournalSink。
+// 非前台运行时框架输出改写到这些 sink，业务代码可通过 LogWriter(ctx) 复用；前台运行时不生效。
+func (b *Builder) WithLogOutput(sinks ...LogSink) *Builder {
+	for _, sink := range sinks {
+		if sink != nil {
+			b.config.runtime.LogSinks = append(b.config.runtime.LogSinks, sink)
+		}
+	}
+	return b
+}
+
+// WithDetach 设置 run 默认以后台方式启动：脱离终端、输出写入日志文件并写入 PID 文件，
+// 适用于没有 systemd 或进程管理器的环境；命令行 --detach=false 可临时关闭
+func (b *Builder) WithDetach(detach bool) *Builder {
+	b.config.runtime.Detach = detach
+	return b
+}
+
+// WithCrashLoopDetection 启用崩溃循环检测，需配置 StateDir：由服务管理器拉起时，window 内失败 maxFailures 次后
+// 不再调用 Run，记录 crash_loop 事件并以 CrashLoopExitCode 退出，直到 start 或 restart 命令清除该状态；正常停止后重新计数
+func (b *Builder) WithCrashLoopDetection(maxFailures int, window time.Duration) *Builder {
+	b.config.runtime.CrashLoop = &CrashLoopPolicy{MaxFailures: maxFailures, Window: window}
+	return b
+}
+
+// WithLifecycleHook 追加生命周期事件回调，启动、停止、崩溃、强制退出与崩溃循环都会触发
+func (b *Builder) WithLifecycleHook(hooks ...LifecycleHook) *Builder {
+	for _, hook := range hooks {
+		if hook != nil {
+			b.config.runtime.LifecycleHooks = append(b.config.runtime.LifecycleHooks, hook)
+		}
+	}
+	return b
+}
+
+// WithMetrics 启用 Prometheus 文本格式指标：框架在 registry 中注册运行时长、启动与重启次数、启停耗时、
+// 强制退出次数、按 ErrorCode 统计的错误数、Go 运行时与构建信息；业务指标可注册到同一 registry。
+// registry 为 nil 时新建；addr 非空时运行期间在该地址的 /metrics 输出，为空时可自行挂载 registry.Handler()
+func (b *Builder) WithMetrics(registry *MetricsRegistry, addr string) *Builder {
+	if registry == nil {
+		registry = NewMetricsRegistry()
+	}
+	b.config.runtime.Metrics = registry
+	b.config.runtime.MetricsAddr = addr
+	return b
+}
+
+// WithInitMode 设置容器 init 模式：run 按前台方式执行，Linux 上由当前进程回收孤儿进程并把终止信号
+// 转发给服务进程组；install、uninstall、start、stop、restart 被隐藏并禁用。默认 InitModeAuto 在进程号为 1 时启用
+func (b *Builder) WithInitMode(mode InitMode) *Builder {
+	b.config.runtime.InitMode = mode
+	return b
+}
+
+// WithScheduledTask 添加运行期间按计划执行的任务，schedule 为五段 cron 表达式、@daily 等描述符或 @every 30s
+// 形式的固定间隔，语法见 ParseSchedule。任务在服务上下文中运行，失败交给错误处理器链并触发 task_run 事件，
+// 服务停止时先停止调度并等待进行中的运行结束；只配置计划任务而不设置 Run 也可作为服务运行
+func (b *Builder) WithScheduledTask(name, schedule string, fn TaskFunc, opts ...TaskOptions) *Builder {
+	parsed, err := ParseSchedule(schedule)
+	switch {
+	case name == "":
+		err = errors.New("scheduled task name is required")
+	case fn == nil:
+		err = fmt.Errorf("scheduled task %s: function is required", name)
+	case err != nil:
+		err = fmt.Errorf("scheduled task %s: %w", name, err)
+	}
+	for _, task := range b.config.runtime.Tasks {
+		if err == nil && task.Name == name {
+			err = fmt.Errorf("scheduled task %s is already registered", name)
+		}
+	}
+	if err != nil {
+		b.buildErrs = append(b.buildErrs, err)
+		return b
+	}
+
+	task := ScheduledTask{Name: name, Spec: schedule, Schedule: parsed, Run: fn}
+	if len(opts) > 0 {
+		task.Options = opts[0]
+	}
+	b.config.runtime.Tasks = append(b.config.runtime.Tasks, task)
+	return b
+}
+
 // WithValidator 添加配置验证器
 func (b *Builder) WithValidator(validator func(*Config) error) *Builder {
 	b.validators = append(b.validators, validator)
@@ -355,8 +559,7 @@ func (b *Builder) Build() *Cli {
 
 	// 若使用 ServiceRunner，确保 runtime 与之同步
 	if b.service != nil {
-		b.config.runtime.Run = b.service.Run
-		b.config.runtime.Stop = b.service.Stop
+		b.syncServiceRunner()
 	}
 
 	// 执行验证
@@ -377,8 +580,7 @@ func (b *Builder) BuildWithError() (*Cli, error) {
 	}
 
 	if b.service != nil {
-		b.config.runtime.Run = b.service.Run
-		b.config.runtime.Stop = b.service.Stop
+		b.syncServiceRunner()
 	}
 
 	// 执行验证
@@ -392,6 +594,11 @@ func (b *Builder) BuildWithError() (*Cli, error) {
 	return b.cli, nil
 }
 
+// Warnings 返回最近一次构建产生的告警，如未识别的平台选项键
+func (b *Builder) Warnings() []string {
+	return append([]string(nil), b.warnings...)
+}
+
 func (b *Builder) finalizeCLI() *Cli {
 	if b.cli == nil {
 		b.cli = newAppBase(b.config)
@@ -414,10 +621,37 @@ func (b *Builder) validate() error {
 	errs = append(errs, b.buildErrs...)
 
 	// 服务相关验证
-	if b.config.runtime.Run != nil && b.config.basic.Name == "" {
+	if b.config.runtime.hasService() && b.config.basic.Name == "" {
 		errs = append(errs, errors.New("service name must be set when service is configured"))
 	}
 
+	// 平台选项与实例模板验证：取值错误直接失败，未识别的原始键仅告警
+	errs = append(errs, b.config.service.validatePlatformOptions()...)
+	errs = append(errs, b.config.service.validateInstanceTemplates()...)
+	for i := range b.config.runtime.WaitFor {
+		if err := b.config.runtime.WaitFor[i].validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if b.config.runtime.Tuning != nil {
+		errs = append(errs, b.config.runtime.Tuning.validate()...)
+	}
+	if (b.config.runtime.PauseSignal != nil || b.config.runtime.ResumeSignal != nil) && b.config.runtime.Pause == nil {
+		errs = append(errs, errors.New("pause signals require a service runner that implements Pausable"))
+	}
+	if b.config.runtime.CrashLoop != nil {
+		if err := b.config.runtime.CrashLoop.validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if b.config.service.Layout.StateDir.Path == "" {
+			errs = append(errs, errors.New("crash loop detection requires a state directory, see WithStateDir"))
+		}
+	}
+	b.warnings = b.warnings[:0]
+	for _, key := range unknownServiceOptionKeys(b.config.service.Options) {
+		b.warnings = append(b.warnings, fmt.Sprintf("service option %q is not recognized by daemon and will be ignored", key))
+	}
+
 	// 执行自定义验证器
 	for i, validator := range b.validators {
 		if err := validator(b.config); err != nil {
@@ -192,8 +192,8 @@ func (c *Cli) setupVersion() {
 
 // setupService 设置服务相关功能
 func (c *Cli) setupService() {
-	// 只有同时设置了 Name 和 Run 函数才初始化服务
-	if c.config.basic.Name != "" && c.config.runtime.Run != nil {
+	// 只有同时设置了 Name 和 Run 函数（或计划任务）才初始化服务
+	if c.config.basic.Name != "" && c.config.runtime.hasService() {
 		// 如果配置了服务名称和启动函数则初始化服务
 		c.initService()
 	}
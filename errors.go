@@ -34,6 +34,15 @@ const (
 	ErrServiceRunning   ErrorCode = "SERVICE_ALREADY_RUNNING"
 	ErrServiceStopped   ErrorCode = "SERVICE_ALREADY_STOPPED"
 	ErrServiceTimeout   ErrorCode = "SERVICE_TIMEOUT"
+	ErrServiceCrashLoop ErrorCode = "SERVICE_CRASH_LOOP"
+	ErrLifecycleTimeout ErrorCode = "LIFECYCLE_TIMEOUT"
+	ErrServicePause     ErrorCode = "SERVICE_PAUSE"
+
+	// 依赖相关错误
+	ErrDependencyUnavailable ErrorCode = "DEPENDENCY_UNAVAILABLE"
+
+	// 运行环境相关错误
+	ErrInitModeUnsupported ErrorCode = "INIT_MODE_UNSUPPORTED"
 
 	// 系统相关错误
 	ErrPermission        ErrorCode = "PERMISSION_DENIED"
@@ -45,6 +54,7 @@ const (
 	ErrRuntime          ErrorCode = "RUNTIME_ERROR"
 	ErrContextCancelled ErrorCode = "CONTEXT_CANCELLED"
 	ErrTimeout          ErrorCode = "TIMEOUT"
+	ErrTaskFailed       ErrorCode = "TASK_FAILED"
 
 	// 网络和通信错误
 	ErrNetwork    ErrorCode = "NETWORK_ERROR"
@@ -283,6 +293,80 @@ func ErrServiceStopTimeout(service string, timeout time.Duration) *ServiceError
 		Build()
 }
 
+// ErrServiceCrashLooping 崩溃循环错误
+func ErrServiceCrashLooping(service string, failures int, window time.Duration) *ServiceError {
+	return NewError(ErrServiceCrashLoop).
+		Service(service).
+		Operation("start").
+		Messagef("crash loop detected: %d failures within %v, not restarting", failures, window).
+		Context("failures", failures).
+		Context("window", window.String()).
+		Build()
+}
+
+// ErrServiceCrashLoopPending 崩溃循环状态尚未被 start 或 restart 命令清除时拒绝运行的错误
+func ErrServiceCrashLoopPending(service string) *ServiceError {
+	return NewError(ErrServiceCrashLoop).
+		Service(service).
+		Operation("run").
+		Message("crash loop detected earlier, not restarting until the start or restart command clears it").
+		Build()
+}
+
+// ErrLifecyclePhaseTimeout 生命周期阶段超时错误
+func ErrLifecyclePhaseTimeout(service string, phase LifecyclePhase, timeout time.Duration, cause error) *ServiceError {
+	return NewError(ErrLifecycleTimeout).
+		Service(service).
+		Operation(string(phase)).
+		Messagef("%s did not complete within %v", phase, timeout).
+		Cause(cause).
+		Context("phase", string(phase)).
+		Context("timeout", timeout.String()).
+		Build()
+}
+
+// ErrScheduledTaskFailed 计划任务执行失败错误
+func ErrScheduledTaskFailed(service, task string, cause error) *ServiceError {
+	return NewError(ErrTaskFailed).
+		Service(service).
+		Operation("task").
+		Messagef("scheduled task %s failed", task).
+		Cause(cause).
+		Context("task", task).
+		Build()
+}
+
+// ErrScheduledTaskTimeout 计划任务单次运行超时错误
+func ErrScheduledTaskTimeout(service, task string, timeout time.Duration) *ServiceError {
+	return NewError(ErrTimeout).
+		Service(service).
+		Operation("task").
+		Messagef("scheduled task %s did not complete within %v", task, timeout).
+		Context("task", task).
+		Context("timeout", timeout.String()).
+		Build()
+}
+
+// ErrUnsupportedInInitMode 容器 init 模式下不可用的命令
+func ErrUnsupportedInInitMode(service, command string) *ServiceError {
+	return NewError(ErrInitModeUnsupported).
+		Service(service).
+		Operation(command).
+		Messagef("%s is not available in container init mode, there is no service manager", command).
+		Build()
+}
+
+// ErrServiceDependencyUnavailable 必需依赖未就绪错误，operation 为被阻塞的操作（start 或 run）
+func ErrServiceDependencyUnavailable(service, operation, dependency, state string) *ServiceError {
+	return NewError(ErrDependencyUnavailable).
+		Service(service).
+		Operation(operation).
+		Messagef("required dependency %s is %s", dependency, state).
+		Context("dependency", dependency).
+		Context("state", state).
+		Build()
+}
+
 // ErrConfigValidationFailed configuration validation failed错误
 func ErrConfigValidationFailed(details []error) *ServiceError {
 	var messages []string
@@ -214,30 +214,9 @@ func (s *AppService) processBatch() {
 func (s *AppService) BeforeStart() error {
 	slog.Info("生命周期: BeforeStart — 初始化数据库和缓存连接")
 
+	// 连通性已由 WithWaitFor 在 Run 之前确认，这里只建立连接
 	s.db = newDatabase(s.cfg.DBHost, s.cfg.DBPort)
 	s.cache = newCache(s.cfg.RedisHost, s.cfg.RedisPort)
-
-	// 结构化错误构建器演示
-	if err := s.db.Ping(context.Background()); err != nil {
-		return zcli.NewError(zcli.ErrConnection).
-			Service(s.name).
-			Operation("BeforeStart").
-			Message("数据库连接测试失败").
-			Context("host", s.cfg.DBHost).
-			Context("port", s.cfg.DBPort).
-			Cause(err).
-			Build()
-	}
-	if err := s.cache.Ping(context.Background()); err != nil {
-		return zcli.NewError(zcli.ErrConnection).
-			Service(s.name).
-			Operation("BeforeStart").
-			Message("缓存连接测试失败").
-			Context("host", s.cfg.RedisHost).
-			Context("port", s.cfg.RedisPort).
-			Cause(err).
-			Build()
-	}
 	return nil
 }
 
@@ -420,10 +399,18 @@ func main() {
 		).
 
 		// 平台选项
-		WithServiceOption("Restart", "on-failure").
-		WithServiceOption("RestartSec", 10).
+		WithSystemdOptions(zcli.SystemdOptions{
+			Restart:    "on-failure",
+			RestartSec: 10 * time.Second,
+		}).
 		WithAllowSudoFallback(true).
 
+		// 前置条件：Run 之前等待数据库与缓存可用
+		WithWaitFor(
+			zcli.WaitForFunc("database", newDatabase(cfg.DBHost, cfg.DBPort).Ping, 30*time.Second),
+			zcli.WaitForFunc("cache", newCache(cfg.RedisHost, cfg.RedisPort).Ping, 30*time.Second),
+		).
+
 		// 超时控制
 		WithShutdownTimeouts(15*time.Second, 5*time.Second).
 		WithServiceTimeouts(30*time.Second, 20*time.Second).
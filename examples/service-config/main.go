@@ -53,15 +53,17 @@ func main() {
 			zcli.Dependency{Name: "postgresql.service", Type: zcli.DependencyRequire},
 			zcli.Dependency{Name: "redis.service", Type: zcli.DependencyWant},
 		).
-		WithServiceOption("Restart", "on-failure").
-		WithServiceOptionsMap(zcli.ServiceOptions{
-			"RestartSec": "5s",
-			"UMask":      "0027",
+		WithSystemdOptions(zcli.SystemdOptions{
+			Restart:         "on-failure",
+			RestartSec:      5 * time.Second,
+			NoNewPrivileges: true,
+			ProtectSystem:   "full",
+			MemoryMax:       "512M",
 		}).
 		WithAllowSudoFallback(true).
 		WithServiceTimeouts(20*time.Second, 30*time.Second).
 		WithServiceConfig(func(cfg *zcli.ServiceConfig) {
-			cfg.Options["LimitNOFILE"] = 65535
+			cfg.Systemd.LimitNOFILE = 65535
 		}).
 		BuildWithError()
 	if err != nil {
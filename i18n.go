@@ -19,6 +19,9 @@ type ServiceDomain struct {
 	Operations ServiceOperations // 服务操作
 	Status     ServiceStatus     // 服务状态
 	Messages   ServiceMessages   // 服务消息
+	Flags      ServiceFlags      // 服务命令参数说明
+	Labels     ServiceLabels     // 服务信息标签
+	Hints      ServiceHints      // doctor 修复建议
 }
 
 // ServiceOperations 服务操作相关文本
@@ -30,11 +33,18 @@ type ServiceOperations struct {
 	Restart   string // 重启
 	Run       string // 运行
 	Status    string // 查看状态
+	Export    string // 导出服务定义
+	List      string // 列出服务实例
+	Logs      string // 查看服务日志
+	Doctor    string // 检查服务运行环境
+	Pause     string // 暂停服务（维护模式）
+	Resume    string // 恢复服务
 }
 
 // ServiceStatus 服务状态相关文本
 type ServiceStatus struct {
 	Running        string // 正在运行
+	Paused         string // 已暂停（维护模式）
 	Stopped        string // 已停止
 	Unknown        string // 未知状态
 	NotInstalled   string // 未安装
@@ -46,14 +56,112 @@ type ServiceStatus struct {
 
 // ServiceMessages 服务操作过程中的提示消息
 type ServiceMessages struct {
-	Installing     string // 正在安装...
-	Uninstalling   string // 正在卸载...
-	Starting       string // 正在启动...
-	Stopping       string // 正在停止...
-	Restarting     string // 正在重启...
-	CheckingStatus string // 正在检查状态...
-	TimeoutWarning string // 超时警告
-	ForceTerminate string // 强制终止
+	Installing       string // 正在安装...
+	Uninstalling     string // 正在卸载...
+	Starting         string // 正在启动...
+	Stopping         string // 正在停止...
+	Restarting       string // 正在重启...
+	CheckingStatus   string // 正在检查状态...
+	TimeoutWarning   string // 超时警告
+	ForceTerminate   string // 强制终止
+	PurgeTargets     string // purge 删除列表标题
+	PurgeConfirm     string // purge 确认提示
+	PurgeAborted     string // purge 已取消
+	NoInstances      string // 未安装任何实例
+	InstanceRegistry string // 实例登记更新失败
+	WaitingDeps      string // 等待依赖就绪
+	WaitingFor       string // 等待前置条件
+	TuningFailed     string // 资源调优失败
+	NoLogSource      string // 未找到日志来源
+	NoHistory        string // 没有生命周期记录
+	MetricsFailed    string // 指标服务启动失败
+	TaskFailed       string // 计划任务执行失败
+	ControlFailed    string // 控制通道不可用
+	WatchRestart     string // watch 检测到变更
+	WatchWaiting     string // watch 等待变更
+	RebuildFailed    string // watch 重建命令失败
+	CrashLoopClear   string // start/restart 清除崩溃循环状态
+}
+
+// ServiceFlags 服务命令参数说明文本
+type ServiceFlags struct {
+	Env        string // --env
+	EnvFile    string // --env-file
+	WorkDir    string // --workdir
+	User       string // --user
+	Arg        string // --arg
+	Executable string // --executable
+	Dependency string // --dependency
+	Wait       string // --wait
+	Timeout    string // --timeout
+	Purge      string // --purge
+	Yes        string // --yes
+	CreateUser string // --create-user
+	Format     string // --format
+	Output     string // --output
+	Image      string // --image
+	Instance   string // --instance
+	WaitDeps   string // --wait-deps
+	Follow     string // --follow
+	Since      string // --since
+	Lines      string // --lines
+	Detach     string // --detach
+	Watch      string // --watch
+	Rebuild    string // --rebuild
+	Debounce   string // --debounce
+	History    string // --history
+	OutFormat  string // doctor --output
+}
+
+// ServiceLabels 服务信息展示标签
+type ServiceLabels struct {
+	Name         string // 服务名称
+	Executable   string // 可执行文件
+	WorkDir      string // 工作目录
+	User         string // 运行用户
+	Arguments    string // 运行参数
+	EnvVars      string // 环境变量
+	Dependencies string // 依赖
+	StateDir     string // 状态目录
+	LogDir       string // 日志目录
+	RuntimeDir   string // 运行时目录
+	ConfigDir    string // 配置目录
+	Account      string // zcli 创建的系统账号
+	Ready        string // 已满足的前置条件
+	NoFile       string // RLIMIT_NOFILE
+	CoreLimit    string // RLIMIT_CORE
+	MaxProcs     string // GOMAXPROCS
+	MemoryLimit  string // Go 运行时软内存上限
+	Umask        string // 文件创建掩码
+	OomScore     string // OOM 调整值
+	Pid          string // 进程号
+	PidFile      string // PID 文件
+	LogFile      string // 日志文件
+	Time         string // 时间
+	Event        string // 事件
+	Detail       string // 详情
+	Check        string // 检查项
+	Result       string // 检查结果
+	Backend      string // 服务管理器
+	Chroot       string // chroot 目录
+	EnvFile      string // 环境变量文件
+	WaitFor      string // 前置条件
+	Template     string // 实例模板
+}
+
+// ServiceHints doctor 检查项的修复建议
+type ServiceHints struct {
+	Backend           string // 没有可用的服务管理器
+	Executable        string // 可执行文件不可用
+	WorkDir           string // 工作目录不可用
+	WorkDirWritable   string // 工作目录不可写
+	Chroot            string // chroot 目录不可用
+	User              string // 运行用户不存在
+	EnvFile           string // 环境变量文件解析失败
+	Dependency        string // 依赖服务未安装
+	DependencyStopped string // 依赖服务未运行
+	WaitFor           string // 前置条件未满足
+	Template          string // 实例模板展开失败
 }
 
 // UIDomain 界面域 - 专注于用户界面相关文本
@@ -98,20 +206,33 @@ type ErrorDomain struct {
 
 // ServiceErrors 服务相关错误
 type ServiceErrors struct {
-	CreateConfig    string // 创建配置失败
-	CreateService   string // 创建服务失败
-	GetStatus       string // 获取状态失败
-	StartFailed     string // 启动失败
-	StopFailed      string // 停止失败
-	RestartFailed   string // 重启失败
-	InstallFailed   string // 安装失败
-	UninstallFailed string // 卸载失败
-	RunFailed       string // 运行失败
-	NotFound        string // 服务未找到
-	AlreadyRunning  string // 服务已在运行
-	Timeout         string // 操作超时
-	TimeoutWarning  string // 超时警告
-	ForceTerminate  string // 强制终止
+	CreateConfig      string // 创建配置失败
+	CreateService     string // 创建服务失败
+	GetStatus         string // 获取状态失败
+	StartFailed       string // 启动失败
+	StopFailed        string // 停止失败
+	RestartFailed     string // 重启失败
+	InstallFailed     string // 安装失败
+	UninstallFailed   string // 卸载失败
+	RunFailed         string // 运行失败
+	NotFound          string // 服务未找到
+	AlreadyRunning    string // 服务已在运行
+	Timeout           string // 操作超时
+	TimeoutWarning    string // 超时警告
+	ForceTerminate    string // 强制终止
+	InvalidEnv        string // 环境变量格式错误
+	InvalidEnvFile    string // 环境变量文件解析失败
+	InvalidDepend     string // 依赖格式错误
+	LayoutFailed      string // 目录布局准备失败
+	UnsafePurge       string // 拒绝清理不安全路径
+	AccountFailed     string // 系统账号创建失败
+	UnsupportedFormat string // 不支持的导出格式
+	InvalidInstance   string // 无效的实例名
+	DropPrivileges    string // 降权失败
+	InvalidSince      string // 无效的起始时间
+	DetachFailed      string // 后台进程启动失败
+	DoctorFailed      string // doctor 检查未通过
+	InvalidOutput     string // 不支持的输出格式
 }
 
 // SystemErrors 系统相关错误
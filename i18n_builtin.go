@@ -14,9 +14,16 @@ func newChineseLanguage() *Language {
 				Restart:   "重启服务",
 				Run:       "运行服务",
 				Status:    "查看状态",
+				Export:    "导出服务定义",
+				List:      "列出服务实例",
+				Logs:      "查看服务日志",
+				Doctor:    "检查服务运行环境",
+				Pause:     "暂停服务（维护模式）",
+				Resume:    "恢复已暂停的服务",
 			},
 			Status: ServiceStatus{
 				Running:        "正在运行",
+				Paused:         "已暂停（维护模式）",
 				Stopped:        "已停止",
 				Unknown:        "未知状态",
 				NotInstalled:   "服务未安装",
@@ -26,14 +33,106 @@ func newChineseLanguage() *Language {
 				Success:        "执行成功",
 			},
 			Messages: ServiceMessages{
-				Installing:     "正在安装服务...",
-				Uninstalling:   "正在卸载服务...",
-				Starting:       "正在启动服务...",
-				Stopping:       "正在停止服务...",
-				Restarting:     "正在重启服务...",
-				CheckingStatus: "正在检查服务状态...",
-				TimeoutWarning: "等待超时，再次调用停止函数",
-				ForceTerminate: "服务未能在规定时间内退出，标记为已停止",
+				Installing:       "正在安装服务...",
+				Uninstalling:     "正在卸载服务...",
+				Starting:         "正在启动服务...",
+				Stopping:         "正在停止服务...",
+				Restarting:       "正在重启服务...",
+				CheckingStatus:   "正在检查服务状态...",
+				TimeoutWarning:   "等待超时，再次调用停止函数",
+				ForceTerminate:   "服务未能在规定时间内退出，标记为已停止",
+				PurgeTargets:     "将删除以下路径:",
+				PurgeConfirm:     "确认继续? [y/N] ",
+				PurgeAborted:     "已取消清理",
+				NoInstances:      "未安装任何服务实例",
+				InstanceRegistry: "更新实例登记失败",
+				WaitingDeps:      "等待依赖服务 %s 就绪（当前: %s）",
+				WaitingFor:       "等待前置条件 %s: %v",
+				TuningFailed:     "资源调优 %s 未生效: %v",
+				NoLogSource:      "未找到服务日志：未配置日志文件，且当前系统没有 journalctl",
+				NoHistory:        "暂无生命周期记录",
+				MetricsFailed:    "指标服务 %s 启动失败: %v",
+				TaskFailed:       "计划任务 %s 执行失败: %v",
+				ControlFailed:    "控制通道 %s 不可用，pause/resume 只能通过信号: %v",
+				WatchRestart:     "检测到 %s 变更，正在重启",
+				WatchWaiting:     "服务已退出，文件变更后重新启动",
+				RebuildFailed:    "重建命令执行失败，文件变更后重试: %v",
+				CrashLoopClear:   "已清除崩溃循环状态，失败次数重新计算",
+			},
+			Flags: ServiceFlags{
+				Env:        "设置环境变量 KEY=VAL（可重复）",
+				EnvFile:    "从文件加载环境变量（可重复）",
+				WorkDir:    "覆盖服务工作目录",
+				User:       "覆盖服务运行用户",
+				Arg:        "覆盖服务运行参数（可重复）",
+				Executable: "覆盖服务可执行文件路径",
+				Dependency: "追加服务依赖 name[:after|before|require|want]（可重复）",
+				Wait:       "等待服务到达目标状态后再返回",
+				Timeout:    "等待目标状态的超时时间（默认取服务启动/停止超时）",
+				Purge:      "同时删除受管目录与复制的可执行文件",
+				Yes:        "跳过确认提示",
+				CreateUser: "创建锁定的系统用户与同名用户组（如不存在）",
+				Format:     "导出格式",
+				Output:     "写入文件而非标准输出",
+				Image:      "容器镜像（默认 <name>:<version>）",
+				Instance:   "服务实例名称，对应服务名 <name>@<instance>",
+				WaitDeps:   "等待必需依赖运行，可指定超时（默认 30s）",
+				Follow:     "持续输出新写入的日志",
+				Since:      "只显示该时间之后的日志，如 1h 或 \"2006-01-02 15:04:05\"",
+				Lines:      "显示最后 N 行，0 表示全部",
+				Detach:     "脱离终端在后台运行，无需服务管理器",
+				Watch:      "开发模式：可执行文件或指定路径变更后重启，-- 之前的参数为监视路径",
+				Rebuild:    "watch 模式下重启前执行的构建命令",
+				Debounce:   "watch 模式下变更静默多久后重启",
+				History:    "显示启动、停止、崩溃等生命周期历史",
+				OutFormat:  "输出格式：table 或 json",
+			},
+			Labels: ServiceLabels{
+				Name:         "名称",
+				Executable:   "可执行文件",
+				WorkDir:      "工作目录",
+				User:         "运行用户",
+				Arguments:    "运行参数",
+				EnvVars:      "环境变量",
+				Dependencies: "依赖",
+				StateDir:     "状态目录",
+				LogDir:       "日志目录",
+				RuntimeDir:   "运行时目录",
+				ConfigDir:    "配置目录",
+				Account:      "系统账号",
+				Ready:        "前置条件就绪",
+				NoFile:       "文件描述符上限",
+				CoreLimit:    "core 文件上限",
+				MaxProcs:     "GOMAXPROCS",
+				MemoryLimit:  "内存软上限",
+				Umask:        "umask",
+				OomScore:     "OOM 调整值",
+				Pid:          "进程号",
+				PidFile:      "PID 文件",
+				LogFile:      "日志文件",
+				Time:         "时间",
+				Event:        "事件",
+				Detail:       "详情",
+				Check:        "检查项",
+				Result:       "结果",
+				Backend:      "服务管理器",
+				Chroot:       "chroot 目录",
+				EnvFile:      "环境变量文件",
+				WaitFor:      "前置条件",
+				Template:     "实例模板",
+			},
+			Hints: ServiceHints{
+				Backend:           "当前系统没有可用的服务管理器，可改用 run --detach 在后台运行",
+				Executable:        "确认可执行文件存在且有执行权限，如 chmod 755 <path>",
+				WorkDir:           "创建工作目录，或通过 --workdir / WithWorkDir 指定已存在的目录",
+				WorkDirWritable:   "工作目录不可写；服务需要写入数据时请调整属主，或改用 WithStateDir",
+				Chroot:            "确认 chroot 目录存在且权限为 755",
+				User:              "使用 install --create-user 创建运行用户，或先手动创建该用户",
+				EnvFile:           "修正环境变量文件中的语法错误；可选文件请以 - 开头",
+				Dependency:        "安装该依赖服务，或从依赖声明中移除",
+				DependencyStopped: "依赖服务未运行，请先启动它，或使用 run --wait-deps 等待",
+				WaitFor:           "前置条件当前未满足，服务启动时会等待直至超时",
+				Template:          "检查运行参数、环境变量与目录中的 {{...}} 模板，可引用 .Name 与 .Instance",
 			},
 		},
 		UI: UIDomain{
@@ -60,20 +159,33 @@ func newChineseLanguage() *Language {
 		Error: ErrorDomain{
 			Prefix: "错误: ",
 			Service: ServiceErrors{
-				CreateConfig:    "创建服务配置失败",
-				CreateService:   "创建服务实例失败",
-				GetStatus:       "获取服务状态失败",
-				StartFailed:     "启动服务失败",
-				StopFailed:      "停止服务失败",
-				RestartFailed:   "重启服务失败",
-				InstallFailed:   "安装服务失败",
-				UninstallFailed: "卸载服务失败",
-				RunFailed:       "运行服务失败",
-				NotFound:        "服务 %s 未安装",
-				AlreadyRunning:  "服务已在运行中",
-				Timeout:         "服务未能在%d秒内正常退出，强制结束进程",
-				TimeoutWarning:  "等待超时，再次调用停止函数",
-				ForceTerminate:  "服务未能在规定时间内退出，标记为已停止",
+				CreateConfig:      "创建服务配置失败",
+				CreateService:     "创建服务实例失败",
+				GetStatus:         "获取服务状态失败",
+				StartFailed:       "启动服务失败",
+				StopFailed:        "停止服务失败",
+				RestartFailed:     "重启服务失败",
+				InstallFailed:     "安装服务失败",
+				UninstallFailed:   "卸载服务失败",
+				RunFailed:         "运行服务失败",
+				NotFound:          "服务 %s 未安装",
+				AlreadyRunning:    "服务已在运行中",
+				Timeout:           "服务未能在%d秒内正常退出，强制结束进程",
+				TimeoutWarning:    "等待超时，再次调用停止函数",
+				ForceTerminate:    "服务未能在规定时间内退出，标记为已停止",
+				InvalidEnv:        "环境变量格式错误 %q，应为 KEY=VAL",
+				InvalidEnvFile:    "环境变量文件解析失败 %s:%d: %s",
+				InvalidDepend:     "依赖格式错误 %q，应为 name[:type]",
+				LayoutFailed:      "准备服务目录失败 %s: %v",
+				UnsafePurge:       "拒绝删除不安全的路径: %s",
+				AccountFailed:     "创建系统用户 %s 失败: %v",
+				UnsupportedFormat: "不支持的导出格式: %s（可选: %s）",
+				InvalidInstance:   "无效的实例名称: %s（仅允许字母、数字、_ . -）",
+				DropPrivileges:    "切换到运行用户 %s 失败，拒绝以 root 继续运行: %v",
+				InvalidSince:      "无效的时间 %q，应为时长（如 1h）或时间（如 2006-01-02 15:04:05）",
+				DetachFailed:      "后台进程启动失败: %s（日志: %s）",
+				DoctorFailed:      "%d 项检查未通过",
+				InvalidOutput:     "不支持的输出格式: %s（可选: %s）",
 			},
 			System: SystemErrors{
 				PathNotExist:      "路径不存在: %s",
@@ -114,9 +226,16 @@ func newEnglishLanguage() *Language {
 				Restart:   "Restart Service",
 				Run:       "Run Service",
 				Status:    "Service Status",
+				Export:    "Export Service Definition",
+				List:      "List Service Instances",
+				Logs:      "View Service Logs",
+				Doctor:    "Check Service Environment",
+				Pause:     "Pause Service (Maintenance Mode)",
+				Resume:    "Resume Paused Service",
 			},
 			Status: ServiceStatus{
 				Running:        "Running",
+				Paused:         "Paused (maintenance mode)",
 				Stopped:        "Stopped",
 				Unknown:        "Unknown",
 				NotInstalled:   "Service not installed",
@@ -126,14 +245,106 @@ func newEnglishLanguage() *Language {
 				Success:        "Success",
 			},
 			Messages: ServiceMessages{
-				Installing:     "Installing service...",
-				Uninstalling:   "Uninstalling service...",
-				Starting:       "Starting service...",
-				Stopping:       "Stopping service...",
-				Restarting:     "Restarting service...",
-				CheckingStatus: "Checking service status...",
-				TimeoutWarning: "Timeout waiting, calling stop functions again",
-				ForceTerminate: "Service failed to exit within timeout period, marked as stopped",
+				Installing:       "Installing service...",
+				Uninstalling:     "Uninstalling service...",
+				Starting:         "Starting service...",
+				Stopping:         "Stopping service...",
+				Restarting:       "Restarting service...",
+				CheckingStatus:   "Checking service status...",
+				TimeoutWarning:   "Timeout waiting, calling stop functions again",
+				ForceTerminate:   "Service failed to exit within timeout period, marked as stopped",
+				PurgeTargets:     "The following paths will be removed:",
+				PurgeConfirm:     "Continue? [y/N] ",
+				PurgeAborted:     "Purge aborted",
+				NoInstances:      "No service instances installed",
+				InstanceRegistry: "Failed to update the instance registry",
+				WaitingDeps:      "Waiting for dependency %s (currently %s)",
+				WaitingFor:       "Waiting for %s: %v",
+				TuningFailed:     "Resource tuning %s not applied: %v",
+				NoLogSource:      "No service logs found: no log file is configured and journalctl is not available",
+				NoHistory:        "No lifecycle history recorded yet",
+				MetricsFailed:    "Metrics endpoint %s not started: %v",
+				TaskFailed:       "Scheduled task %s failed: %v",
+				ControlFailed:    "Control channel %s unavailable, pause/resume only via signals: %v",
+				WatchRestart:     "%s changed, restarting",
+				WatchWaiting:     "Service exited, restarting on the next change",
+				RebuildFailed:    "Rebuild command failed, retrying on the next change: %v",
+				CrashLoopClear:   "Crash loop state cleared, failures are counted afresh",
+			},
+			Flags: ServiceFlags{
+				Env:        "Set an environment variable KEY=VAL (repeatable)",
+				EnvFile:    "Load environment variables from a file (repeatable)",
+				WorkDir:    "Override the service working directory",
+				User:       "Override the service user",
+				Arg:        "Override the service arguments (repeatable)",
+				Executable: "Override the service executable path",
+				Dependency: "Add a service dependency name[:after|before|require|want] (repeatable)",
+				Wait:       "Wait until the service reaches the target state",
+				Timeout:    "Timeout for reaching the target state (defaults to the service start/stop timeout)",
+				Purge:      "Also remove managed directories and the installed binary",
+				Yes:        "Skip the confirmation prompt",
+				CreateUser: "Create a locked system user and group if missing",
+				Format:     "Export format",
+				Output:     "Write to a file instead of stdout",
+				Image:      "Container image (defaults to <name>:<version>)",
+				Instance:   "Service instance, maps to the service name <name>@<instance>",
+				WaitDeps:   "Wait for required dependencies to run, optionally with a timeout (default 30s)",
+				Follow:     "Keep printing new log entries",
+				Since:      "Only show entries after this time, e.g. 1h or \"2006-01-02 15:04:05\"",
+				Lines:      "Show the last N lines, 0 for all",
+				Detach:     "Run in the background detached from the terminal, without a service manager",
+				Watch:      "Development mode: restart when the executable or given paths change; arguments before -- are watched paths",
+				Rebuild:    "Build command to run before restarting in watch mode",
+				Debounce:   "Quiet period after the last change before restarting in watch mode",
+				History:    "Show the start, stop and crash history",
+				OutFormat:  "Output format: table or json",
+			},
+			Labels: ServiceLabels{
+				Name:         "Name",
+				Executable:   "Executable",
+				WorkDir:      "Work dir",
+				User:         "User",
+				Arguments:    "Arguments",
+				EnvVars:      "Env vars",
+				Dependencies: "Dependencies",
+				StateDir:     "State dir",
+				LogDir:       "Log dir",
+				RuntimeDir:   "Runtime dir",
+				ConfigDir:    "Config dir",
+				Account:      "System account",
+				Ready:        "Condition ready",
+				NoFile:       "Open files limit",
+				CoreLimit:    "Core file limit",
+				MaxProcs:     "GOMAXPROCS",
+				MemoryLimit:  "Memory limit",
+				Umask:        "umask",
+				OomScore:     "OOM score adj",
+				Pid:          "PID",
+				PidFile:      "PID file",
+				LogFile:      "Log file",
+				Time:         "Time",
+				Event:        "Event",
+				Detail:       "Detail",
+				Check:        "Check",
+				Result:       "Result",
+				Backend:      "Service manager",
+				Chroot:       "Chroot dir",
+				EnvFile:      "Env file",
+				WaitFor:      "Wait-for",
+				Template:     "Instance template",
+			},
+			Hints: ServiceHints{
+				Backend:           "No service manager is available on this system; use run --detach to run in the background",
+				Executable:        "Make sure the executable exists and is executable, e.g. chmod 755 <path>",
+				WorkDir:           "Create the working directory, or point --workdir / WithWorkDir at an existing one",
+				WorkDirWritable:   "The working directory is not writable; fix its owner if the service writes there, or use WithStateDir",
+				Chroot:            "Make sure the chroot directory exists with mode 755",
+				User:              "Create the user with install --create-user, or create it manually first",
+				EnvFile:           "Fix the syntax error in the environment file; prefix optional files with -",
+				Dependency:        "Install the dependency, or remove it from the declared dependencies",
+				DependencyStopped: "The dependency is not running; start it first or use run --wait-deps",
+				WaitFor:           "The condition is not met yet; the service waits for it at startup until the timeout",
+				Template:          "Check the {{...}} templates in arguments, env vars and directories; they may reference .Name and .Instance",
 			},
 		},
 		UI: UIDomain{
@@ -160,20 +371,33 @@ func newEnglishLanguage() *Language {
 		Error: ErrorDomain{
 			Prefix: "Error: ",
 			Service: ServiceErrors{
-				CreateConfig:    "Failed to create service configuration",
-				CreateService:   "Failed to create service instance",
-				GetStatus:       "Failed to get service status",
-				StartFailed:     "Failed to start service",
-				StopFailed:      "Failed to stop service",
-				RestartFailed:   "Failed to restart service",
-				InstallFailed:   "Failed to install service",
-				UninstallFailed: "Failed to uninstall service",
-				RunFailed:       "Failed to run service",
-				NotFound:        "Service %s is not installed",
-				AlreadyRunning:  "Service is already running",
-				Timeout:         "Service failed to exit within %d seconds, force terminating process",
-				TimeoutWarning:  "Timeout waiting, calling stop functions again",
-				ForceTerminate:  "Service failed to exit within timeout period, marked as stopped",
+				CreateConfig:      "Failed to create service configuration",
+				CreateService:     "Failed to create service instance",
+				GetStatus:         "Failed to get service status",
+				StartFailed:       "Failed to start service",
+				StopFailed:        "Failed to stop service",
+				RestartFailed:     "Failed to restart service",
+				InstallFailed:     "Failed to install service",
+				UninstallFailed:   "Failed to uninstall service",
+				RunFailed:         "Failed to run service",
+				NotFound:          "Service %s is not installed",
+				AlreadyRunning:    "Service is already running",
+				Timeout:           "Service failed to exit within %d seconds, force terminating process",
+				TimeoutWarning:    "Timeout waiting, calling stop functions again",
+				ForceTerminate:    "Service failed to exit within timeout period, marked as stopped",
+				InvalidEnv:        "Invalid environment variable %q, expected KEY=VAL",
+				InvalidEnvFile:    "Failed to parse environment file %s:%d: %s",
+				InvalidDepend:     "Invalid dependency %q, expected name[:type]",
+				LayoutFailed:      "Failed to prepare service directory %s: %v",
+				UnsafePurge:       "Refusing to remove unsafe path: %s",
+				AccountFailed:     "Failed to create system user %s: %v",
+				UnsupportedFormat: "Unsupported export format: %s (available: %s)",
+				InvalidInstance:   "Invalid instance name: %s (letters, digits, _ . - only)",
+				DropPrivileges:    "Failed to switch to user %s, refusing to keep running as root: %v",
+				InvalidSince:      "Invalid time %q, expected a duration (e.g. 1h) or a time (e.g. 2006-01-02 15:04:05)",
+				DetachFailed:      "Background process failed to start: %s (log: %s)",
+				DoctorFailed:      "%d checks failed",
+				InvalidOutput:     "Unsupported output format: %s (available: %s)",
 			},
 			System: SystemErrors{
 				PathNotExist:      "Path does not exist: %s",
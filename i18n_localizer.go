@@ -61,10 +61,34 @@ func (sl *ServiceLocalizer) GetStatus(status string) string {
 	return sl.manager.GetText(path)
 }
 
+// GetMessage 获取服务提示消息文本
+func (sl *ServiceLocalizer) GetMessage(message string) string {
+	path := fmt.Sprintf("service.messages.%s", message)
+	return sl.manager.GetText(path)
+}
+
+// GetFlag 获取命令参数说明文本
+func (sl *ServiceLocalizer) GetFlag(flag string) string {
+	path := fmt.Sprintf("service.flags.%s", flag)
+	return sl.manager.GetText(path)
+}
+
+// GetLabel 获取信息标签文本
+func (sl *ServiceLocalizer) GetLabel(label string) string {
+	path := fmt.Sprintf("service.labels.%s", label)
+	return sl.manager.GetText(path)
+}
+
+// GetHint 获取 doctor 检查项的修复建议
+func (sl *ServiceLocalizer) GetHint(hint string) string {
+	path := fmt.Sprintf("service.hints.%s", hint)
+	return sl.manager.GetText(path)
+}
+
 // GetError 获取错误文本
 func (sl *ServiceLocalizer) GetError(errorType string) string {
 	path := fmt.Sprintf("error.service.%s", errorType)
-	if text := sl.manager.GetText(path); text != "" {
+	if text := sl.manager.lookupText(path); text != "" {
 		return text
 	}
 	path = fmt.Sprintf("error.system.%s", errorType)
@@ -134,6 +158,19 @@ func (sl *ServiceLocalizer) LogInfo(serviceName, status string) {
 	}
 }
 
+// LogDetail 记录带标签的明细行，用于补充展示操作结果
+func (sl *ServiceLocalizer) LogDetail(label string, value any) {
+	text := fmt.Sprintf("%s%-16s %v", indent, sl.GetLabel(label)+":", value)
+	if sl.colors != nil {
+		out := color.Output
+		color.Output = sl.colorOut
+		_, _ = sl.colors.Info.Println(text)
+		color.Output = out
+	} else {
+		_, _ = fmt.Fprintln(sl.out, text)
+	}
+}
+
 // FormatError 格式化错误消息
 func (sl *ServiceLocalizer) FormatError(errorType string, args ...any) string {
 	template := sl.GetError(errorType)
@@ -77,15 +77,18 @@ func (lm *LanguageManager) GetPrimary() *Language {
 
 // GetText 智能获取文本，支持回退机制
 func (lm *LanguageManager) GetText(path string) string {
-	if text := lm.getTextFromLanguage(lm.primary, path); text != "" {
+	if text := lm.lookupText(path); text != "" {
 		return text
 	}
+	return fmt.Sprintf("[Missing: %s]", path)
+}
 
-	if text := lm.getTextFromLanguage(lm.fallback, path); text != "" {
+// lookupText 依次从主语言与回退语言查找文本，均未找到时返回空字符串
+func (lm *LanguageManager) lookupText(path string) string {
+	if text := lm.getTextFromLanguage(lm.primary, path); text != "" {
 		return text
 	}
-
-	return fmt.Sprintf("[Missing: %s]", path)
+	return lm.getTextFromLanguage(lm.fallback, path)
 }
 
 // getTextFromLanguage 从指定语言包获取文本
@@ -0,0 +1,335 @@
+package zcli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// metricsContentType Prometheus 文本格式 0.0.4
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// metricKind 指标类型，取值即文本格式中的 TYPE
+type metricKind string
+
+const (
+	metricCounter metricKind = "counter"
+	metricGauge   metricKind = "gauge"
+)
+
+// metricValue 以原子方式保存的 float64
+type metricValue struct {
+	bits atomic.Uint64
+}
+
+func (v *metricValue) load() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+func (v *metricValue) store(f float64) {
+	v.bits.Store(math.Float64bits(f))
+}
+
+func (v *metricValue) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if v.bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// Counter 单调递增的计数器
+type Counter struct {
+	v metricValue
+}
+
+// Inc 计数加一
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add 增加计数，负数被忽略
+func (c *Counter) Add(delta float64) {
+	if delta > 0 {
+		c.v.add(delta)
+	}
+}
+
+// Value 返回当前计数
+func (c *Counter) Value() float64 { return c.v.load() }
+
+// mirror 同步外部维护的累计值，如运行时统计
+func (c *Counter) mirror(total float64) { c.v.store(total) }
+
+// Gauge 可任意增减的瞬时值
+type Gauge struct {
+	v metricValue
+}
+
+// Set 设置当前值
+func (g *Gauge) Set(value float64) { g.v.store(value) }
+
+// Add 增加当前值，可为负数
+func (g *Gauge) Add(delta float64) { g.v.add(delta) }
+
+// Inc 当前值加一
+func (g *Gauge) Inc() { g.v.add(1) }
+
+// Dec 当前值减一
+func (g *Gauge) Dec() { g.v.add(-1) }
+
+// Value 返回当前值
+func (g *Gauge) Value() float64 { return g.v.load() }
+
+// metricSeries 指标族中的一条时间序列
+type metricSeries struct {
+	values  []string
+	counter *Counter
+	gauge   *Gauge
+}
+
+func (s *metricSeries) value() float64 {
+	if s.counter != nil {
+		return s.counter.Value()
+	}
+	return s.gauge.Value()
+}
+
+// metricFamily 同名指标：类型、标签名与全部时间序列
+type metricFamily struct {
+	name   string
+	help   string
+	kind   metricKind
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*metricSeries
+	fn     func() float64 // GaugeFunc 在采集时取值
+}
+
+// with 返回给定标签值对应的时间序列，不存在时创建
+func (f *metricFamily) with(values []string) *metricSeries {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("zcli: metric %q expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s
+	}
+	s := &metricSeries{values: append([]string(nil), values...)}
+	if f.kind == metricCounter {
+		s.counter = &Counter{}
+	} else {
+		s.gauge = &Gauge{}
+	}
+	f.series[key] = s
+	return s
+}
+
+// CounterVec 按标签区分的一组计数器
+type CounterVec struct {
+	family *metricFamily
+}
+
+// With 返回标签值对应的计数器，值的顺序与注册时的标签名一致
+func (v *CounterVec) With(values ...string) *Counter {
+	return v.family.with(values).counter
+}
+
+// GaugeVec 按标签区分的一组瞬时值
+type GaugeVec struct {
+	family *metricFamily
+}
+
+// With 返回标签值对应的瞬时值，值的顺序与注册时的标签名一致
+func (v *GaugeVec) With(values ...string) *Gauge {
+	return v.family.with(values).gauge
+}
+
+// MetricsRegistry 指标注册表，以 Prometheus 文本格式输出。
+// 同名同类型的重复注册返回已有指标；名称非法或与已有指标冲突时 panic，属于编程错误。
+type MetricsRegistry struct {
+	mu         sync.RWMutex
+	families   map[string]*metricFamily
+	collectors []func() // 输出前调用，用于批量刷新需要采集的值
+}
+
+// NewMetricsRegistry 创建空的指标注册表
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{families: make(map[string]*metricFamily)}
+}
+
+// NewCounter 注册计数器
+func (r *MetricsRegistry) NewCounter(name, help string) *Counter {
+	return r.register(name, help, metricCounter, nil).with(nil).counter
+}
+
+// NewGauge 注册瞬时值
+func (r *MetricsRegistry) NewGauge(name, help string) *Gauge {
+	return r.register(name, help, metricGauge, nil).with(nil).gauge
+}
+
+// NewCounterVec 注册带标签的计数器
+func (r *MetricsRegistry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{family: r.register(name, help, metricCounter, labels)}
+}
+
+// NewGaugeVec 注册带标签的瞬时值
+func (r *MetricsRegistry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{family: r.register(name, help, metricGauge, labels)}
+}
+
+// NewGaugeFunc 注册在每次输出时调用 fn 取值的瞬时值，重复注册时替换 fn
+func (r *MetricsRegistry) NewGaugeFunc(name, help string, fn func() float64) {
+	family := r.register(name, help, metricGauge, nil)
+	family.mu.Lock()
+	family.fn = fn
+	family.mu.Unlock()
+}
+
+// onCollect 追加输出前的采集回调
+func (r *MetricsRegistry) onCollect(fn func()) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, fn)
+	r.mu.Unlock()
+}
+
+func (r *MetricsRegistry) register(name, help string, kind metricKind, labels []string) *metricFamily {
+	if !metricNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("zcli: invalid metric name %q", name))
+	}
+	for _, label := range labels {
+		if !labelNamePattern.MatchString(label) || strings.HasPrefix(label, "__") {
+			panic(fmt.Sprintf("zcli: invalid label name %q for metric %q", label, name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if family, ok := r.families[name]; ok {
+		if family.kind != kind || strings.Join(family.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Sprintf("zcli: metric %q already registered as %s%v", name, family.kind, family.labels))
+		}
+		return family
+	}
+	family := &metricFamily{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: append([]string(nil), labels...),
+		series: make(map[string]*metricSeries),
+	}
+	r.families[name] = family
+	return family
+}
+
+// WriteText 按名称排序输出全部指标
+func (r *MetricsRegistry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	collectors := append([]func(){}, r.collectors...)
+	families := make([]*metricFamily, 0, len(r.families))
+	for _, family := range r.families {
+		families = append(families, family)
+	}
+	r.mu.RUnlock()
+
+	for _, collect := range collectors {
+		collect()
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, family := range families {
+		writeFamily(bw, family)
+	}
+	return bw.Flush()
+}
+
+func writeFamily(w *bufio.Writer, family *metricFamily) {
+	family.mu.Lock()
+	fn := family.fn
+	series := make([]*metricSeries, 0, len(family.series))
+	for _, s := range family.series {
+		series = append(series, s)
+	}
+	family.mu.Unlock()
+
+	// 带标签的指标尚无时间序列时不输出
+	if fn == nil && len(series) == 0 {
+		return
+	}
+	if family.help != "" {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n", family.name, escapeHelp(family.help))
+	}
+	_, _ = fmt.Fprintf(w, "# TYPE %s %s\n", family.name, family.kind)
+	if fn != nil {
+		_, _ = fmt.Fprintf(w, "%s %s\n", family.name, formatMetricValue(fn()))
+		return
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return strings.Join(series[i].values, "\xff") < strings.Join(series[j].values, "\xff")
+	})
+	for _, s := range series {
+		_, _ = w.WriteString(family.name)
+		if len(family.labels) > 0 {
+			_ = w.WriteByte('{')
+			for i, label := range family.labels {
+				if i > 0 {
+					_ = w.WriteByte(',')
+				}
+				_, _ = fmt.Fprintf(w, "%s=\"%s\"", label, escapeLabelValue(s.values[i]))
+			}
+			_ = w.WriteByte('}')
+		}
+		_, _ = fmt.Fprintf(w, " %s\n", formatMetricValue(s.value()))
+	}
+}
+
+// Handler 返回以文本格式输出指标的 HTTP 处理器
+func (r *MetricsRegistry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", metricsContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,86 @@
+package zcli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsRegistry_TextFormat(t *testing.T) {
+	r := NewMetricsRegistry()
+	requests := r.NewCounter("app_requests_total", "Handled requests.")
+	requests.Add(3)
+	requests.Add(-1)
+	r.NewGauge("app_queue_depth", "Queued jobs.\nPer worker.").Set(2.5)
+	jobs := r.NewCounterVec("app_jobs_total", "Jobs by result.", "result")
+	jobs.With("ok").Inc()
+	jobs.With(`bad "input"`).Inc()
+	r.NewCounterVec("app_unused_total", "Never observed.", "kind")
+	r.NewGaugeFunc("app_ratio", "", func() float64 { return 0.25 })
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := `# HELP app_jobs_total Jobs by result.
+# TYPE app_jobs_total counter
+app_jobs_total{result="bad \"input\""} 1
+app_jobs_total{result="ok"} 1
+# HELP app_queue_depth Queued jobs.\nPer worker.
+# TYPE app_queue_depth gauge
+app_queue_depth 2.5
+# TYPE app_ratio gauge
+app_ratio 0.25
+# HELP app_requests_total Handled requests.
+# TYPE app_requests_total counter
+app_requests_total 3
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestMetricsRegistry_Registration(t *testing.T) {
+	r := NewMetricsRegistry()
+	if r.NewCounter("app_total", "") != r.NewCounter("app_total", "") {
+		t.Fatal("registering the same counter twice should return the existing one")
+	}
+
+	for name, register := range map[string]func(){
+		"kind mismatch":  func() { r.NewGauge("app_total", "") },
+		"invalid name":   func() { r.NewGauge("app-total", "") },
+		"invalid label":  func() { r.NewCounterVec("app_labeled_total", "", "bad-label") },
+		"label mismatch": func() { r.NewCounterVec("app_vec_total", "", "a").With("x", "y") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s should panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
+func TestMetricsRegistry_Handler(t *testing.T) {
+	r := NewMetricsRegistry()
+	r.NewCounter("app_total", "").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("unexpected response: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "app_total 1\n") {
+		t.Fatalf("body missing metric:\n%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST should be rejected, got %d", rec.Code)
+	}
+}
@@ -3,6 +3,7 @@ package zcli
 import (
 	"context"
 	"maps"
+	"os"
 	"time"
 )
 
@@ -14,6 +15,13 @@ type RunFunc func(ctx context.Context) error
 // 返回 error 用于报告停止过程中的错误
 type StopFunc func() error
 
+// PauseFunc 服务暂停或恢复函数签名
+type PauseFunc func(ctx context.Context) error
+
+// SetupFunc 特权准备函数签名
+// 以 root 启动 run 时，在切换到服务运行用户之前执行，如绑定特权端口、读取仅 root 可读的证书
+type SetupFunc func(ctx context.Context) error
+
 // Basic 基础配置
 type Basic struct {
 	Name              string // 服务名称
@@ -34,6 +42,13 @@ type Runtime struct {
 	Stop      StopFunc     // 停止函数，标准签名：func() error
 	BuildInfo *VersionInfo // 构建信息
 
+	// Pause、Resume 维护模式的暂停与恢复函数，由实现 Pausable 的 ServiceRunner 提供
+	Pause  PauseFunc
+	Resume PauseFunc
+	// PauseSignal、ResumeSignal 运行期间触发暂停与恢复的信号，nil 表示不监听
+	PauseSignal  os.Signal
+	ResumeSignal os.Signal
+
 	// ShutdownInitial 在取消 Run(ctx) 后，等待主服务优雅退出的时长，默认 15s
 	ShutdownInitial time.Duration
 	// ShutdownGrace 在主服务收到停止信号后，保留给 stop hook / 最终清理的额外时长，默认 5s
@@ -44,6 +59,33 @@ type Runtime struct {
 	StopTimeout time.Duration
 	// ErrorHandlers 错误处理器链
 	ErrorHandlers []ErrorHandler
+	// WaitFor 调用 Run 前依次等待的前置条件
+	WaitFor []WaitCondition
+	// Tuning 调用 Run 前的进程级资源调优
+	Tuning *ResourceTuning
+	// PrivilegedSetup 降权前依次执行的特权准备函数
+	PrivilegedSetup []SetupFunc
+	// LogSinks 以服务方式运行时的日志输出
+	LogSinks []LogSink
+	// Detach run 默认以后台方式启动，可被 --detach=false 覆盖
+	Detach bool
+	// CrashLoop 崩溃循环判定，nil 表示不检测
+	CrashLoop *CrashLoopPolicy
+	// LifecycleHooks 生命周期事件回调
+	LifecycleHooks []LifecycleHook
+	// Metrics 指标注册表，nil 表示不启用；克隆时共享同一注册表
+	Metrics *MetricsRegistry
+	// MetricsAddr 运行期间输出 /metrics 的监听地址，为空时由调用方自行挂载 Handler
+	MetricsAddr string
+	// InitMode 容器 init（PID 1）模式，默认进程号为 1 时启用
+	InitMode InitMode
+	// Tasks 运行期间按计划执行的任务
+	Tasks []ScheduledTask
+}
+
+// hasService 报告是否配置了服务：Run 或计划任务任一存在即可作为服务运行
+func (r *Runtime) hasService() bool {
+	return r.Run != nil || len(r.Tasks) > 0
 }
 
 // Config 统一配置结构
@@ -204,6 +246,9 @@ func cloneService(src *ServiceConfig) ServiceConfig {
 		Executable:        src.Executable,
 		ChRoot:            src.ChRoot,
 		AllowSudoFallback: src.AllowSudoFallback,
+		CreateUser:        src.CreateUser,
+		CheckDependencies: src.CheckDependencies,
+		Layout:            src.Layout,
 	}
 
 	if len(src.Arguments) > 0 {
@@ -215,6 +260,9 @@ func cloneService(src *ServiceConfig) ServiceConfig {
 	if len(src.StructuredDeps) > 0 {
 		dst.StructuredDeps = append([]Dependency(nil), src.StructuredDeps...)
 	}
+	if len(src.EnvFiles) > 0 {
+		dst.EnvFiles = append([]string(nil), src.EnvFiles...)
+	}
 	if src.EnvVars != nil {
 		dst.EnvVars = make(map[string]string, len(src.EnvVars))
 		maps.Copy(dst.EnvVars, src.EnvVars)
@@ -223,6 +271,19 @@ func cloneService(src *ServiceConfig) ServiceConfig {
 		dst.Options = make(ServiceOptions, len(src.Options))
 		maps.Copy(dst.Options, src.Options)
 	}
+	if src.Systemd != nil {
+		systemd := *src.Systemd
+		systemd.ReadWritePaths = append([]string(nil), src.Systemd.ReadWritePaths...)
+		dst.Systemd = &systemd
+	}
+	if src.Launchd != nil {
+		launchd := *src.Launchd
+		dst.Launchd = &launchd
+	}
+	if src.Windows != nil {
+		windows := *src.Windows
+		dst.Windows = &windows
+	}
 	return dst
 }
 
@@ -244,15 +305,46 @@ func cloneRuntime(src *Runtime) Runtime {
 	dst := Runtime{
 		Run:             src.Run,
 		Stop:            src.Stop,
+		Pause:           src.Pause,
+		Resume:          src.Resume,
 		ShutdownInitial: src.ShutdownInitial,
 		ShutdownGrace:   src.ShutdownGrace,
 		StartTimeout:    src.StartTimeout,
 		StopTimeout:     src.StopTimeout,
+		Detach:          src.Detach,
+		Metrics:         src.Metrics,
+		MetricsAddr:     src.MetricsAddr,
+		InitMode:        src.InitMode,
+		PauseSignal:     src.PauseSignal,
+		ResumeSignal:    src.ResumeSignal,
 	}
 
 	if len(src.ErrorHandlers) > 0 {
 		dst.ErrorHandlers = append([]ErrorHandler(nil), src.ErrorHandlers...)
 	}
+	if src.Tuning != nil {
+		tuning := *src.Tuning
+		dst.Tuning = &tuning
+	}
+	if len(src.PrivilegedSetup) > 0 {
+		dst.PrivilegedSetup = append([]SetupFunc(nil), src.PrivilegedSetup...)
+	}
+	if len(src.WaitFor) > 0 {
+		dst.WaitFor = append([]WaitCondition(nil), src.WaitFor...)
+	}
+	if len(src.LogSinks) > 0 {
+		dst.LogSinks = append([]LogSink(nil), src.LogSinks...)
+	}
+	if src.CrashLoop != nil {
+		policy := *src.CrashLoop
+		dst.CrashLoop = &policy
+	}
+	if len(src.LifecycleHooks) > 0 {
+		dst.LifecycleHooks = append([]LifecycleHook(nil), src.LifecycleHooks...)
+	}
+	if len(src.Tasks) > 0 {
+		dst.Tasks = append([]ScheduledTask(nil), src.Tasks...)
+	}
 
 	if src.BuildInfo != nil {
 		dst.BuildInfo = cloneVersionInfo(src.BuildInfo)
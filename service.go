@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -21,6 +22,7 @@ var (
 	exitFunc           = os.Exit
 	serviceManagerGOOS = runtime.GOOS
 	executablePath     = os.Executable
+	newDaemonService   = service.New
 )
 
 // serviceRunSession 描述单次服务命令执行的上下文边界：
@@ -50,6 +52,12 @@ type sManager struct {
 	stopMu         sync.Mutex
 	runnerDone     chan struct{}
 	runnerErr      chan error
+	instance       string          // 当前选中的实例，空表示基础服务
+	metrics        *serviceMetrics // 未启用指标时为 nil
+	paused         atomic.Bool     // 维护模式，见 Pausable
+	pauseMu        sync.Mutex      // 串行化暂停与恢复
+	configErr      error           // 当前实例配置未通过路径检查的原因，doctor 之外的命令执行时返回
+	sys            serviceSystem   // 时钟与系统操作
 }
 
 // newServiceAssemblyManager 为 Cli 装配 service 能力。
@@ -74,13 +82,6 @@ func newServiceManager(cmd *Cli, ctx context.Context, cancel context.CancelFunc)
 	}
 
 	localizer := NewServiceLocalizer(langManager, cmd.colors)
-	out := io.Writer(os.Stdout)
-	errOut := io.Writer(os.Stderr)
-	if cmd.command != nil {
-		out = cmd.command.OutOrStdout()
-		errOut = cmd.command.ErrOrStderr()
-	}
-	localizer.ConfigureOutput(out, errOut, cmd.config.basic.SilenceErrors, cmd.config.basic.SilenceUsage)
 
 	sessionCtx := ctx
 	if sessionCtx == nil {
@@ -94,17 +95,52 @@ func newServiceManager(cmd *Cli, ctx context.Context, cancel context.CancelFunc)
 		session:       &serviceRunSession{commandCtx: commandCtx, commandCancel: commandCancel},
 		exitChan:      make(chan struct{}),
 		errorHandlers: cmd.config.runtime.ErrorHandlers,
+		sys:           defaultServiceSystem(),
 	}
+	sm.metrics = newServiceMetrics(cmd.config.runtime.Metrics, cmd.config.runtime.BuildInfo, sm.sys.now)
+	sm.configureOutput()
 
 	sm.stopExecuted.Store(false)
 	sm.forceExitOnce.Store(false)
 
-	config, err := sm.createServiceConfig()
+	config, err := sm.loadServiceConfig()
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf(localizer.FormatError("createConfig")+": %v", err)
 	}
 
+	sm.applyRuntimeOptions(config)
+
+	sm.config = config
+	svcRunner := sm.buildRunner()
+	svc, err := newDaemonService(svcRunner, config)
+	if err != nil {
+		cancel()
+		return nil, WrapError(err, ErrServiceCreate, "create")
+	}
+	sm.service = svc
+
+	return sm, nil
+}
+
+// configureOutput 设置本地化输出的目标：命令输出，或非前台运行时配置的日志 sink
+func (sm *sManager) configureOutput() {
+	out := io.Writer(os.Stdout)
+	errOut := io.Writer(os.Stderr)
+	if sm.commands.command != nil {
+		out = sm.commands.command.OutOrStdout()
+		errOut = sm.commands.command.ErrOrStderr()
+	}
+	if sink := sm.serviceLogSink(); sink != nil {
+		out = LevelWriter(sink, LogLevelInfo)
+		errOut = LevelWriter(sink, LogLevelError)
+	}
+	basic := sm.commands.config.basic
+	sm.localizer.ConfigureOutput(out, errOut, basic.SilenceErrors, basic.SilenceUsage)
+}
+
+// applyRuntimeOptions 把运行期信号等待与超时配置写入 daemon 配置。
+func (sm *sManager) applyRuntimeOptions(config *service.Config) {
 	if config.Option == nil {
 		config.Option = make(service.KeyValue)
 	}
@@ -135,17 +171,6 @@ func newServiceManager(cmd *Cli, ctx context.Context, cancel context.CancelFunc)
 	if sm.commands.config.runtime.StopTimeout > 0 {
 		config.Timeout.Stop = sm.commands.config.runtime.StopTimeout
 	}
-
-	sm.config = config
-	svcRunner := sm.buildRunner()
-	svc, err := service.New(svcRunner, config)
-	if err != nil {
-		cancel()
-		return nil, WrapError(err, ErrServiceCreate, "create")
-	}
-	sm.service = svc
-
-	return sm, nil
 }
 
 // buildRunner 构造符合 daemon 的 ServiceRunner
@@ -215,23 +240,107 @@ func (sm *sManager) clearServiceContext(session *serviceRunSession) {
 	session.serviceCancel = nil
 }
 
-// Name 返回服务名称
+// Name 返回当前实例的服务名称
 func (sm *sManager) Name() string {
-	return sm.commands.config.basic.Name
+	return instanceServiceName(sm.commands.config.basic.Name, sm.instance)
 }
 
 // createServiceConfig 创建服务配置
 func (sm *sManager) createServiceConfig() (*service.Config, error) {
-	svcCfg := cloneService(sm.commands.config.service)
-	displayName := sm.commands.config.basic.DisplayName
-	if displayName == "" {
-		displayName = sm.commands.config.basic.Name
+	svcCfg, err := sm.baseServiceConfig()
+	if err != nil {
+		return nil, err
+	}
+	return sm.buildServiceConfig(svcCfg)
+}
+
+// loadServiceConfig 创建当前实例的 daemon 配置。模板或路径检查失败时退回未检查的配置，
+// 错误记录在 configErr 中：命令仍可注册，doctor 可以诊断，其他命令执行时返回该错误
+func (sm *sManager) loadServiceConfig() (*service.Config, error) {
+	config, err := sm.createServiceConfig()
+	if err == nil {
+		sm.configErr = nil
+		return config, nil
+	}
+	svcCfg, _ := sm.baseServiceConfig()
+	fallback, translateErr := sm.translateServiceConfig(svcCfg)
+	if translateErr != nil {
+		return nil, err
+	}
+	err = fmt.Errorf("%s: %w", sm.localizer.FormatError("createConfig"), err)
+	sm.configErr = WrapServiceOperationError(err, ErrConfigInvalid, "create", svcCfg.Name)
+	return fallback, nil
+}
+
+// applyResourceTuning 在调用 Run 前执行进程级资源调优，逐项写入启动日志。
+// 结果附加到返回的上下文中，可通过 AppliedTuning 读取，并写入 tuningFile 供 status 展示；
+// 返回的函数在 Run 退出时删除该文件
+func (sm *sManager) applyResourceTuning(ctx context.Context) (context.Context, func()) {
+	tuning := sm.commands.config.runtime.Tuning
+	if tuning == nil {
+		return ctx, func() {}
+	}
+	results := tuning.apply(&sm.sys)
+	for _, result := range results {
+		if result.Err != nil {
+			sm.localizer.LogWarning(sm.localizer.GetMessage("tuningFailed"), result.Name, result.Err)
+			continue
+		}
+		sm.localizer.LogDetail(tuningLabels[result.Name], result.Value)
+	}
+	path := sm.tuningFile()
+	if path == "" {
+		return context.WithValue(ctx, tuningResultsKey{}, results), func() {}
 	}
+	// 结果文件只用于 status 展示，写入失败不影响启动
+	_ = writeTuningResults(path, results)
+	return context.WithValue(ctx, tuningResultsKey{}, results), func() { _ = os.Remove(path) }
+}
 
+// baseServiceConfig 返回当前实例的 ServiceConfig 副本，并补齐来自 Basic 的身份字段。
+func (sm *sManager) baseServiceConfig() (ServiceConfig, error) {
+	return sm.serviceConfigFor(sm.instance)
+}
+
+// currentLayout 返回当前实例的目录布局；实例模板已在选择实例时校验，这里不再返回错误
+func (sm *sManager) currentLayout() ServiceLayout {
+	svcCfg, _ := sm.serviceConfigFor(sm.instance)
+	return svcCfg.Layout
+}
+
+// buildServiceConfig 将 ServiceConfig 转换为 daemon 配置，并执行路径权限检查。
+// 环境变量文件只在 run、install、export 中读取，这里不解析，避免文件缺失时所有命令都不可用
+func (sm *sManager) buildServiceConfig(svcCfg ServiceConfig) (*service.Config, error) {
+	config, err := sm.translateServiceConfig(svcCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if serviceManagerGOOS != "windows" {
+		if err := checkPermissions(config.Executable, 0o755, sm.localizer); err != nil {
+			return nil, fmt.Errorf("%s", sm.localizer.FormatError("execPermission", config.Executable, err))
+		}
+		if config.WorkingDirectory != "" {
+			if err := checkPermissions(config.WorkingDirectory, os.ModeDir|0o755, sm.localizer); err != nil {
+				return nil, fmt.Errorf("%s", sm.localizer.FormatError("workDirPermission", config.WorkingDirectory, err))
+			}
+		}
+		if config.ChRoot != "" {
+			if err := checkPermissions(config.ChRoot, os.ModeDir|0o755, sm.localizer); err != nil {
+				return nil, fmt.Errorf("%s", sm.localizer.FormatError("chrootPermission", config.ChRoot, err))
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// translateServiceConfig 将 ServiceConfig 转换为 daemon 配置并补齐默认值，不访问文件系统。
+func (sm *sManager) translateServiceConfig(svcCfg ServiceConfig) (*service.Config, error) {
 	config := &service.Config{
-		Name:              sm.commands.config.basic.Name,
-		DisplayName:       displayName,
-		Description:       sm.commands.config.basic.Description,
+		Name:              svcCfg.Name,
+		DisplayName:       svcCfg.DisplayName,
+		Description:       svcCfg.Description,
 		UserName:          svcCfg.Username,
 		Arguments:         []string{"run"},
 		Dependencies:      append([]string(nil), svcCfg.Dependencies...),
@@ -243,10 +352,9 @@ func (sm *sManager) createServiceConfig() (*service.Config, error) {
 	if svcCfg.Arguments != nil {
 		config.Arguments = append([]string(nil), svcCfg.Arguments...)
 	}
-	if svcCfg.Options != nil {
-		config.Option = make(service.KeyValue, len(svcCfg.Options))
-		maps.Copy(config.Option, svcCfg.Options)
-	}
+	config.Option = make(service.KeyValue, len(svcCfg.Options))
+	maps.Copy(config.Option, svcCfg.Options)
+	svcCfg.applyPlatformOptions(config.Option)
 
 	execPath := svcCfg.Executable
 	if execPath == "" {
@@ -264,23 +372,29 @@ func (sm *sManager) createServiceConfig() (*service.Config, error) {
 	}
 	config.WorkingDirectory = workDir
 
-	if serviceManagerGOOS != "windows" {
-		if err := checkPermissions(config.Executable, 0o755, sm.localizer); err != nil {
-			return nil, fmt.Errorf("%s", sm.localizer.FormatError("execPermission", config.Executable, err))
-		}
-		if config.WorkingDirectory != "" {
-			if err := checkPermissions(config.WorkingDirectory, os.ModeDir|0o755, sm.localizer); err != nil {
-				return nil, fmt.Errorf("%s", sm.localizer.FormatError("workDirPermission", config.WorkingDirectory, err))
-			}
-		}
-		if config.ChRoot != "" {
-			if err := checkPermissions(config.ChRoot, os.ModeDir|0o755, sm.localizer); err != nil {
-				return nil, fmt.Errorf("%s", sm.localizer.FormatError("chrootPermission", config.ChRoot, err))
-			}
-		}
+	sm.attachSystemdScript(svcCfg, config)
+	return config, nil
+}
+
+// attachSystemdScript 在配置了 unit 专用指令且未提供自定义脚本时，
+// 把追加了这些指令的 daemon 默认模板作为 SystemdScript 交给 daemon。
+func (sm *sManager) attachSystemdScript(svcCfg ServiceConfig, config *service.Config) {
+	if len(sm.systemdDirectives(svcCfg)) == 0 {
+		return
 	}
+	if _, custom := svcCfg.Options["SystemdScript"]; custom {
+		return
+	}
+	config.Option["SystemdScript"] = renderSystemdTemplate(sm.newExportSpec(svcCfg, config))
+}
 
-	return config, nil
+// systemdDirectives 返回写入 unit 的专用指令；启用崩溃循环检测时阻止 systemd 重启以 CrashLoopExitCode 退出的进程
+func (sm *sManager) systemdDirectives(svcCfg ServiceConfig) []string {
+	lines := svcCfg.Systemd.unitDirectives()
+	if sm.commands.config.runtime.CrashLoop != nil {
+		lines = append(lines, "RestartPreventExitStatus="+strconv.Itoa(CrashLoopExitCode))
+	}
+	return lines
 }
 
 func cloneStringMap(src map[string]string) map[string]string {
@@ -0,0 +1,235 @@
+package zcli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// installRecordName 是记录 zcli 所建账号与所复制可执行文件的文件名
+const installRecordName = ".zcli-install"
+
+// serviceAccount 描述由 zcli 创建的系统账号，字段为空表示该项已存在而非 zcli 创建
+type serviceAccount struct {
+	User  string
+	Group string
+}
+
+// provisionAccount 在用户或同名组缺失时创建锁定的系统账号。
+// 两者均已存在时返回 nil。
+func (sm *sManager) provisionAccount(username, home string) (*serviceAccount, error) {
+	if serviceManagerGOOS != "linux" {
+		return nil, fmt.Errorf("creating system users is not supported on %s", serviceManagerGOOS)
+	}
+
+	account := &serviceAccount{}
+	if _, err := sm.sys.lookupGroup(username); err != nil {
+		var unknown user.UnknownGroupError
+		if !errors.As(err, &unknown) {
+			return nil, err
+		}
+		if err := sm.sys.runCommand("groupadd", "--system", username); err != nil {
+			return nil, err
+		}
+		account.Group = username
+	}
+
+	if _, err := sm.sys.lookupUser(username); err != nil {
+		var unknown user.UnknownUserError
+		if !errors.As(err, &unknown) {
+			return nil, CombineErrors(err, sm.removeAccount(account))
+		}
+		args := []string{"--system", "--gid", username, "--no-create-home", "--shell", "/usr/sbin/nologin"}
+		if home != "" {
+			args = append(args, "--home-dir", home)
+		}
+		if err := sm.sys.runCommand("useradd", append(args, username)...); err != nil {
+			return nil, CombineErrors(err, sm.removeAccount(account))
+		}
+		account.User = username
+		if err := sm.sys.runCommand("usermod", "--lock", username); err != nil {
+			return nil, CombineErrors(err, sm.removeAccount(account))
+		}
+	}
+
+	if account.User == "" && account.Group == "" {
+		return nil, nil
+	}
+	return account, nil
+}
+
+// removeAccount 删除 zcli 创建的用户与用户组
+func (sm *sManager) removeAccount(a *serviceAccount) error {
+	if a == nil {
+		return nil
+	}
+	var errs []error
+	if a.User != "" {
+		if err := sm.sys.runCommand("userdel", a.User); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// userdel 可能已连带删除同名私有组
+	if a.Group != "" {
+		if _, err := sm.sys.lookupGroup(a.Group); err == nil {
+			if err := sm.sys.runCommand("groupdel", a.Group); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return CombineErrors(errs...)
+}
+
+// String 返回便于展示的账号描述
+func (a *serviceAccount) String() string {
+	switch {
+	case a.User != "" && a.Group != "":
+		return a.User + ":" + a.Group
+	case a.User != "":
+		return a.User
+	default:
+		return ":" + a.Group
+	}
+}
+
+// installRecord 记录 install 创建的内容，uninstall --purge 只清理其中列出的账号与文件
+type installRecord struct {
+	account *serviceAccount
+	binary  string // 复制到 BinaryPrefix 的可执行文件
+}
+
+// empty 报告记录中是否没有需要清理的内容
+func (r *installRecord) empty() bool {
+	return r == nil || (r.account == nil && r.binary == "")
+}
+
+// installRecordDir 返回安装记录所在目录。记录不放在服务账号可写的 StateDir 中，
+// 否则服务可以改写记录，让 root 执行的 uninstall --purge 删除任意账号或文件
+func (sm *sManager) installRecordDir(name string) string {
+	return filepath.Join(sm.sys.recordRoot, name)
+}
+
+// writeInstallRecord 在 dir 下写入仅 root 可读写的安装记录，返回回滚时需要删除的路径
+func writeInstallRecord(dir string, record *installRecord) (string, error) {
+	undo := filepath.Join(dir, installRecordName)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		undo = dir
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	var content strings.Builder
+	if record.account != nil {
+		_, _ = fmt.Fprintf(&content, "user=%s\ngroup=%s\n", record.account.User, record.account.Group)
+	}
+	if record.binary != "" {
+		_, _ = fmt.Fprintf(&content, "binary=%s\n", record.binary)
+	}
+	path := filepath.Join(dir, installRecordName)
+	if err := os.WriteFile(path, []byte(content.String()), 0o600); err != nil {
+		return undo, err
+	}
+	// 记录已存在时 WriteFile 不修改权限
+	return undo, os.Chmod(path, 0o600)
+}
+
+// readInstallRecord 读取 dir 下的安装记录，记录不存在时返回 nil。
+// 不是属于 root 且权限为 0600 的普通文件时拒绝使用
+func readInstallRecord(dir string) (*installRecord, error) {
+	path := filepath.Join(dir, installRecordName)
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uid, _, ok := fileOwner(info); !info.Mode().IsRegular() || info.Mode().Perm() != 0o600 || (ok && uid != 0) {
+		return nil, fmt.Errorf("refusing to use install record %s: must be a regular file owned by root with mode 0600", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	record := &installRecord{}
+	account := &serviceAccount{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		switch key {
+		case "user":
+			account.User = value
+		case "group":
+			account.Group = value
+		case "binary":
+			record.binary = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if account.User != "" || account.Group != "" {
+		record.account = account
+	}
+	if record.empty() {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// maxSystemAccountID 系统账号 ID 的上限（不含），与 useradd --system 的默认范围一致
+const maxSystemAccountID = 1000
+
+// checkRecordedAccount 确认记录中的账号仍是 zcli 以 --system 创建的系统账号，
+// 拒绝删除 root 或普通用户；已不存在的账号交由 removeAccount 跳过或报错
+func (sm *sManager) checkRecordedAccount(a *serviceAccount) error {
+	if a == nil {
+		return nil
+	}
+	if a.User != "" {
+		if u, err := sm.sys.lookupUser(a.User); err == nil && !isSystemAccountID(u.Uid) {
+			return fmt.Errorf("refusing to remove user %s (uid %s): not a system account", a.User, u.Uid)
+		}
+	}
+	if a.Group != "" {
+		if g, err := sm.sys.lookupGroup(a.Group); err == nil && !isSystemAccountID(g.Gid) {
+			return fmt.Errorf("refusing to remove group %s (gid %s): not a system group", a.Group, g.Gid)
+		}
+	}
+	return nil
+}
+
+// isSystemAccountID 报告 id 是否位于系统账号范围且不是 root
+func isSystemAccountID(id string) bool {
+	n, err := strconv.Atoi(id)
+	return err == nil && n > 0 && n < maxSystemAccountID
+}
+
+// withAccount 返回归属于 owner 的布局副本：
+// 未配置 StateDir 时使用默认状态目录作为账号主目录，未指定属主的目录改为 owner。
+func (l ServiceLayout) withAccount(name, owner string) ServiceLayout {
+	l = l.withDefaultStateDir(name)
+	for _, dir := range []*ServiceDirectory{&l.StateDir, &l.LogDir, &l.RuntimeDir, &l.ConfigDir} {
+		if dir.Path != "" && dir.Owner == "" {
+			dir.Owner = owner
+		}
+	}
+	return l
+}
+
+// withDefaultStateDir 未配置 StateDir 时改用默认状态目录
+func (l ServiceLayout) withDefaultStateDir(name string) ServiceLayout {
+	if l.StateDir.Path == "" {
+		l.StateDir = DefaultServiceLayout(name).StateDir
+	}
+	return l
+}
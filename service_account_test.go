@@ -0,0 +1,283 @@
+package zcli
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+// fakeAccounts 模拟系统账号数据库，记录执行过的账号管理命令
+type fakeAccounts struct {
+	users    map[string]bool
+	groups   map[string]bool
+	commands []string
+}
+
+func installFakeAccounts(t *testing.T, sm *sManager) *fakeAccounts {
+	t.Helper()
+	accounts := &fakeAccounts{users: map[string]bool{}, groups: map[string]bool{}}
+	// uninstall --purge 只删除系统账号，root 下改用系统账号范围内的 ID
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+	if os.Getuid() == 0 {
+		uid, gid = "990", "990"
+	}
+
+	sm.sys.lookupUser = func(name string) (*user.User, error) {
+		if !accounts.users[name] {
+			return nil, user.UnknownUserError(name)
+		}
+		return &user.User{Username: name, Uid: uid, Gid: gid}, nil
+	}
+	sm.sys.lookupGroup = func(name string) (*user.Group, error) {
+		if !accounts.groups[name] {
+			return nil, user.UnknownGroupError(name)
+		}
+		return &user.Group{Name: name, Gid: gid}, nil
+	}
+	sm.sys.runCommand = func(name string, args ...string) error {
+		accounts.commands = append(accounts.commands, name)
+		target := args[len(args)-1]
+		switch name {
+		case "groupadd":
+			accounts.groups[target] = true
+		case "useradd":
+			accounts.users[target] = true
+		case "userdel":
+			delete(accounts.users, target)
+		case "groupdel":
+			delete(accounts.groups, target)
+		}
+		return nil
+	}
+	prevGOOS := serviceManagerGOOS
+	serviceManagerGOOS = "linux"
+	t.Cleanup(func() { serviceManagerGOOS = prevGOOS })
+	return accounts
+}
+
+// skipUnlessRoot 安装记录必须属于 root，非 root 运行时无法读取测试写入的记录
+func skipUnlessRoot(t *testing.T) {
+	t.Helper()
+	if os.Getuid() != 0 {
+		t.Skip("install records must be owned by root")
+	}
+}
+
+func TestInstallCommand_CreateUserProvisionsAndRecordsAccount(t *testing.T) {
+	skipUnlessRoot(t)
+	stateDir := filepath.Join(t.TempDir(), "lib", "test-service")
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{StateDir: ServiceDirectory{Path: stateDir}})
+	accounts := installFakeAccounts(t, sm)
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--create-user"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install --create-user: %v", err)
+	}
+
+	if got := strings.Join(accounts.commands, ","); got != "groupadd,useradd,usermod" {
+		t.Fatalf("unexpected account commands: %s", got)
+	}
+	record, err := readInstallRecord(sm.installRecordDir(sm.Name()))
+	if err != nil || record == nil || record.account == nil {
+		t.Fatalf("expected account record, got %v, %v", record, err)
+	}
+	account := record.account
+	if account.User != sm.Name() || account.Group != sm.Name() {
+		t.Fatalf("unexpected account record: %+v", account)
+	}
+
+	accounts.commands = nil
+	cmd = sm.newUninstallCmd()
+	if err := cmd.ParseFlags([]string{"--purge", "--yes"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	stub.statusErr = nil
+	stub.status = service.StatusStopped
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("uninstall --purge: %v", err)
+	}
+	if got := strings.Join(accounts.commands, ","); got != "userdel,groupdel" {
+		t.Fatalf("unexpected purge commands: %s", got)
+	}
+	if _, err := os.Stat(stateDir); !os.IsNotExist(err) {
+		t.Fatalf("expected state dir to be purged, stat err=%v", err)
+	}
+	if _, err := os.Stat(sm.installRecordDir(sm.Name())); !os.IsNotExist(err) {
+		t.Fatalf("expected install record to be purged, stat err=%v", err)
+	}
+}
+
+func TestInstallCommand_RecordKeptOutOfStateDir(t *testing.T) {
+	skipUnlessRoot(t)
+	stateDir := filepath.Join(t.TempDir(), "lib", "test-service")
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{StateDir: ServiceDirectory{Path: stateDir}})
+	installFakeAccounts(t, sm)
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--create-user"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install --create-user: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, installRecordName)); !os.IsNotExist(err) {
+		t.Fatalf("the service account owns StateDir, the record must not live there: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(sm.installRecordDir(sm.Name()), installRecordName))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a 0600 install record, got %v, %v", info, err)
+	}
+}
+
+func TestUninstallCommand_PurgeRejectsTamperedRecord(t *testing.T) {
+	skipUnlessRoot(t)
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{})
+	accounts := installFakeAccounts(t, sm)
+	accounts.users["test-service"] = true
+	dir := sm.installRecordDir(sm.Name())
+	if _, err := writeInstallRecord(dir, &installRecord{account: &serviceAccount{User: "test-service"}}); err != nil {
+		t.Fatalf("writeInstallRecord: %v", err)
+	}
+	path := filepath.Join(dir, installRecordName)
+
+	purge := func() error {
+		cmd := sm.newUninstallCmd()
+		if err := cmd.ParseFlags([]string{"--purge", "--yes"}); err != nil {
+			t.Fatalf("ParseFlags: %v", err)
+		}
+		return cmd.RunE(cmd, nil)
+	}
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if err := purge(); !IsErrorCode(err, ErrServiceUninstall) {
+		t.Fatalf("a record with loose permissions must be refused, got %v", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if err := os.Chown(path, 990, 990); err != nil {
+		t.Fatalf("chown: %v", err)
+	}
+	if err := purge(); !IsErrorCode(err, ErrServiceUninstall) {
+		t.Fatalf("a record not owned by root must be refused, got %v", err)
+	}
+	if len(accounts.commands) != 0 || stub.uninstallCalled != 0 {
+		t.Fatalf("nothing may be removed, commands=%v uninstall=%d", accounts.commands, stub.uninstallCalled)
+	}
+}
+
+func TestUninstallCommand_PurgeRefusesNonSystemAccount(t *testing.T) {
+	skipUnlessRoot(t)
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{})
+	accounts := installFakeAccounts(t, sm)
+	sm.sys.lookupUser = func(name string) (*user.User, error) {
+		return &user.User{Username: name, Uid: "0", Gid: "0"}, nil
+	}
+	if _, err := writeInstallRecord(sm.installRecordDir(sm.Name()), &installRecord{account: &serviceAccount{User: "root"}}); err != nil {
+		t.Fatalf("writeInstallRecord: %v", err)
+	}
+
+	cmd := sm.newUninstallCmd()
+	if err := cmd.ParseFlags([]string{"--purge", "--yes"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrServiceUninstall) || !strings.Contains(err.Error(), "not a system account") {
+		t.Fatalf("expected the root account to be refused, got %v", err)
+	}
+	if len(accounts.commands) != 0 {
+		t.Fatalf("no account may be removed, got %v", accounts.commands)
+	}
+}
+
+func TestInstallCommand_CreateUserKeepsExistingAccount(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "lib", "test-service")
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{StateDir: ServiceDirectory{Path: stateDir}})
+	accounts := installFakeAccounts(t, sm)
+	accounts.users["svc"] = true
+	accounts.groups["svc"] = true
+	sm.config.UserName = "svc"
+	sm.commands.config.service.CreateUser = true
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if len(accounts.commands) != 0 {
+		t.Fatalf("existing account must not be recreated: %v", accounts.commands)
+	}
+	if record, _ := readInstallRecord(sm.installRecordDir(sm.Name())); record != nil {
+		t.Fatalf("existing account must not be recorded: %+v", record)
+	}
+}
+
+func TestInstallCommand_CreateUserRollsBackOnFailure(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "lib", "test-service")
+	stub := &fakeDaemonService{
+		status:     service.StatusUnknown,
+		statusErr:  service.ErrNotInstalled,
+		installErr: errors.New("install failed"),
+	}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{StateDir: ServiceDirectory{Path: stateDir}})
+	accounts := installFakeAccounts(t, sm)
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--create-user"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrServiceInstall) {
+		t.Fatalf("expected ErrServiceInstall, got %v", err)
+	}
+	if len(accounts.users) != 0 || len(accounts.groups) != 0 {
+		t.Fatalf("expected created account to be removed, users=%v groups=%v", accounts.users, accounts.groups)
+	}
+	if _, err := os.Stat(stateDir); !os.IsNotExist(err) {
+		t.Fatalf("expected state dir to be rolled back, stat err=%v", err)
+	}
+	if _, err := os.Stat(sm.installRecordDir(sm.Name())); !os.IsNotExist(err) {
+		t.Fatalf("expected install record to be rolled back, stat err=%v", err)
+	}
+}
+
+func TestInstallCommand_CreateUserDefaultsToBaseName(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "lib", "test-service")
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, _ := newLayoutTestManager(t, stub, ServiceLayout{StateDir: ServiceDirectory{Path: stateDir}})
+	accounts := installFakeAccounts(t, sm)
+	sm.config.Name = instanceServiceName(sm.Name(), "eu1")
+	var installed *service.Config
+	prevNew := newDaemonService
+	newDaemonService = func(_ service.Interface, config *service.Config) (service.Service, error) {
+		installed = config
+		return stub, nil
+	}
+	t.Cleanup(func() { newDaemonService = prevNew })
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--create-user"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install --create-user: %v", err)
+	}
+	if !accounts.users["test-service"] || len(accounts.users) != 1 {
+		t.Fatalf("instances should share the account named after the service, got %v", accounts.users)
+	}
+	if installed == nil || installed.EnvVars[serviceUserEnvVar] != "test-service" {
+		t.Fatalf("the account should be passed to run for the privilege drop, got %+v", installed)
+	}
+}
@@ -41,6 +41,7 @@ func TestCreateServiceConfig_PropagatesDaemonFields(t *testing.T) {
 	sm := &sManager{
 		commands:  cli,
 		localizer: NewServiceLocalizer(GetLanguageManager(), cli.colors),
+		sys:       defaultServiceSystem(),
 	}
 
 	svcConfig, err := sm.createServiceConfig()
@@ -366,3 +367,122 @@ func waitForRunning(t *testing.T, svc *BaseService) {
 		}
 	}
 }
+
+// ctxLifecycleRunner 同时实现 ServiceRunner 与 ContextLifecycle
+type ctxLifecycleRunner struct {
+	runnerWrapper
+	beforeStart func(ctx context.Context) error
+	stopCauses  chan *ShutdownCause
+	deadlines   atomic.Int32
+}
+
+func (r *ctxLifecycleRunner) BeforeStart(ctx context.Context) error {
+	if r.beforeStart != nil {
+		return r.beforeStart(ctx)
+	}
+	return nil
+}
+
+func (r *ctxLifecycleRunner) AfterStart(context.Context) error { return nil }
+
+func (r *ctxLifecycleRunner) BeforeStop(ctx context.Context, cause *ShutdownCause) error {
+	if _, ok := ctx.Deadline(); ok {
+		r.deadlines.Add(1)
+	}
+	r.stopCauses <- cause
+	return nil
+}
+
+func (r *ctxLifecycleRunner) AfterStop(ctx context.Context, cause *ShutdownCause) error {
+	if _, ok := ctx.Deadline(); ok {
+		r.deadlines.Add(1)
+	}
+	r.stopCauses <- cause
+	return nil
+}
+
+func TestManagedService_CallsBothLifecyclesAndPassesCause(t *testing.T) {
+	runner := &ctxLifecycleRunner{
+		runnerWrapper: runnerWrapper{runDone: make(chan struct{})},
+		stopCauses:    make(chan *ShutdownCause, 2),
+	}
+	legacy := &countingLifecycle{afterStart: make(chan struct{}, 1)}
+	managed := NewManagedService(runner, legacy)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- managed.Run(ctx) }()
+	select {
+	case <-legacy.afterStart:
+	case <-time.After(time.Second):
+		t.Fatal("ServiceLifecycle.AfterStart should run next to ContextLifecycle")
+	}
+	cancel(newShutdownCause(ShutdownReasonSignal, nil, nil))
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("graceful stop should return nil, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("managed service did not stop")
+	}
+	for _, phase := range []LifecyclePhase{PhaseBeforeStop, PhaseAfterStop} {
+		if cause := <-runner.stopCauses; cause == nil || cause.Reason != ShutdownReasonSignal {
+			t.Fatalf("%s should receive the shutdown cause, got %+v", phase, cause)
+		}
+	}
+	if got := runner.deadlines.Load(); got != 2 {
+		t.Fatalf("stop phases should carry a deadline, got %d", got)
+	}
+	if legacy.beforeStop.Load() != 1 || legacy.afterStop.Load() != 1 {
+		t.Fatalf("ServiceLifecycle stop hooks should run once each, got %d/%d", legacy.beforeStop.Load(), legacy.afterStop.Load())
+	}
+}
+
+func TestManagedService_BeforeStartTimeoutNamesPhase(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	runner := &ctxLifecycleRunner{
+		runnerWrapper: runnerWrapper{runDone: make(chan struct{})},
+		// 模拟忽略 ctx 的钩子
+		beforeStart: func(context.Context) error {
+			<-release
+			return nil
+		},
+	}
+	managed := NewContextManagedService(runner, runner).
+		WithLifecycleTimeouts(LifecycleTimeouts{BeforeStart: 20 * time.Millisecond})
+
+	err := managed.Run(context.Background())
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrLifecycleTimeout || serviceErr.Operation != string(PhaseBeforeStart) {
+		t.Fatalf("expected BeforeStart timeout, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "BeforeStart") {
+		t.Fatalf("error should name the phase: %v", err)
+	}
+}
+
+func TestManagedService_BeforeStartFollowsRunContext(t *testing.T) {
+	runner := &ctxLifecycleRunner{
+		runnerWrapper: runnerWrapper{runDone: make(chan struct{})},
+		beforeStart: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	managed := NewManagedService(runner, nil).
+		WithLifecycleTimeouts(LifecycleTimeouts{BeforeStart: -1})
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel(newShutdownCause(ShutdownReasonSignal, nil, nil))
+	}()
+	err := managed.Run(ctx)
+	var cause *ShutdownCause
+	if !errors.As(err, &cause) || cause.Reason != ShutdownReasonSignal {
+		t.Fatalf("cancelling Run during BeforeStart should return the shutdown cause, got %v", err)
+	}
+}
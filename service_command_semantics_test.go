@@ -107,6 +107,7 @@ func TestAttachServiceAssemblyInjectsCommandsAndPreservesRootFallback(t *testing
 		commands:  cli,
 		localizer: localizer,
 		service:   stub,
+		sys:       defaultServiceSystem(),
 	}
 
 	cli.attachServiceAssembly(sm)
@@ -144,9 +145,12 @@ func newTestServiceManager(t *testing.T, svc service.Service) *sManager {
 	localizer := NewServiceLocalizer(GetLanguageManager(), cli.colors)
 	localizer.ConfigureOutput(io.Discard, io.Discard, false, false)
 
-	return &sManager{
+	sm := &sManager{
 		commands:  cli,
 		localizer: localizer,
 		service:   svc,
+		sys:       defaultServiceSystem(),
 	}
+	sm.sys.recordRoot = t.TempDir()
+	return sm
 }
@@ -2,6 +2,8 @@ package zcli
 
 import (
 	"errors"
+	"fmt"
+	"maps"
 
 	service "github.com/darkit/daemon"
 	"github.com/spf13/cobra"
@@ -9,8 +11,8 @@ import (
 
 // initService 初始化服务
 func (c *Cli) initService() {
-	// 检查是否设置了服务运行函数
-	if c.config.runtime.Run == nil {
+	// 检查是否设置了服务运行函数或计划任务
+	if !c.config.runtime.hasService() {
 		return
 	}
 
@@ -38,16 +40,27 @@ func (c *Cli) attachServiceAssembly(sm *sManager) {
 // addServiceCommands 添加服务管理命令。
 // 该阶段只负责命令树注入，不修改根命令默认运行策略。
 func (c *Cli) addServiceCommands(sm *sManager) {
+	install, uninstall := sm.newInstallCmd(), sm.newUninstallCmd()
+	start, stop, restart := sm.newStartCmd(), sm.newStopCmd(), sm.newRestartCmd()
+	sm.restrictInitModeCommands(install, uninstall, start, stop, restart)
+
 	// 向命令行应用添加服务管理命令
 	c.command.AddCommand(
 		sm.newRunCmd(),
-		sm.newInstallCmd(),
-		sm.newUninstallCmd(),
-		sm.newStartCmd(),
-		sm.newStopCmd(),
-		sm.newRestartCmd(),
+		install,
+		uninstall,
+		start,
+		stop,
+		restart,
 		sm.newStatusCmd(),
+		sm.newLogsCmd(),
+		sm.newExportCmd(),
+		sm.newListCmd(),
+		sm.newDoctorCmd(),
 	)
+	if sm.pausable() {
+		c.command.AddCommand(sm.newPauseCmd(controlPause), sm.newPauseCmd(controlResume))
+	}
 }
 
 // attachServiceRootRun 设置根命令的运行策略，处理直接运行的情况。
@@ -63,36 +76,62 @@ func (c *Cli) attachServiceRootRun(sm *sManager) {
 			originalRun(cmd, args)
 			return nil
 		default:
+			if err := sm.selectInstance(cmd); err != nil {
+				return sm.handleError(err)
+			}
+			if sm.configErr != nil {
+				return sm.handleError(sm.configErr)
+			}
 			return sm.executeRunCommand(cmd, args)
 		}
 	}
 }
 
 func (sm *sManager) buildBaseCommand(use, short string) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   use,
 		Short: short,
 	}
+	sm.bindInstanceFlag(cmd)
+	return cmd
 }
 
 func (sm *sManager) wrapRunE(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
 	if runE == nil {
 		return nil
 	}
+	return sm.wrapDiagnosticRunE(func(cmd *cobra.Command, args []string) error {
+		if sm.configErr != nil {
+			return sm.configErr
+		}
+		return runE(cmd, args)
+	})
+}
+
+// wrapDiagnosticRunE 选择实例后执行命令，不要求配置通过检查，供 doctor 诊断配置问题
+func (sm *sManager) wrapDiagnosticRunE(runE func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		if err := sm.selectInstance(cmd); err != nil {
+			return sm.handleError(err)
+		}
 		return sm.handleError(runE(cmd, args))
 	}
 }
 
 func (sm *sManager) queryServiceStatus() (service.Status, error) {
-	status, err := sm.service.Status()
+	return sm.queryStatusOf(sm.service, sm.Name())
+}
+
+// queryStatusOf 查询指定 daemon 服务实例的状态，并统一错误语义。
+func (sm *sManager) queryStatusOf(svc service.Service, name string) (service.Status, error) {
+	status, err := svc.Status()
 	if err == nil {
 		return status, nil
 	}
 	if errors.Is(err, service.ErrNotInstalled) {
-		return status, ErrServiceNotInstalled(sm.Name()).WithCause(err)
+		return status, ErrServiceNotInstalled(name).WithCause(err)
 	}
-	return status, sm.wrapServiceError(err, ErrServiceStatus, "status")
+	return status, WrapServiceOperationError(err, ErrServiceStatus, "status", name)
 }
 
 func isNotInstalled(err error) bool {
@@ -102,35 +141,116 @@ func isNotInstalled(err error) bool {
 // newInstallCmd 创建安装服务命令
 func (sm *sManager) newInstallCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("install", sm.localizer.GetOperation("install"))
+	overrides := &installOverrides{}
+	sm.bindInstallFlags(cmd, overrides)
+	var createUser bool
+	cmd.Flags().BoolVar(&createUser, "create-user", false, sm.localizer.GetFlag("createUser"))
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
-		// 检查权限
-		var err error
-
-		// 创建服务实例
-		if sm.service == nil {
-			svc, createErr := service.New(sm.buildRunner(), sm.config)
-			if createErr != nil {
-				return WrapError(createErr, ErrServiceCreate, "install")
-			}
-			sm.service = svc
+		// 合并命令行覆盖项并创建服务实例
+		svc, config, err := sm.resolveInstallTarget(overrides)
+		if err != nil {
+			return err
+		}
+		name := sm.Name()
+		if config != nil {
+			name = config.Name
 		}
 
 		// 检查服务是否已安装
-		status, statusErr := sm.queryServiceStatus()
+		status, statusErr := sm.queryStatusOf(svc, name)
 		if statusErr == nil && status != service.StatusUnknown {
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "alreadyExists")
+			sm.localizer.LogInfo(name, "alreadyExists")
 			return nil
 		}
 		if statusErr != nil && !isNotInstalled(statusErr) {
 			return statusErr
 		}
 
-		// 安装服务
-		if err = sm.service.Install(); err != nil {
-			return sm.wrapServiceError(err, ErrServiceInstall, "install")
+		// 准备运行账号与受管目录布局，必要时复制可执行文件并改用副本
+		base, err := sm.baseServiceConfig()
+		if err != nil {
+			return WrapServiceOperationError(err, ErrConfigInvalid, "install", name)
+		}
+		layout := base.Layout
+		wantUser := createUser || base.CreateUser
+		rebuild := layout.BinaryPrefix != ""
+		if config != nil && (wantUser || !layout.IsZero()) {
+			clone := *config
+			config = &clone
 		}
 
-		sm.localizer.LogSuccess(sm.commands.config.basic.Name, "install")
+		var account *serviceAccount
+		if wantUser && config != nil {
+			// 默认账号使用不含实例后缀的服务名，各实例共用同一账号
+			if config.UserName == "" {
+				config.UserName = sm.commands.config.basic.Name
+				rebuild = true
+			}
+			layout = layout.withAccount(base.Name, config.UserName)
+			if account, err = sm.provisionAccount(config.UserName, layout.StateDir.Path); err != nil {
+				err = fmt.Errorf("%s", sm.localizer.FormatError("accountFailed", config.UserName, err))
+				return WrapServiceOperationError(err, ErrServiceInstall, "install", name)
+			}
+		}
+
+		// --user 或默认账号改变了运行用户时写入服务环境，由 root 启动的 run 据此降权
+		if config != nil && config.UserName != base.Username {
+			clone := *config
+			clone.EnvVars = maps.Clone(config.EnvVars)
+			if clone.EnvVars == nil {
+				clone.EnvVars = make(map[string]string)
+			}
+			clone.EnvVars[serviceUserEnvVar] = clone.UserName
+			config, rebuild = &clone, true
+		}
+
+		var rollback *layoutRollback
+		record := &installRecord{account: account}
+		if !layout.IsZero() {
+			if rollback, record.binary, err = sm.prepareLayout(layout, config); err != nil {
+				return WrapServiceOperationError(CombineErrors(err, sm.removeAccount(account)), ErrServiceInstall, "install", name)
+			}
+		}
+		if account != nil {
+			rollback.onUndo(func() error { return sm.removeAccount(account) })
+		}
+		if !record.empty() {
+			// 记录 zcli 创建的账号与复制的可执行文件，供 uninstall --purge 清理
+			recordPath, err := writeInstallRecord(sm.installRecordDir(base.Name), record)
+			if err != nil {
+				return WrapServiceOperationError(CombineErrors(err, rollback.undo()), ErrServiceInstall, "install", name)
+			}
+			rollback.track(recordPath)
+		}
+		if rebuild && config != nil {
+			// 可执行文件或运行用户已变化，重新渲染生成的 unit
+			config.Option = maps.Clone(config.Option)
+			sm.attachSystemdScript(base, config)
+			if svc, err = newDaemonService(sm.buildRunner(), config); err != nil {
+				_ = rollback.undo()
+				return WrapServiceOperationError(err, ErrServiceCreate, "install", name)
+			}
+		}
+
+		// 安装服务，失败时回滚本次创建的目录与账号
+		if err = svc.Install(); err != nil {
+			return WrapServiceOperationError(CombineErrors(err, rollback.undo()), ErrServiceInstall, "install", name)
+		}
+
+		// 登记失败时 list 与 status 看不到该实例，撤销本次安装
+		if err := sm.registerInstance(); err != nil {
+			err = fmt.Errorf("%s: %w", sm.localizer.GetMessage("instanceRegistry"), err)
+			return WrapServiceOperationError(CombineErrors(err, svc.Uninstall(), rollback.undo()), ErrServiceInstall, "install", name)
+		}
+
+		sm.localizer.LogSuccess(name, "install")
+		sm.logInstallSummary(config)
+		for _, entry := range layout.directories() {
+			sm.localizer.LogDetail(entry.label, entry.dir.Path)
+		}
+		if account != nil {
+			sm.localizer.LogDetail("account", account)
+		}
 		return nil
 	})
 	return cmd
@@ -139,26 +259,79 @@ func (sm *sManager) newInstallCmd() *cobra.Command {
 // newUninstallCmd 创建卸载服务命令
 func (sm *sManager) newUninstallCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("uninstall", sm.localizer.GetOperation("uninstall"))
+	var purge, assumeYes bool
+	cmd.Flags().BoolVar(&purge, "purge", false, sm.localizer.GetFlag("purge"))
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, sm.localizer.GetFlag("yes"))
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
+		// purge 前先确认清理范围，避免卸载完成后才发现路径不安全
+		var targets []string
+		var account *serviceAccount
+		if purge {
+			base, err := sm.baseServiceConfig()
+			if err != nil {
+				return sm.wrapServiceError(err, ErrConfigInvalid, "uninstall")
+			}
+			recordDir := sm.installRecordDir(base.Name)
+			record, err := readInstallRecord(recordDir)
+			if err == nil && record != nil {
+				err = sm.checkRecordedAccount(record.account)
+			}
+			if err != nil {
+				return sm.wrapServiceError(err, ErrServiceUninstall, "uninstall")
+			}
+			if targets, err = sm.purgeTargets(base.Layout, record); err != nil {
+				return sm.wrapServiceError(err, ErrPathInvalid, "uninstall")
+			}
+			if record != nil {
+				account = record.account
+				// 未配置 StateDir 时 --create-user 使用默认状态目录作为账号主目录，该目录同样由 zcli 创建
+				if account != nil && base.Layout.StateDir.Path == "" {
+					targets = append(targets, base.Layout.withDefaultStateDir(base.Name).StateDir.Path)
+				}
+				targets = append(targets, recordDir)
+			}
+			if (len(targets) > 0 || account != nil) && !assumeYes && !sm.confirmPurge(cmd, targets, account) {
+				sm.localizer.LogWarning("%s", sm.localizer.GetMessage("purgeAborted"))
+				return nil
+			}
+		}
+
+		installed := true
 		status, statusErr := sm.queryServiceStatus()
 		if statusErr == nil && status == service.StatusUnknown {
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "notInstalled")
-			return nil
+			installed = false
 		}
 		if statusErr != nil {
-			if isNotInstalled(statusErr) {
-				sm.localizer.LogInfo(sm.commands.config.basic.Name, "notInstalled")
-				return nil
+			if !isNotInstalled(statusErr) {
+				return statusErr
 			}
-			return statusErr
+			installed = false
 		}
 
-		// 卸载服务
-		if err := sm.service.Uninstall(); err != nil {
+		if installed {
+			// 卸载服务
+			if err := sm.service.Uninstall(); err != nil {
+				return sm.wrapServiceError(err, ErrServiceUninstall, "uninstall")
+			}
+		}
+
+		if len(targets) > 0 {
+			if err := purgeLayout(targets); err != nil {
+				return sm.wrapServiceError(err, ErrServiceUninstall, "uninstall")
+			}
+		}
+		if err := sm.removeAccount(account); err != nil {
 			return sm.wrapServiceError(err, ErrServiceUninstall, "uninstall")
 		}
+		if err := sm.unregisterInstance(); err != nil {
+			sm.localizer.LogWarning("%s: %v", sm.localizer.GetMessage("instanceRegistry"), err)
+		}
 
-		sm.localizer.LogSuccess(sm.commands.config.basic.Name, "uninstall")
+		if !installed {
+			sm.localizer.LogInfo(sm.Name(), "notInstalled")
+			return nil
+		}
+		sm.localizer.LogSuccess(sm.Name(), "uninstall")
 		return nil
 	})
 	return cmd
@@ -167,6 +340,9 @@ func (sm *sManager) newUninstallCmd() *cobra.Command {
 // newStartCmd 创建启动服务命令
 func (sm *sManager) newStartCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("start", sm.localizer.GetOperation("start"))
+	opts := &waitOptions{}
+	sm.bindWaitFlags(cmd, opts)
+	sm.bindDependencyFlag(cmd)
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
 		// 检查服务状态
 		status, err := sm.queryServiceStatus()
@@ -175,16 +351,30 @@ func (sm *sManager) newStartCmd() *cobra.Command {
 		}
 
 		if status == service.StatusRunning {
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "alreadyRunning")
+			sm.localizer.LogInfo(sm.Name(), "alreadyRunning")
 			return nil
 		}
 
+		// 确认必需依赖已运行
+		if err := sm.checkDependencies(sm.commandContext(cmd), "start", dependencyWait(cmd)); err != nil {
+			return err
+		}
+
 		// 启动服务
+		if err := sm.clearCrashLoop(); err != nil {
+			return sm.wrapServiceError(err, ErrServiceStart, "start")
+		}
 		if err := sm.service.Start(); err != nil {
 			return sm.wrapServiceError(err, ErrServiceStart, "start")
 		}
+		if opts.wait {
+			timeout := sm.effectiveTimeout(opts, service.StatusRunning)
+			if err := sm.waitForStatus(sm.commandContext(cmd), service.StatusRunning, timeout); err != nil {
+				return err
+			}
+		}
 
-		sm.localizer.LogSuccess(sm.commands.config.basic.Name, "start")
+		sm.localizer.LogSuccess(sm.Name(), "start")
 		return nil
 	})
 	return cmd
@@ -193,7 +383,14 @@ func (sm *sManager) newStartCmd() *cobra.Command {
 // newStopCmd 创建停止服务命令
 func (sm *sManager) newStopCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("stop", sm.localizer.GetOperation("stop"))
+	opts := &waitOptions{}
+	sm.bindWaitFlags(cmd, opts)
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
+		// run --detach 启动的后台进程没有服务管理器，直接按 PID 文件停止
+		if pid, ok := sm.detachedPID(); ok {
+			return sm.stopDetached(sm.commandContext(cmd), pid, opts)
+		}
+
 		// 检查服务状态
 		status, err := sm.queryServiceStatus()
 		if err != nil {
@@ -201,7 +398,7 @@ func (sm *sManager) newStopCmd() *cobra.Command {
 		}
 
 		if status == service.StatusStopped {
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "alreadyStopped")
+			sm.localizer.LogInfo(sm.Name(), "alreadyStopped")
 			return nil
 		}
 
@@ -209,8 +406,14 @@ func (sm *sManager) newStopCmd() *cobra.Command {
 		if err := sm.service.Stop(); err != nil {
 			return sm.wrapServiceError(err, ErrServiceStop, "stop")
 		}
+		if opts.wait {
+			timeout := sm.effectiveTimeout(opts, service.StatusStopped)
+			if err := sm.waitForStatus(sm.commandContext(cmd), service.StatusStopped, timeout); err != nil {
+				return err
+			}
+		}
 
-		sm.localizer.LogSuccess(sm.commands.config.basic.Name, "stop")
+		sm.localizer.LogSuccess(sm.Name(), "stop")
 		return nil
 	})
 	return cmd
@@ -219,6 +422,8 @@ func (sm *sManager) newStopCmd() *cobra.Command {
 // newRestartCmd 创建重启服务命令
 func (sm *sManager) newRestartCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("restart", sm.localizer.GetOperation("restart"))
+	opts := &waitOptions{}
+	sm.bindWaitFlags(cmd, opts)
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
 		// 检查服务状态
 		status, err := sm.queryServiceStatus()
@@ -227,22 +432,36 @@ func (sm *sManager) newRestartCmd() *cobra.Command {
 		}
 
 		if status == service.StatusUnknown {
-			return ErrServiceNotInstalled(sm.commands.config.basic.Name)
+			return ErrServiceNotInstalled(sm.Name())
 		}
 
-		// 如果服务正在运行，先停止
+		// 如果服务正在运行，先停止并确认已停止，避免新旧进程重叠
+		ctx := sm.commandContext(cmd)
 		if status == service.StatusRunning {
 			if err := sm.service.Stop(); err != nil {
 				return sm.wrapServiceError(err, ErrServiceStop, "restart")
 			}
+			timeout := sm.effectiveTimeout(opts, service.StatusStopped)
+			if err := sm.waitForStatus(ctx, service.StatusStopped, timeout); err != nil {
+				return err
+			}
 		}
 
 		// 启动服务
+		if err := sm.clearCrashLoop(); err != nil {
+			return sm.wrapServiceError(err, ErrServiceRestart, "restart")
+		}
 		if err := sm.service.Start(); err != nil {
 			return sm.wrapServiceError(err, ErrServiceRestart, "restart")
 		}
+		if opts.wait {
+			timeout := sm.effectiveTimeout(opts, service.StatusRunning)
+			if err := sm.waitForStatus(ctx, service.StatusRunning, timeout); err != nil {
+				return err
+			}
+		}
 
-		sm.localizer.LogSuccess(sm.commands.config.basic.Name, "restart")
+		sm.localizer.LogSuccess(sm.Name(), "restart")
 		return nil
 	})
 	return cmd
@@ -251,12 +470,24 @@ func (sm *sManager) newRestartCmd() *cobra.Command {
 // newStatusCmd 创建查看状态命令
 func (sm *sManager) newStatusCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("status", sm.localizer.GetOperation("status"))
+	var history bool
+	cmd.Flags().BoolVar(&history, "history", false, sm.localizer.GetFlag("history"))
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
+		if history {
+			return sm.printHistory(cmd.OutOrStdout())
+		}
+		if pid, ok := sm.detachedPID(); ok {
+			sm.localizer.LogInfo(sm.Name(), sm.runningState(sm.commandContext(cmd)))
+			sm.localizer.LogDetail("pid", pid)
+			sm.logAppliedTuning()
+			return nil
+		}
+
 		// 获取服务状态
 		status, err := sm.queryServiceStatus()
 		if err != nil {
 			if isNotInstalled(err) {
-				sm.localizer.LogInfo(sm.commands.config.basic.Name, "notInstalled")
+				sm.localizer.LogInfo(sm.Name(), "notInstalled")
 				return nil
 			}
 			return err
@@ -265,13 +496,14 @@ func (sm *sManager) newStatusCmd() *cobra.Command {
 		// 显示状态
 		switch status {
 		case service.StatusRunning:
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "running")
+			sm.localizer.LogInfo(sm.Name(), sm.runningState(sm.commandContext(cmd)))
+			sm.logAppliedTuning()
 		case service.StatusStopped:
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "stopped")
+			sm.localizer.LogInfo(sm.Name(), "stopped")
 		case service.StatusUnknown:
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "notInstalled")
+			sm.localizer.LogInfo(sm.Name(), "notInstalled")
 		default:
-			sm.localizer.LogInfo(sm.commands.config.basic.Name, "unknown")
+			sm.localizer.LogInfo(sm.Name(), "unknown")
 		}
 
 		return nil
@@ -282,8 +514,16 @@ func (sm *sManager) newStatusCmd() *cobra.Command {
 // newRunCmd 创建运行服务命令
 func (sm *sManager) newRunCmd() *cobra.Command {
 	cmd := sm.buildBaseCommand("run", sm.localizer.GetOperation("run"))
+	sm.bindDependencyFlag(cmd)
+	sm.bindDetachFlag(cmd)
+	sm.bindWatchFlags(cmd)
 	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
-		return sm.executeRunCommand(cmd, args)
+		err := sm.executeRunCommand(cmd, args)
+		// 以专用退出码退出，避免服务管理器继续重启；此时 run 的延迟清理均已完成
+		if isCrashLoop(err) {
+			exitFunc(CrashLoopExitCode)
+		}
+		return err
 	})
 	return cmd
 }
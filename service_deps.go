@@ -0,0 +1,117 @@
+package zcli
+
+import (
+	"context"
+	"time"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// bindDependencyFlag 为 start/run 注册 --wait-deps 参数，省略取值时使用默认等待上限
+func (sm *sManager) bindDependencyFlag(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.Duration("wait-deps", 0, sm.localizer.GetFlag("waitDeps"))
+	flags.Lookup("wait-deps").NoOptDefVal = defaultWaitTimeout.String()
+}
+
+// dependencyWait 返回 --wait-deps 指定的等待时长，未注册或未设置时为 0
+func dependencyWait(cmd *cobra.Command) time.Duration {
+	if cmd == nil {
+		return 0
+	}
+	wait, err := cmd.Flags().GetDuration("wait-deps")
+	if err != nil {
+		return 0
+	}
+	return wait
+}
+
+// requiredDependencies 返回类型为 DependencyRequire 的依赖名
+func (sm *sManager) requiredDependencies() []string {
+	var names []string
+	for _, dep := range sm.commands.config.service.StructuredDeps {
+		if dep.Type == DependencyRequire && dep.Name != "" {
+			names = append(names, dep.Name)
+		}
+	}
+	return names
+}
+
+// dependencyState 查询依赖在当前后端中的状态，运行中时返回空字符串
+func (sm *sManager) dependencyState(name string) (string, error) {
+	svc, err := newDaemonService(sm.buildRunner(), &service.Config{Name: name})
+	if err != nil {
+		return "unavailable", err
+	}
+	status, err := sm.queryStatusOf(svc, name)
+	switch {
+	case err != nil && isNotInstalled(err):
+		return "not installed", nil
+	case err != nil:
+		return "unavailable", err
+	case status == service.StatusRunning:
+		return "", nil
+	case status == service.StatusStopped:
+		return "stopped", nil
+	default:
+		return "unknown", nil
+	}
+}
+
+// checkDependencies 在 operation（start 或 run）前确认必需依赖均已运行。
+// 未启用 CheckDependencies 且 wait 为 0 时跳过；wait 大于 0 时按退避间隔等待依赖就绪。
+func (sm *sManager) checkDependencies(ctx context.Context, operation string, wait time.Duration) error {
+	deps := sm.requiredDependencies()
+	if len(deps) == 0 || (!sm.commands.config.service.CheckDependencies && wait <= 0) {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deadline := time.Now().Add(wait)
+	interval := sm.sys.statusPollInitial
+	waiting := ""
+	for {
+		blocked, state, cause := "", "", error(nil)
+		for _, dep := range deps {
+			if state, cause = sm.dependencyState(dep); state != "" {
+				blocked = dep
+				break
+			}
+		}
+		if blocked == "" {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			err := ErrServiceDependencyUnavailable(sm.Name(), operation, blocked, state)
+			if cause != nil {
+				err.WithCause(cause)
+			}
+			return err
+		}
+		if blocked != waiting {
+			sm.localizer.LogWarning(sm.localizer.GetMessage("waitingDeps"), blocked, state)
+			waiting = blocked
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return WrapServiceOperationError(ctx.Err(), ErrContextCancelled, "wait", sm.Name())
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > sm.sys.statusPollMax {
+			interval = sm.sys.statusPollMax
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package zcli
+
+import (
+	"errors"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+// useDependencyServices 让 newDaemonService 按名称返回依赖的 fake 服务
+func useDependencyServices(t *testing.T, deps map[string]service.Service) {
+	t.Helper()
+	prev := newDaemonService
+	newDaemonService = func(_ service.Interface, config *service.Config) (service.Service, error) {
+		if svc, ok := deps[config.Name]; ok {
+			return svc, nil
+		}
+		return &fakeDaemonService{statusErr: service.ErrNotInstalled}, nil
+	}
+	t.Cleanup(func() { newDaemonService = prev })
+}
+
+func dependencyErrorContext(t *testing.T, err error) map[string]any {
+	t.Helper()
+	var se *ServiceError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected ServiceError, got %T", err)
+	}
+	return se.GetContext()
+}
+
+func TestStartCommand_RefusesWhenRequiredDependencyDown(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm := newTestServiceManager(t, stub)
+	sm.commands.config.service.CheckDependencies = true
+	sm.commands.config.service.StructuredDeps = []Dependency{
+		{Name: "cache", Type: DependencyWant},
+		{Name: "db", Type: DependencyRequire},
+	}
+	useDependencyServices(t, map[string]service.Service{
+		"db": &fakeDaemonService{status: service.StatusStopped},
+	})
+
+	cmd := sm.newStartCmd()
+	err := cmd.RunE(cmd, nil)
+	if !IsErrorCode(err, ErrDependencyUnavailable) {
+		t.Fatalf("expected ErrDependencyUnavailable, got %v", err)
+	}
+	if dep := dependencyErrorContext(t, err)["dependency"]; dep != "db" {
+		t.Fatalf("expected db to be reported as the blocking dependency, got %v", dep)
+	}
+	if op := err.(*ServiceError).Operation; op != "start" {
+		t.Fatalf("expected the start operation, got %q", op)
+	}
+	if stub.startCalled != 0 {
+		t.Fatal("service must not be started while a required dependency is down")
+	}
+}
+
+func TestRunCommand_ReportsRunWhenRequiredDependencyDown(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.sys.interactive = func() bool { return true }
+	sm.commands.config.service.CheckDependencies = true
+	sm.commands.config.service.StructuredDeps = []Dependency{{Name: "db", Type: DependencyRequire}}
+	useDependencyServices(t, nil)
+
+	cmd := sm.newRunCmd()
+	err := cmd.RunE(cmd, nil)
+	if !IsErrorCode(err, ErrDependencyUnavailable) {
+		t.Fatalf("expected ErrDependencyUnavailable, got %v", err)
+	}
+	if op := err.(*ServiceError).Operation; op != "run" {
+		t.Fatalf("expected the run operation, got %q", op)
+	}
+}
+
+func TestStartCommand_SkipsDependencyCheckByDefault(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm := newTestServiceManager(t, stub)
+	sm.commands.config.service.StructuredDeps = []Dependency{{Name: "db", Type: DependencyRequire}}
+	useDependencyServices(t, nil)
+
+	cmd := sm.newStartCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("start without dependency check: %v", err)
+	}
+	if stub.startCalled != 1 {
+		t.Fatalf("expected start to be called once, got %d", stub.startCalled)
+	}
+}
+
+func TestStartCommand_WaitDepsPollsUntilDependencyRuns(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm := newTestServiceManager(t, stub)
+	shortenStatusPolling(sm)
+	sm.commands.config.service.StructuredDeps = []Dependency{{Name: "db", Type: DependencyRequire}}
+	db := &sequenceDaemonService{statuses: []service.Status{
+		service.StatusStopped,
+		service.StatusStopped,
+		service.StatusRunning,
+	}}
+	useDependencyServices(t, map[string]service.Service{"db": db})
+
+	cmd := sm.newStartCmd()
+	if err := cmd.ParseFlags([]string{"--wait-deps=1s"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("start --wait-deps: %v", err)
+	}
+	if stub.startCalled != 1 || len(db.events) != 3 {
+		t.Fatalf("expected three dependency polls before start, got %v (start=%d)", db.events, stub.startCalled)
+	}
+}
+
+func TestStartCommand_WaitDepsTimesOut(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm := newTestServiceManager(t, stub)
+	shortenStatusPolling(sm)
+	sm.commands.config.service.StructuredDeps = []Dependency{{Name: "db", Type: DependencyRequire}}
+	useDependencyServices(t, nil)
+
+	cmd := sm.newStartCmd()
+	if err := cmd.ParseFlags([]string{"--wait-deps=20ms"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	err := cmd.RunE(cmd, nil)
+	if !IsErrorCode(err, ErrDependencyUnavailable) {
+		t.Fatalf("expected ErrDependencyUnavailable, got %v", err)
+	}
+	if state := dependencyErrorContext(t, err)["state"]; state != "not installed" {
+		t.Fatalf("unexpected dependency state %v", state)
+	}
+}
@@ -0,0 +1,314 @@
+package zcli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// detachEnvVar run --detach 拉起的子进程通过该变量获知就绪通知管道的文件描述符
+const detachEnvVar = "ZCLI_DETACH_FD"
+
+var (
+	// errDetachUnsupported 当前平台不支持后台分离运行
+	errDetachUnsupported = errors.New("detach not supported on " + runtime.GOOS)
+	// errDetachExited 子进程在就绪前退出
+	errDetachExited = errors.New("process exited before becoming ready")
+)
+
+// detachPipe 返回子进程的就绪通知管道，首次调用时读取并清除环境变量，
+// 避免业务代码再拉起的子进程误认为自己是后台子进程
+var detachPipe = sync.OnceValue(func() *os.File {
+	value := os.Getenv(detachEnvVar)
+	if value == "" {
+		return nil
+	}
+	_ = os.Unsetenv(detachEnvVar)
+	fd, err := strconv.Atoi(value)
+	if err != nil || fd < 3 {
+		return nil
+	}
+	return os.NewFile(uintptr(fd), "zcli-detach")
+})
+
+// detachNotifyOnce 保证只向父进程报告一次
+var detachNotifyOnce sync.Once
+
+// detachedChild 报告当前进程是否由 run --detach 拉起
+func detachedChild() bool {
+	return detachPipe() != nil
+}
+
+// notifyDetachParent 向 run --detach 的父进程报告就绪（err 为 nil）或启动失败
+func notifyDetachParent(err error) {
+	pipe := detachPipe()
+	if pipe == nil {
+		return
+	}
+	detachNotifyOnce.Do(func() {
+		msg := "ready\n"
+		if err != nil {
+			msg = "error " + strings.ReplaceAll(err.Error(), "\n", " ") + "\n"
+		}
+		_, _ = pipe.WriteString(msg)
+		_ = pipe.Close()
+	})
+}
+
+// bindDetachFlag 为 run 命令注册 --detach 参数
+func (sm *sManager) bindDetachFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("detach", "d", sm.commands.config.runtime.Detach, sm.localizer.GetFlag("detach"))
+}
+
+// detachRequested 返回本次 run 是否以后台方式启动：--detach 优先，其次是 Builder 默认值。
+// 已是后台子进程、由服务管理器拉起或处于容器 init 模式时不再分离。
+func (sm *sManager) detachRequested(cmd *cobra.Command) bool {
+	if detachedChild() || !sm.sys.interactive() || sm.initMode() {
+		return false
+	}
+	if cmd != nil {
+		if flag := cmd.Flags().Lookup("detach"); flag != nil {
+			return flag.Value.String() == "true"
+		}
+	}
+	return sm.commands.config.runtime.Detach
+}
+
+// detachPIDFile 返回后台运行的 PID 文件：RuntimeDir 下的 <name>.pid，
+// 未配置时使用仅当前用户可访问的运行目录，不使用所有人可写的临时目录
+func (sm *sManager) detachPIDFile() (string, error) {
+	dir := sm.currentLayout().RuntimeDir.Path
+	if dir == "" {
+		var err error
+		if dir, err = privateRuntimeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, sm.Name()+".pid"), nil
+}
+
+// detachedPID 返回 run --detach 拉起且仍在运行的后台进程号
+func (sm *sManager) detachedPID() (int, bool) {
+	path, err := sm.detachPIDFile()
+	if err != nil {
+		return 0, false
+	}
+	return livePID(path)
+}
+
+// detachLogFile 返回后台进程标准输出的去向：配置了轮转日志文件时为同目录下的 <name>.out，
+// 其次是 LogDir 下的 <name>.log，最后使用当前用户专用的运行目录。
+// 子进程的标准输出不能指向轮转文件本身，否则轮转后仍写入被改名、压缩或删除的旧文件
+func (sm *sManager) detachLogFile() (string, error) {
+	if file, _ := configuredLogSources(sm.commands.config.runtime.LogSinks); file != nil {
+		return filepath.Join(filepath.Dir(file.Path()), sm.Name()+".out"), nil
+	}
+	dir := sm.currentLayout().LogDir.Path
+	if dir == "" {
+		var err error
+		if dir, err = privateRuntimeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, sm.Name()+".log"), nil
+}
+
+// runDetached 以 setsid 重新执行自身，标准输入输出重定向到日志文件，
+// 等待子进程报告就绪或失败后返回
+func (sm *sManager) runDetached(args []string) error {
+	pidFile, err := sm.detachPIDFile()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrPathInvalid, "run")
+	}
+	if pid, ok := livePID(pidFile); ok {
+		sm.localizer.LogInfo(sm.Name(), "alreadyRunning")
+		sm.localizer.LogDetail("pid", pid)
+		return nil
+	}
+
+	attr, err := detachSysProcAttr()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrServiceStart, "run")
+	}
+	exe, err := executablePath()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrExecutableInvalid, "run")
+	}
+
+	logPath, err := sm.detachLogFile()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrPathInvalid, "run")
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		return sm.wrapServiceError(err, ErrPathInvalid, "run")
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return sm.wrapServiceError(err, ErrPathInvalid, "run")
+	}
+	defer func() { _ = logFile.Close() }()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrServiceStart, "run")
+	}
+	defer func() { _ = reader.Close() }()
+
+	// 子进程的启动方式与服务管理器一致：可执行文件加运行参数
+	child := exec.Command(exe, sm.runArguments(args)...)
+	child.Env = append(os.Environ(), detachEnvVar+"=3")
+	if sm.instance != "" {
+		child.Env = append(child.Env, instanceEnvVar+"="+sm.instance)
+	}
+	child.Dir = sm.config.WorkingDirectory
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.ExtraFiles = []*os.File{writer}
+	child.SysProcAttr = attr
+	startErr := child.Start()
+	_ = writer.Close()
+	if startErr != nil {
+		return sm.wrapServiceError(startErr, ErrServiceStart, "run")
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		// 命令返回后当前进程随即退出；宿主进程仍存活时在此回收子进程
+		_ = child.Wait()
+		close(exited)
+	}()
+
+	report := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(reader).ReadString('\n')
+		report <- strings.TrimSpace(line)
+	}()
+
+	timeout := sm.effectiveTimeout(nil, service.StatusRunning)
+	select {
+	case line := <-report:
+		if line == "ready" {
+			sm.localizer.LogSuccess(sm.Name(), "run")
+			sm.localizer.LogDetail("pid", child.Process.Pid)
+			sm.localizer.LogDetail("pidFile", pidFile)
+			sm.localizer.LogDetail("logFile", logPath)
+			return nil
+		}
+		<-exited
+		reason, ok := strings.CutPrefix(line, "error ")
+		if !ok {
+			reason = errDetachExited.Error()
+			if child.ProcessState != nil {
+				reason += ": " + child.ProcessState.String()
+			}
+		}
+		return NewError(ErrServiceStart).
+			Service(sm.Name()).
+			Operation("run").
+			Message(sm.localizer.FormatError("detachFailed", reason, logPath)).
+			Context("log", logPath).
+			Build()
+	case <-time.After(timeout):
+		_ = child.Process.Kill()
+		<-exited
+		return ErrServiceStartTimeout(sm.Name(), timeout).WithContext("log", logPath)
+	}
+}
+
+// writePIDFile 以 O_EXCL 创建 PID 文件并在进程存活期间持有其文件锁，返回退出时删除该文件的函数。
+// 已存在的文件属于仍在运行的进程时失败，否则视为上次异常退出的残留并替换
+func writePIDFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	create := func() (*os.File, error) {
+		return os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	}
+	file, err := create()
+	if errors.Is(err, fs.ErrExist) {
+		if pid, ok := livePID(path); ok {
+			return nil, fmt.Errorf("%s is held by running process %d", path, pid)
+		}
+		_ = os.Remove(path)
+		file, err = create()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+	if _, err := file.WriteString(strconv.Itoa(os.Getpid()) + "\n"); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return func() {
+		_ = os.Remove(path)
+		_ = file.Close()
+	}, nil
+}
+
+// readPIDFile 读取 PID 文件
+func readPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// livePID 返回 PID 文件中仍在运行的进程号。进程号可能已被系统复用，
+// 因此还要求 PID 文件锁仍被持有，且进程运行的是当前可执行文件
+func livePID(path string) (int, bool) {
+	pid, err := readPIDFile(path)
+	if err != nil || pid <= 0 || !processAlive(pid) {
+		return 0, false
+	}
+	if !pidFileLocked(path) || !runsExecutable(pid) {
+		return 0, false
+	}
+	return pid, true
+}
+
+// stopDetached 向后台进程发送终止信号，并以指数退避等待其退出
+func (sm *sManager) stopDetached(ctx context.Context, pid int, opts *waitOptions) error {
+	if err := terminateProcess(pid); err != nil {
+		return sm.wrapServiceError(err, ErrServiceStop, "stop")
+	}
+	timeout := sm.effectiveTimeout(opts, service.StatusStopped)
+	deadline := time.Now().Add(timeout)
+	interval := sm.sys.statusPollInitial
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return ErrServiceStopTimeout(sm.Name(), timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return WrapServiceOperationError(ctx.Err(), ErrContextCancelled, "stop", sm.Name())
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, sm.sys.statusPollMax)
+	}
+	// 进程被强制结束时不会自行清理 PID 文件
+	if path, err := sm.detachPIDFile(); err == nil {
+		_ = os.Remove(path)
+	}
+	sm.localizer.LogSuccess(sm.Name(), "stop")
+	return nil
+}
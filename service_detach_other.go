@@ -0,0 +1,43 @@
+//go:build !linux && !darwin
+
+package zcli
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachSysProcAttr 当前平台不支持后台分离运行
+func detachSysProcAttr() (*syscall.SysProcAttr, error) {
+	return nil, errDetachUnsupported
+}
+
+// processAlive 当前平台不会产生后台进程的 PID 文件
+func processAlive(int) bool {
+	return false
+}
+
+// terminateProcess 当前平台不支持后台分离运行
+func terminateProcess(int) error {
+	return errDetachUnsupported
+}
+
+// lockFile 当前平台不支持后台分离运行
+func lockFile(*os.File) error {
+	return errDetachUnsupported
+}
+
+// pidFileLocked 当前平台不会产生后台进程的 PID 文件
+func pidFileLocked(string) bool {
+	return false
+}
+
+// runsExecutable 当前平台不会产生后台进程的 PID 文件
+func runsExecutable(int) bool {
+	return false
+}
+
+// privateRuntimeDir 当前平台不支持后台分离运行
+func privateRuntimeDir() (string, error) {
+	return "", errDetachUnsupported
+}
@@ -0,0 +1,94 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// detachSysProcAttr 子进程在新会话中运行，脱离控制终端
+func detachSysProcAttr() (*syscall.SysProcAttr, error) {
+	return &syscall.SysProcAttr{Setsid: true}, nil
+}
+
+// processAlive 以信号 0 探测进程是否存在
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminateProcess 发送 SIGTERM，由服务的信号处理完成优雅退出
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// lockFile 对 PID 文件加独占锁，进程退出时由内核释放
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// pidFileLocked 报告 PID 文件的锁是否仍由写入它的进程持有
+func pidFileLocked(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = file.Close() }()
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if err == nil {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		return false
+	}
+	return errors.Is(err, syscall.EWOULDBLOCK)
+}
+
+// runsExecutable 报告进程是否运行当前可执行文件；无法读取 /proc 的平台只依赖文件锁
+func runsExecutable(pid int) bool {
+	if runtime.GOOS != "linux" {
+		return true
+	}
+	target, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "exe"))
+	if errors.Is(err, fs.ErrPermission) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	self, err := executablePath()
+	if err != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+	// 可执行文件在运行期间被替换时内核会追加 " (deleted)"
+	return strings.TrimSuffix(target, " (deleted)") == self
+}
+
+// privateRuntimeDir 返回仅当前用户可访问的运行目录：$XDG_RUNTIME_DIR/zcli 或临时目录下的 zcli-<uid>
+func privateRuntimeDir() (string, error) {
+	dir := filepath.Join(os.TempDir(), "zcli-"+strconv.Itoa(os.Getuid()))
+	if base := os.Getenv("XDG_RUNTIME_DIR"); base != "" {
+		dir = filepath.Join(base, "zcli")
+	}
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return "", err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !info.IsDir() || info.Mode().Perm() != 0o700 || !ok || int(stat.Uid) != os.Getuid() {
+		return "", fmt.Errorf("%s must be a directory owned by the current user with mode 0700", dir)
+	}
+	return dir, nil
+}
@@ -0,0 +1,217 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// detachTestDirEnv 把测试目录传给后台子进程
+const detachTestDirEnv = "ZCLI_TEST_DETACH_DIR"
+
+// newDetachTestManager 构造 run --detach 父子进程共用的服务配置，子进程为当前测试二进制
+func newDetachTestManager(t *testing.T, dir string, failBeforeReady bool) *sManager {
+	t.Helper()
+	config := NewConfig()
+	config.basic.Name = "detach-demo"
+	config.service.Arguments = []string{"-test.run=^TestDetachHelperProcess$"}
+	config.service.Layout.RuntimeDir.Path = dir
+	config.service.Layout.LogDir.Path = dir
+	config.runtime.StartTimeout = 10 * time.Second
+	config.runtime.Run = func(ctx context.Context) error {
+		_, _ = io.WriteString(os.Stdout, "helper running\n")
+		<-ctx.Done()
+		return nil
+	}
+	if failBeforeReady {
+		config.runtime.WaitFor = []WaitCondition{WaitForFile(filepath.Join(dir, "never"), 10*time.Millisecond)}
+	}
+	cli := &Cli{config: config, colors: newColors(), lang: GetLanguageManager().GetPrimary()}
+	return newLogTestManager(t, cli)
+}
+
+// TestDetachHelperProcess 仅在 run --detach 拉起的子进程中执行
+func TestDetachHelperProcess(t *testing.T) {
+	dir := os.Getenv(detachTestDirEnv)
+	if dir == "" || !detachedChild() {
+		t.Skip("helper process for run --detach tests")
+	}
+	sm := newDetachTestManager(t, dir, os.Getenv("ZCLI_TEST_DETACH_FAIL") != "")
+	_ = sm.executeRunCommand(nil, nil)
+}
+
+func TestRunDetach_StatusAndStopUsePIDFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(detachTestDirEnv, dir)
+	sm := newDetachTestManager(t, dir, false)
+	var out bytes.Buffer
+	sm.localizer.ConfigureOutput(&out, &out, false, false)
+
+	if err := sm.runDetached(nil); err != nil {
+		t.Fatalf("runDetached: %v", err)
+	}
+	pidFile := filepath.Join(dir, "detach-demo.pid")
+	pid, ok := livePID(pidFile)
+	if !ok || pid == os.Getpid() {
+		t.Fatalf("expected a live child pid in %s, got %d (%v)", pidFile, pid, ok)
+	}
+	if !strings.Contains(out.String(), pidFile) {
+		t.Fatalf("run --detach should report the pid file:\n%s", out.String())
+	}
+
+	out.Reset()
+	status := sm.newStatusCmd()
+	if err := status.RunE(status, nil); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out.String(), sm.localizer.GetStatus("running")) {
+		t.Fatalf("status should report the detached process as running:\n%s", out.String())
+	}
+
+	// 就绪通知先于用户 Run，等子进程写出输出后再停止
+	logPath := filepath.Join(dir, "detach-demo.log")
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if logs, _ := os.ReadFile(logPath); strings.Contains(string(logs), "helper running") {
+			break
+		}
+	}
+
+	stop := sm.newStopCmd()
+	if err := stop.RunE(stop, nil); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if processAlive(pid) {
+		t.Fatalf("process %d should have exited", pid)
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Fatalf("pid file should be removed, stat err=%v", err)
+	}
+	logs, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logs), "helper running") {
+		t.Fatalf("child stdout should be redirected to the log file:\n%s", logs)
+	}
+}
+
+func TestRunDetach_ReportsFailureBeforeReady(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(detachTestDirEnv, dir)
+	t.Setenv("ZCLI_TEST_DETACH_FAIL", "1")
+	sm := newDetachTestManager(t, dir, true)
+
+	err := sm.runDetached(nil)
+	if !IsErrorCode(err, ErrServiceStart) || !strings.Contains(err.Error(), "detach-demo.log") {
+		t.Fatalf("expected a start error pointing at the log file, got %v", err)
+	}
+	if _, ok := livePID(filepath.Join(dir, "detach-demo.pid")); ok {
+		t.Fatal("failed child must not leave a live pid file")
+	}
+}
+
+func TestDetachLogFile_KeepsRawOutputOutOfRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	sm := newDetachTestManager(t, dir, false)
+	file, err := NewRotatingFile(filepath.Join(dir, "logs", "app.log"), RotateOptions{})
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	t.Cleanup(func() { _ = file.Close() })
+	sm.commands.config.runtime.LogSinks = []LogSink{file}
+
+	got, err := sm.detachLogFile()
+	if want := filepath.Join(dir, "logs", "detach-demo.out"); err != nil || got != want {
+		t.Fatalf("detachLogFile = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestDetachedPID_IgnoresUnverifiedProcesses(t *testing.T) {
+	dir := t.TempDir()
+	sm := newDetachTestManager(t, dir, false)
+	pidFile := filepath.Join(dir, "detach-demo.pid")
+
+	// 进程存活但未持有 PID 文件锁，也不是当前可执行文件，不能被当作后台服务
+	other := exec.Command("sleep", "30")
+	if err := other.Start(); err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = other.Process.Kill()
+		_ = other.Wait()
+	})
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(other.Process.Pid)+"\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if pid, ok := sm.detachedPID(); ok {
+		t.Fatalf("unlocked pid file must be ignored, got %d", pid)
+	}
+	sm.commands.config.runtime.PauseSignal = syscall.SIGSTOP
+	if sent, err := sm.signalPause(controlPause); sent || err != nil {
+		t.Fatalf("pause must not signal an unverified process, sent=%v err=%v", sent, err)
+	}
+
+	// 残留文件被替换，持有锁期间再次写入失败
+	release, err := writePIDFile(pidFile)
+	if err != nil {
+		t.Fatalf("writePIDFile should replace a stale file: %v", err)
+	}
+	if pid, ok := sm.detachedPID(); !ok || pid != os.Getpid() {
+		t.Fatalf("locked pid file should be verified, got %d (%v)", pid, ok)
+	}
+	if _, err := writePIDFile(pidFile); err == nil {
+		t.Fatal("writePIDFile must fail while another process holds the file")
+	}
+	release()
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Fatalf("release should remove the pid file, stat err=%v", err)
+	}
+	if !processAlive(other.Process.Pid) {
+		t.Fatal("unrelated process must not be signalled")
+	}
+}
+
+func TestPrivateRuntimeDir_IsOwnerOnly(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	dir, err := privateRuntimeDir()
+	if err != nil {
+		t.Fatalf("privateRuntimeDir: %v", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected a 0700 dir, got %v (%v)", info, err)
+	}
+	if err := os.Chmod(dir, 0o777); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	if _, err := privateRuntimeDir(); err == nil {
+		t.Fatal("a group or world accessible dir must be rejected")
+	}
+}
+
+func TestDetachRequested_RespectsFlagAndDefault(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.commands.config.runtime.Detach = true
+	cmd := sm.newRunCmd()
+	if !sm.detachRequested(cmd) {
+		t.Fatal("builder default should enable detach")
+	}
+	if err := cmd.ParseFlags([]string{"--detach=false"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if sm.detachRequested(cmd) {
+		t.Fatal("--detach=false should override the builder default")
+	}
+
+	sm.sys.interactive = func() bool { return false }
+	if sm.detachRequested(nil) {
+		t.Fatal("runs started by a service manager must not detach")
+	}
+}
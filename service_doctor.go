@@ -0,0 +1,292 @@
+package zcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// doctorProbeTimeout doctor 对单个前置条件的检查上限
+const doctorProbeTimeout = 3 * time.Second
+
+// doctorStatus 检查结果
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "pass"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorCheck 单个检查项的结果，Check 为标签键，JSON 输出保持稳定
+type doctorCheck struct {
+	Check  string       `json:"check"`
+	Status doctorStatus `json:"status"`
+	Target string       `json:"target,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Hint   string       `json:"hint,omitempty"`
+}
+
+// doctorReport doctor 的完整结果
+type doctorReport struct {
+	Service string        `json:"service"`
+	OK      bool          `json:"ok"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+// newDoctorCmd 创建运行环境检查命令
+func (sm *sManager) newDoctorCmd() *cobra.Command {
+	cmd := sm.buildBaseCommand("doctor", sm.localizer.GetOperation("doctor"))
+	var output string
+	cmd.Flags().StringVar(&output, "output", "table", sm.localizer.GetFlag("outFormat"))
+	// 配置未通过检查时其他命令不可用，doctor 仍需运行以诊断问题
+	cmd.RunE = sm.wrapDiagnosticRunE(func(cmd *cobra.Command, args []string) error {
+		if output != "table" && output != "json" {
+			err := fmt.Errorf("%s", sm.localizer.FormatError("invalidOutput", output, "table, json"))
+			return sm.wrapServiceError(err, ErrConfigInvalid, "doctor")
+		}
+		report := sm.runDoctor(sm.commandContext(cmd))
+		if output == "json" {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return sm.wrapServiceError(err, ErrRuntime, "doctor")
+			}
+		} else if err := sm.printDoctorReport(cmd.OutOrStdout(), report); err != nil {
+			return sm.wrapServiceError(err, ErrRuntime, "doctor")
+		}
+
+		if failed := report.failures(); failed > 0 {
+			return NewError(ErrConfigValidation).
+				Service(sm.Name()).
+				Operation("doctor").
+				Message(sm.localizer.FormatError("doctorFailed", failed)).
+				Context("failed", failed).
+				Build()
+		}
+		return nil
+	})
+	return cmd
+}
+
+// failures 返回失败的检查项数量
+func (r doctorReport) failures() int {
+	failed := 0
+	for _, check := range r.Checks {
+		if check.Status == doctorFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// runDoctor 汇总创建服务配置时的路径检查，并补充账号、环境变量文件、后端、依赖与前置条件检查
+func (sm *sManager) runDoctor(ctx context.Context) doctorReport {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d := &doctor{sm: sm}
+	svcCfg, err := sm.baseServiceConfig()
+	if err != nil {
+		d.add("template", doctorFail, sm.Name(), err.Error(), "template")
+	}
+
+	d.checkBackend()
+	d.checkEnvFiles(svcCfg.EnvFiles)
+	if config, err := sm.translateServiceConfig(svcCfg); err != nil {
+		d.add("executable", doctorFail, "", err.Error(), "executable")
+	} else {
+		d.checkPaths(config)
+	}
+	d.checkUser(svcCfg.Username)
+	d.checkDependencies()
+	d.checkWaitFor(ctx)
+
+	return doctorReport{Service: sm.Name(), OK: d.report.failures() == 0, Checks: d.report.Checks}
+}
+
+// doctor 收集检查结果
+type doctor struct {
+	sm     *sManager
+	report doctorReport
+}
+
+func (d *doctor) add(check string, status doctorStatus, target, detail, hint string) {
+	result := doctorCheck{Check: check, Status: status, Target: target, Detail: detail}
+	if status != doctorPass && hint != "" {
+		result.Hint = d.sm.localizer.GetHint(hint)
+	}
+	d.report.Checks = append(d.report.Checks, result)
+}
+
+// checkBackend 确认当前系统有可用的服务管理器，容器 init 模式下不需要
+func (d *doctor) checkBackend() {
+	if d.sm.initMode() {
+		d.add("backend", doctorPass, "init", "", "")
+		return
+	}
+	system := d.sm.sys.chosenSystem()
+	if system == nil {
+		d.add("backend", doctorFail, "", service.Platform(), "backend")
+		return
+	}
+	d.add("backend", doctorPass, system.String(), "", "")
+}
+
+// checkEnvFiles 逐个解析环境变量文件，可选文件缺失视为通过
+func (d *doctor) checkEnvFiles(paths []string) {
+	for _, path := range paths {
+		target := strings.TrimPrefix(path, "-")
+		if _, err := loadEnvFiles([]string{path}, d.sm.localizer); err != nil {
+			d.add("envFile", doctorFail, target, err.Error(), "envFile")
+			continue
+		}
+		d.add("envFile", doctorPass, target, "", "")
+	}
+}
+
+// checkPaths 复用 buildServiceConfig 的权限检查，并确认工作目录可写
+func (d *doctor) checkPaths(config *service.Config) {
+	if serviceManagerGOOS == "windows" {
+		return
+	}
+	if err := checkPermissions(config.Executable, 0o755, d.sm.localizer); err != nil {
+		d.add("executable", doctorFail, config.Executable, err.Error(), "executable")
+	} else {
+		d.add("executable", doctorPass, config.Executable, "", "")
+	}
+
+	if dir := config.WorkingDirectory; dir != "" {
+		if err := checkPermissions(dir, os.ModeDir|0o755, d.sm.localizer); err != nil {
+			d.add("workDir", doctorFail, dir, err.Error(), "workDir")
+		} else if err := d.checkWritable(dir, config.UserName); err != nil {
+			d.add("workDir", doctorWarn, dir, err.Error(), "workDirWritable")
+		} else {
+			d.add("workDir", doctorPass, dir, "", "")
+		}
+	}
+
+	if dir := config.ChRoot; dir != "" {
+		if err := checkPermissions(dir, os.ModeDir|0o755, d.sm.localizer); err != nil {
+			d.add("chroot", doctorFail, dir, err.Error(), "chroot")
+		} else {
+			d.add("chroot", doctorPass, dir, "", "")
+		}
+	}
+}
+
+// checkWritable 确认运行用户可写目录。配置了运行用户时按目录属主、属组与权限位判断，
+// 否则以当前进程身份创建临时文件探测；用户不存在由 checkUser 报告
+func (d *doctor) checkWritable(dir, username string) error {
+	if username == "" {
+		return probeWritable(dir)
+	}
+	id, err := d.sm.lookupIdentity(username)
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return probeWritable(dir)
+	}
+	if id.uid == 0 {
+		return nil
+	}
+
+	// 在目录中创建文件需要写与执行权限
+	perm := info.Mode().Perm()
+	var need os.FileMode = 0o003
+	switch {
+	case uid == id.uid:
+		need = 0o300
+	case gid == id.gid || slices.Contains(id.groups, gid):
+		need = 0o030
+	}
+	if perm&need != need {
+		return fmt.Errorf("not writable by user %s (owner %d:%d, mode %s)", id.user, uid, gid, perm)
+	}
+	return nil
+}
+
+// probeWritable 通过创建临时文件确认目录可写
+func probeWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".zcli-doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
+
+// checkUser 确认配置的运行用户存在
+func (d *doctor) checkUser(username string) {
+	if username == "" {
+		return
+	}
+	if _, err := d.sm.sys.lookupUser(username); err != nil {
+		d.add("user", doctorFail, username, err.Error(), "user")
+		return
+	}
+	d.add("user", doctorPass, username, "", "")
+}
+
+// checkDependencies 确认必需依赖已安装，未运行时给出警告
+func (d *doctor) checkDependencies() {
+	for _, name := range d.sm.requiredDependencies() {
+		state, err := d.sm.dependencyState(name)
+		switch {
+		case err != nil:
+			d.add("dependencies", doctorFail, name, err.Error(), "dependency")
+		case state == "not installed":
+			d.add("dependencies", doctorFail, name, state, "dependency")
+		case state != "":
+			d.add("dependencies", doctorWarn, name, state, "dependencyStopped")
+		default:
+			d.add("dependencies", doctorPass, name, "", "")
+		}
+	}
+}
+
+// checkWaitFor 对每个前置条件执行一次检查，不满足时仅警告，启动时仍会等待
+func (d *doctor) checkWaitFor(ctx context.Context) {
+	for _, cond := range d.sm.commands.config.runtime.WaitFor {
+		probeCtx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+		err := cond.Check(probeCtx)
+		cancel()
+		if err != nil {
+			d.add("waitFor", doctorWarn, cond.Name, err.Error(), "waitFor")
+			continue
+		}
+		d.add("waitFor", doctorPass, cond.Name, "", "")
+	}
+}
+
+// printDoctorReport 以表格输出检查结果，未通过的项在下一行给出修复建议
+func (sm *sManager) printDoctorReport(out io.Writer, report doctorReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		sm.localizer.GetLabel("result"), sm.localizer.GetLabel("check"),
+		sm.localizer.GetLabel("name"), sm.localizer.GetLabel("detail"))
+	for _, check := range report.Checks {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			strings.ToUpper(string(check.Status)), sm.localizer.GetLabel(check.Check), check.Target, check.Detail)
+		if check.Hint != "" {
+			_, _ = fmt.Fprintf(w, "\t\t↳ %s\n", check.Hint)
+		}
+	}
+	return w.Flush()
+}
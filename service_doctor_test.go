@@ -0,0 +1,244 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+// fakeSystem 固定返回的服务管理器后端
+type fakeSystem struct{ name string }
+
+func (s fakeSystem) String() string    { return s.name }
+func (s fakeSystem) Detect() bool      { return true }
+func (s fakeSystem) Interactive() bool { return true }
+func (s fakeSystem) New(service.Interface, *service.Config) (service.Service, error) {
+	return &fakeDaemonService{}, nil
+}
+
+func useServiceSystem(sm *sManager, system service.System) {
+	sm.sys.chosenSystem = func() service.System { return system }
+}
+
+func runDoctorCmd(t *testing.T, sm *sManager, args ...string) (string, error) {
+	t.Helper()
+	cmd := sm.newDoctorCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	err := cmd.RunE(cmd, nil)
+	return out.String(), err
+}
+
+func newDoctorTestManager(t *testing.T) *sManager {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	svc := sm.commands.config.service
+	svc.Executable = exe
+	svc.WorkDir = t.TempDir()
+	useServiceSystem(sm, fakeSystem{name: "linux-systemd"})
+	useDependencyServices(t, nil)
+	return sm
+}
+
+func TestDoctor_AllChecksPass(t *testing.T) {
+	sm := newDoctorTestManager(t)
+	sm.commands.config.runtime.WaitFor = []WaitCondition{WaitForFile(sm.commands.config.service.WorkDir, 0)}
+
+	out, err := runDoctorCmd(t, sm)
+	if err != nil {
+		t.Fatalf("doctor: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "FAIL") || strings.Contains(out, "WARN") || !strings.Contains(out, "linux-systemd") {
+		t.Fatalf("unexpected report:\n%s", out)
+	}
+}
+
+func TestDoctor_ReportsProblemsWithHints(t *testing.T) {
+	sm := newDoctorTestManager(t)
+	svc := sm.commands.config.service
+	dir := t.TempDir()
+	svc.EnvFiles = []string{writeEnvFile(t, dir, "bad.env", "A=1\nnot a pair\n"), "-" + filepath.Join(dir, "missing.env")}
+	svc.WorkDir = filepath.Join(dir, "missing")
+	svc.Username = "zcli-doctor-no-such-user"
+	svc.StructuredDeps = []Dependency{{Name: "db", Type: DependencyRequire}, {Name: "cache", Type: DependencyRequire}}
+	useDependencyServices(t, map[string]service.Service{"cache": &fakeDaemonService{status: service.StatusStopped}})
+	sm.commands.config.runtime.WaitFor = []WaitCondition{WaitForFunc("broker", func(context.Context) error {
+		return errors.New("connection refused")
+	}, 0)}
+	sm.sys.lookupUser = func(name string) (*user.User, error) { return nil, user.UnknownUserError(name) }
+
+	out, err := runDoctorCmd(t, sm, "--output", "json")
+	if !IsErrorCode(err, ErrConfigValidation) {
+		t.Fatalf("expected a validation error for failing checks, got %v", err)
+	}
+	var report doctorReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out)
+	}
+	if report.OK {
+		t.Fatal("report should not be ok")
+	}
+
+	got := map[string]doctorStatus{}
+	for _, check := range report.Checks {
+		got[check.Check+":"+check.Target] = check.Status
+		if check.Status != doctorPass && (check.Hint == "" || strings.Contains(check.Hint, "Missing")) {
+			t.Errorf("%s should carry a localized hint, got %q", check.Check, check.Hint)
+		}
+	}
+	want := map[string]doctorStatus{
+		"envFile:" + filepath.Join(dir, "bad.env"):     doctorFail,
+		"envFile:" + filepath.Join(dir, "missing.env"): doctorPass,
+		"workDir:" + svc.WorkDir:                       doctorFail,
+		"user:" + svc.Username:                         doctorFail,
+		"dependencies:db":                              doctorFail,
+		"dependencies:cache":                           doctorWarn,
+		"waitFor:broker":                               doctorWarn,
+	}
+	for key, status := range want {
+		if got[key] != status {
+			t.Errorf("%s = %q, want %q", key, got[key], status)
+		}
+	}
+	if report.failures() != 4 {
+		t.Errorf("expected 4 failures, got %d", report.failures())
+	}
+}
+
+func TestDoctor_WorkDirCheckedAgainstServiceUser(t *testing.T) {
+	sm := newDoctorTestManager(t)
+	fakePrivilegeDrop(t, sm, nil)
+	svc := sm.commands.config.service
+	svc.Username = "svc"
+	if err := os.Chmod(svc.WorkDir, 0o755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	workDirStatus := func() doctorStatus {
+		t.Helper()
+		out, _ := runDoctorCmd(t, sm, "--output", "json")
+		var report doctorReport
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("invalid json: %v\n%s", err, out)
+		}
+		for _, check := range report.Checks {
+			if check.Check == "workDir" {
+				return check.Status
+			}
+		}
+		t.Fatalf("missing workDir check:\n%s", out)
+		return ""
+	}
+
+	// 当前进程可写，但属主不是 svc 且其他用户没有写权限
+	if got := workDirStatus(); got != doctorWarn {
+		t.Fatalf("work dir not writable by svc should warn, got %q", got)
+	}
+	// 属组 44 是 svc 的附加组
+	if err := os.Chown(svc.WorkDir, -1, 44); err == nil {
+		if err := os.Chmod(svc.WorkDir, 0o775); err != nil {
+			t.Fatalf("chmod: %v", err)
+		}
+		if got := workDirStatus(); got != doctorPass {
+			t.Fatalf("group-writable work dir should pass, got %q", got)
+		}
+		if err := os.Chmod(svc.WorkDir, 0o755); err != nil {
+			t.Fatalf("chmod: %v", err)
+		}
+	}
+	if err := os.Chmod(svc.WorkDir, 0o777); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if got := workDirStatus(); got != doctorPass {
+		t.Fatalf("world-writable work dir should pass, got %q", got)
+	}
+}
+
+func TestDoctor_TableAndBackend(t *testing.T) {
+	sm := newDoctorTestManager(t)
+	useServiceSystem(sm, nil)
+
+	out, err := runDoctorCmd(t, sm)
+	if err == nil {
+		t.Fatal("missing service manager should fail")
+	}
+	if !strings.Contains(out, "FAIL") || !strings.Contains(out, sm.localizer.GetHint("backend")) {
+		t.Fatalf("table should show the failing check and its hint:\n%s", out)
+	}
+
+	if _, err := runDoctorCmd(t, sm, "--output", "yaml"); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("unsupported output should be rejected, got %v", err)
+	}
+}
+
+func TestDoctor_AvailableWhenConfigFailsChecks(t *testing.T) {
+	useDependencyServices(t, nil)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "missing.env")
+	workDir := filepath.Join(dir, "missing")
+
+	app, err := NewBuilder("en").
+		WithName("doctor-demo").
+		WithExecutable(exe).
+		WithWorkDir(workDir).
+		WithEnvFile(envFile).
+		WithService(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}).
+		BuildWithError()
+	if err != nil {
+		t.Fatalf("BuildWithError: %v", err)
+	}
+	var out bytes.Buffer
+	app.SetOut(&out)
+	app.SetErr(&out)
+
+	app.SetArgs([]string{"doctor", "--output", "json"})
+	if err := app.Execute(); !IsErrorCode(err, ErrConfigValidation) {
+		t.Fatalf("doctor should run and report the failures, got %v\n%s", err, out.String())
+	}
+	var report doctorReport
+	if err := json.NewDecoder(&out).Decode(&report); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out.String())
+	}
+	got := map[string]doctorStatus{}
+	for _, check := range report.Checks {
+		got[check.Check+":"+check.Target] = check.Status
+	}
+	if got["envFile:"+envFile] != doctorFail || got["workDir:"+workDir] != doctorFail {
+		t.Fatalf("doctor should report the env file and work dir, got %v", got)
+	}
+
+	// 其他命令仍返回配置错误
+	app.SetArgs([]string{"status"})
+	if err := app.Execute(); !IsErrorCode(err, ErrConfigInvalid) || !strings.Contains(err.Error(), workDir) {
+		t.Fatalf("status should fail with the config error, got %v", err)
+	}
+}
+
+func TestGetError_FallsBackToSystemErrors(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	if got := sm.localizer.FormatError("pathNotExist", "/x"); strings.Contains(got, "Missing") || !strings.Contains(got, "/x") {
+		t.Fatalf("system errors should be resolved, got %q", got)
+	}
+}
@@ -0,0 +1,253 @@
+package zcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envKeyPattern 环境变量文件中允许的键名
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// parseEnvFile 解析 dotenv 格式的环境变量文件，${VAR} 引用文件内已定义的键与进程环境。
+func parseEnvFile(path string, localizer *ServiceLocalizer) (map[string]string, error) {
+	return readEnvFile(path, os.LookupEnv, localizer)
+}
+
+// loadEnvFiles 按顺序解析多个环境变量文件，后面的文件覆盖前面的同名键，
+// 并可引用前面文件中定义的键。路径以 "-" 开头时文件不存在不视为错误。
+func loadEnvFiles(paths []string, localizer *ServiceLocalizer) (map[string]string, error) {
+	merged := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if value, ok := merged[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+	for _, path := range paths {
+		optional := strings.HasPrefix(path, "-")
+		path = strings.TrimPrefix(path, "-")
+		vars, err := readEnvFile(path, lookup, localizer)
+		if err != nil {
+			if optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		maps.Copy(merged, vars)
+	}
+	return merged, nil
+}
+
+// readEnvFile 打开并解析单个环境变量文件
+func readEnvFile(path string, lookup func(string) (string, bool), localizer *ServiceLocalizer) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return parseDotenv(file, path, lookup, localizer)
+}
+
+// parseDotenv 解析 dotenv 内容：
+//   - 空行与 # 开头的行为注释，未加引号的值中空白后的 # 开始行尾注释
+//   - 可选的 export 前缀
+//   - 单引号内为字面量；双引号内支持 \n \t \r \" \\ \$ 转义，两种引号都可以跨行
+//   - 未加引号与双引号的值展开 $VAR、${VAR} 与 ${VAR:-default}
+func parseDotenv(r io.Reader, path string, lookup func(string) (string, bool), localizer *ServiceLocalizer) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	resolve := func(name string) (string, bool) {
+		if value, ok := vars[name]; ok {
+			return value, true
+		}
+		if lookup != nil {
+			return lookup(name)
+		}
+		return "", false
+	}
+	fail := func(lineNo int, reason string) error {
+		return fmt.Errorf("%s", localizer.FormatError("invalidEnvFile", path, lineNo, reason))
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export "); ok {
+			line = strings.TrimLeft(rest, " \t")
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fail(lineNo, "missing '='")
+		}
+		if !envKeyPattern.MatchString(key) {
+			return nil, fail(lineNo, fmt.Sprintf("invalid key %q", key))
+		}
+
+		raw = strings.TrimLeft(raw, " \t")
+		var value string
+		if raw != "" && (raw[0] == '"' || raw[0] == '\'') {
+			quote := raw[0]
+			body := raw[1:]
+			end := closingQuote(body, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				body += "\n" + lines[i]
+				end = closingQuote(body, quote)
+			}
+			if end < 0 {
+				return nil, fail(lineNo, "unterminated quoted value")
+			}
+			if tail := strings.TrimSpace(body[end+1:]); tail != "" && tail[0] != '#' {
+				return nil, fail(lineNo, fmt.Sprintf("unexpected %q after quoted value", tail))
+			}
+			value = body[:end]
+			if quote == '"' {
+				if value, err = expandEnvValue(value, true, resolve); err != nil {
+					return nil, fail(lineNo, err.Error())
+				}
+			}
+		} else {
+			if idx := inlineCommentIndex(raw); idx >= 0 {
+				raw = raw[:idx]
+			}
+			if value, err = expandEnvValue(strings.TrimSpace(raw), false, resolve); err != nil {
+				return nil, fail(lineNo, err.Error())
+			}
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// closingQuote 返回结束引号的位置，双引号内跳过转义字符；未找到时返回 -1
+func closingQuote(body string, quote byte) int {
+	for i := 0; i < len(body); i++ {
+		switch {
+		case quote == '"' && body[i] == '\\':
+			i++
+		case body[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// inlineCommentIndex 返回未加引号值中行尾注释的起始位置
+func inlineCommentIndex(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandEnvValue 展开变量引用；escapes 为 true 时处理双引号转义
+func expandEnvValue(s string, escapes bool, resolve func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escapes && c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", errors.New("unterminated ${")
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:i+2+end], ":-")
+			if !isEnvName(name) {
+				return "", fmt.Errorf("invalid variable reference ${%s}", s[i+2:i+2+end])
+			}
+			value, ok := resolve(name)
+			if hasFallback && (!ok || value == "") {
+				value = fallback
+			}
+			b.WriteString(value)
+			i += 2 + end
+		case c == '$' && i+1 < len(s) && isEnvNameStart(s[i+1]):
+			j := i + 1
+			for j < len(s) && (isEnvNameStart(s[j]) || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			value, _ := resolve(s[i+1 : j])
+			b.WriteString(value)
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvName(name string) bool {
+	if name == "" || !isEnvNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if c := name[i]; !isEnvNameStart(c) && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveEnvFiles 将 WithEnvFile 文件中的变量合并到 EnvVars，已显式设置的键优先
+func (sm *sManager) resolveEnvFiles(svcCfg *ServiceConfig) error {
+	if len(svcCfg.EnvFiles) == 0 {
+		return nil
+	}
+	vars, err := loadEnvFiles(svcCfg.EnvFiles, sm.localizer)
+	if err != nil {
+		return err
+	}
+	maps.Copy(vars, svcCfg.EnvVars)
+	svcCfg.EnvVars = vars
+	return nil
+}
+
+// applyProcessEnv 前台运行时把服务环境变量与环境变量文件写入当前进程，进程中已存在的变量保持不变
+func (sm *sManager) applyProcessEnv() error {
+	svcCfg, err := sm.baseServiceConfig()
+	if err != nil {
+		return err
+	}
+	if err := sm.resolveEnvFiles(&svcCfg); err != nil {
+		return err
+	}
+	for key, value := range svcCfg.EnvVars {
+		if _, exists := os.LookupEnv(key); !exists {
+			_ = os.Setenv(key, value)
+		}
+	}
+	return nil
+}
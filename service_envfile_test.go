@@ -0,0 +1,157 @@
+package zcli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestParseDotenv_Syntax(t *testing.T) {
+	t.Setenv("ZCLI_TEST_HOST", "db.internal")
+	content := strings.Join([]string{
+		"# comment",
+		"",
+		"export PLAIN=value # trailing comment",
+		"HASH=a#b",
+		"SINGLE='literal $PLAIN \\n'",
+		`DOUBLE="line1\nsay \"hi\" \$PLAIN"`,
+		`URL=postgres://${ZCLI_TEST_HOST}:${PORT:-5432}/$PLAIN`,
+		`MULTI="first`,
+		`second"  # done`,
+		"EMPTY=",
+	}, "\n")
+
+	vars, err := parseDotenv(strings.NewReader(content), "app.env", os.LookupEnv, newTestServiceManager(t, &fakeDaemonService{}).localizer)
+	if err != nil {
+		t.Fatalf("parseDotenv: %v", err)
+	}
+	want := map[string]string{
+		"PLAIN":  "value",
+		"HASH":   "a#b",
+		"SINGLE": "literal $PLAIN \\n",
+		"DOUBLE": "line1\nsay \"hi\" $PLAIN",
+		"URL":    "postgres://db.internal:5432/value",
+		"MULTI":  "first\nsecond",
+		"EMPTY":  "",
+	}
+	for key, value := range want {
+		if vars[key] != value {
+			t.Errorf("%s = %q, want %q", key, vars[key], value)
+		}
+	}
+}
+
+func TestParseDotenv_ReportsFileAndLine(t *testing.T) {
+	localizer := newTestServiceManager(t, &fakeDaemonService{}).localizer
+	cases := map[string]string{
+		"A=1\nnot a pair\n":       "app.env:2",
+		"A=1\n\nB=\"open\n":       "app.env:3",
+		"A=1\n1BAD=x\n":           "app.env:2",
+		"A='x' trailing\n":        "app.env:1",
+		"A=1\nB=${UNCLOSED\n":     "app.env:2",
+		"A=1\nB=\"ok\"\nC=${-}\n": "app.env:3",
+	}
+	for content, want := range cases {
+		_, err := parseDotenv(strings.NewReader(content), "app.env", nil, localizer)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error at %s, got %v", content, want, err)
+		}
+	}
+}
+
+func TestLoadEnvFiles_OrderAndOptional(t *testing.T) {
+	dir := t.TempDir()
+	base := writeEnvFile(t, dir, "base.env", "HOST=localhost\nPORT=80\n")
+	local := writeEnvFile(t, dir, "local.env", "PORT=8080\nADDR=${HOST}:${PORT}\n")
+	localizer := newTestServiceManager(t, &fakeDaemonService{}).localizer
+
+	vars, err := loadEnvFiles([]string{base, local, "-" + filepath.Join(dir, "missing.env")}, localizer)
+	if err != nil {
+		t.Fatalf("loadEnvFiles: %v", err)
+	}
+	if vars["PORT"] != "8080" || vars["ADDR"] != "localhost:8080" {
+		t.Fatalf("unexpected vars: %v", vars)
+	}
+
+	if _, err := loadEnvFiles([]string{filepath.Join(dir, "missing.env")}, localizer); !os.IsNotExist(err) {
+		t.Fatalf("required file should fail with not-exist, got %v", err)
+	}
+}
+
+func TestEnvFile_PrecedenceInInstalledDefinition(t *testing.T) {
+	dir := t.TempDir()
+	sm := newExportTestManager(t)
+	svc := sm.commands.config.service
+	svc.EnvFiles = []string{writeEnvFile(t, dir, "app.env", "APP_ENV=dev\nFROM_FILE=1\nOVERRIDE=file\n")}
+	override := writeEnvFile(t, dir, "override.env", "OVERRIDE=flag-file\n")
+
+	out := runExport(t, sm, "--format", "systemd", "--env-file", override, "--env", "GREETING=cli")
+	for _, want := range []string{
+		`Environment="APP_ENV=prod"`,
+		`Environment="FROM_FILE=1"`,
+		`Environment="OVERRIDE=flag-file"`,
+		`Environment="GREETING=cli"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("systemd unit missing %s:\n%s", want, out)
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	svc.Executable = exe
+	_, config, err := sm.resolveInstallTarget(nil)
+	if err != nil {
+		t.Fatalf("resolveInstallTarget: %v", err)
+	}
+	if config.EnvVars["FROM_FILE"] != "1" || config.EnvVars["APP_ENV"] != "prod" {
+		t.Fatalf("service definition should merge env files under EnvVars: %v", config.EnvVars)
+	}
+}
+
+func TestApplyProcessEnv_KeepsExistingVariables(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	t.Setenv("ZCLI_TEST_KEEP", "outer")
+	t.Setenv("ZCLI_TEST_NEW", "")
+	_ = os.Unsetenv("ZCLI_TEST_NEW")
+	sm.commands.config.service.EnvVars = map[string]string{"ZCLI_TEST_KEEP": "file", "ZCLI_TEST_NEW": "file"}
+
+	if err := sm.applyProcessEnv(); err != nil {
+		t.Fatalf("applyProcessEnv: %v", err)
+	}
+	if os.Getenv("ZCLI_TEST_KEEP") != "outer" || os.Getenv("ZCLI_TEST_NEW") != "file" {
+		t.Fatalf("unexpected process env: KEEP=%q NEW=%q", os.Getenv("ZCLI_TEST_KEEP"), os.Getenv("ZCLI_TEST_NEW"))
+	}
+}
+
+func TestEnvFile_MissingOnlyFailsCommandsThatReadIt(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	sm.commands.config.service.Executable = exe
+	sm.commands.config.service.EnvFiles = []string{filepath.Join(t.TempDir(), "missing.env")}
+
+	if _, err := sm.createServiceConfig(); err != nil {
+		t.Fatalf("a missing env file must not break other commands: %v", err)
+	}
+	if _, _, err := sm.resolveInstallTarget(nil); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("install should fail on the missing env file, got %v", err)
+	}
+	if err := sm.applyProcessEnv(); err == nil {
+		t.Fatal("run should fail on the missing env file")
+	}
+}
@@ -0,0 +1,285 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultExecShutdownGrace 与 Runtime.ShutdownGrace 的默认值一致
+const defaultExecShutdownGrace = 5 * time.Second
+
+// maxPrefixLineBytes 单行输出的缓存上限，超出后按上限拆分写出
+const maxPrefixLineBytes = 64 << 10
+
+// ExecOptions 外部进程服务的配置
+type ExecOptions struct {
+	Name          string        // 服务名称，默认取命令文件名
+	Dir           string        // 子进程工作目录
+	Env           []string      // 追加到当前环境的变量，格式 KEY=VALUE
+	StdoutPrefix  string        // 标准输出每行的前缀，默认 "[<name>] "
+	StderrPrefix  string        // 标准错误每行的前缀，默认 "[<name>] "
+	ReloadSignals []os.Signal   // 收到后原样转发给子进程的信号，默认 SIGHUP
+	StopSignal    os.Signal     // 停止时发送的信号，默认 SIGTERM；平台不支持时直接结束进程
+	ShutdownGrace time.Duration // 发送 StopSignal 后等待多久改用 SIGKILL，默认 5s
+}
+
+// ExecService 把外部程序作为 ServiceRunner 运行：输出写入服务日志，
+// 转发重载信号，停止时向子进程所在的进程组先发送 StopSignal，超过 ShutdownGrace 后强制结束。
+type ExecService struct {
+	path string
+	args []string
+	opts ExecOptions
+
+	mu      sync.Mutex
+	process *os.Process
+	stopCh  chan struct{}
+	done    chan struct{}
+}
+
+// NewExecService 创建外部进程服务，配合 Builder.WithServiceRunner 即可作为简单的进程守护
+func NewExecService(cmd string, args []string, opts ExecOptions) *ExecService {
+	if opts.Name == "" {
+		opts.Name = filepath.Base(cmd)
+	}
+	if opts.StdoutPrefix == "" {
+		opts.StdoutPrefix = "[" + opts.Name + "] "
+	}
+	if opts.StderrPrefix == "" {
+		opts.StderrPrefix = "[" + opts.Name + "] "
+	}
+	if opts.ReloadSignals == nil {
+		opts.ReloadSignals = []os.Signal{syscall.SIGHUP}
+	}
+	if opts.StopSignal == nil {
+		opts.StopSignal = syscall.SIGTERM
+	}
+	if opts.ShutdownGrace <= 0 {
+		opts.ShutdownGrace = defaultExecShutdownGrace
+	}
+	return &ExecService{path: cmd, args: append([]string(nil), args...), opts: opts}
+}
+
+// Name 返回服务名称
+func (s *ExecService) Name() string {
+	return s.opts.Name
+}
+
+// Run 启动子进程并等待其退出。ctx 取消或调用 Stop 时按 StopSignal、SIGKILL 的顺序结束子进程；
+// 子进程自行以非 0 状态退出，或需要强制结束时返回带退出码的 ServiceError。
+func (s *ExecService) Run(ctx context.Context) error {
+	stdout, stderr := s.outputs(ctx)
+	cmd := exec.Command(s.path, s.args...)
+	cmd.Dir = s.opts.Dir
+	cmd.Env = append(os.Environ(), s.opts.Env...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = execSysProcAttr()
+	// 派生的进程继承输出管道时，子进程退出后最多再等待 ShutdownGrace
+	cmd.WaitDelay = s.opts.ShutdownGrace
+
+	// 先注册再启动，子进程启动后收到的重载信号不会丢失
+	signals := make(chan os.Signal, 1)
+	if len(s.opts.ReloadSignals) > 0 {
+		signal.Notify(signals, s.opts.ReloadSignals...)
+		defer signal.Stop(signals)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return NewError(ErrServiceStart).
+			Service(s.Name()).
+			Operation("run").
+			Message("failed to start process").
+			Cause(err).
+			Context("command", s.path).
+			Build()
+	}
+
+	stopCh, done := make(chan struct{}), make(chan struct{})
+	s.mu.Lock()
+	s.process, s.stopCh, s.done = cmd.Process, stopCh, done
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.process = nil
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		stdout.flush()
+		stderr.flush()
+		waitErr <- err
+	}()
+
+	for {
+		select {
+		case err := <-waitErr:
+			return s.exitError(cmd.ProcessState, err, false, false)
+		case sig := <-signals:
+			_ = cmd.Process.Signal(sig)
+		case <-ctx.Done():
+			return s.terminate(cmd, waitErr)
+		case <-stopCh:
+			return s.terminate(cmd, waitErr)
+		}
+	}
+}
+
+// Signal 向运行中的子进程发送信号，未运行时忽略
+func (s *ExecService) Signal(sig os.Signal) error {
+	s.mu.Lock()
+	process := s.process
+	s.mu.Unlock()
+	if process == nil {
+		return nil
+	}
+	return process.Signal(sig)
+}
+
+// Stop 请求结束子进程并等待 Run 返回，可重复调用
+func (s *ExecService) Stop() error {
+	s.mu.Lock()
+	stopCh, done := s.stopCh, s.done
+	if stopCh != nil {
+		select {
+		case <-stopCh:
+		default:
+			close(stopCh)
+		}
+	}
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return nil
+}
+
+// terminate 向进程组发送 StopSignal，超过 ShutdownGrace 仍未退出时强制结束整个进程组
+func (s *ExecService) terminate(cmd *exec.Cmd, waitErr <-chan error) error {
+	if err := signalProcessGroup(cmd.Process, s.opts.StopSignal); err != nil {
+		_ = killProcessGroup(cmd.Process)
+	}
+	timer := time.NewTimer(s.opts.ShutdownGrace)
+	defer timer.Stop()
+	select {
+	case err := <-waitErr:
+		return s.exitError(cmd.ProcessState, err, true, false)
+	case <-timer.C:
+		_ = killProcessGroup(cmd.Process)
+		err := <-waitErr
+		return s.exitError(cmd.ProcessState, err, true, true)
+	}
+}
+
+// exitError 把子进程的退出状态转换为错误。停止过程中因 StopSignal 结束、以 0 或 128+信号值退出视为正常。
+func (s *ExecService) exitError(state *os.ProcessState, err error, stopping, killed bool) error {
+	if state == nil {
+		if err == nil {
+			return nil
+		}
+		return WrapServiceOperationError(err, ErrRuntime, "run", s.Name())
+	}
+
+	code := state.ExitCode()
+	var signaled os.Signal
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		signaled = status.Signal()
+	}
+	if !killed {
+		if code == 0 {
+			return nil
+		}
+		if stopping {
+			if stopSig, ok := s.opts.StopSignal.(syscall.Signal); ok && (signaled == stopSig || code == 128+int(stopSig)) {
+				return nil
+			}
+		}
+	}
+
+	errCode, message := ErrRuntime, "process exited with status "+state.String()
+	if killed {
+		errCode, message = ErrServiceTimeout, "process did not exit within "+s.opts.ShutdownGrace.String()+" and was killed"
+	}
+	builder := NewError(errCode).
+		Service(s.Name()).
+		Operation("run").
+		Message(message).
+		Context("command", s.path).
+		Context("exit_code", code).
+		Context("pid", state.Pid())
+	if err != nil && !errors.As(err, new(*exec.ExitError)) {
+		builder.Cause(err)
+	}
+	if signaled != nil {
+		builder.Context("signal", signaled.String())
+	}
+	return builder.Build()
+}
+
+// outputs 返回子进程输出的写入目标：配置了日志 sink 时标准输出记为 Info、标准错误记为 Error，
+// 否则分别写入当前进程的标准输出与标准错误
+func (s *ExecService) outputs(ctx context.Context) (*prefixLineWriter, *prefixLineWriter) {
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if sink, ok := LogWriter(ctx).(LogSink); ok {
+		stdout, stderr = LevelWriter(sink, LogLevelInfo), LevelWriter(sink, LogLevelError)
+	}
+	return newPrefixLineWriter(stdout, s.opts.StdoutPrefix), newPrefixLineWriter(stderr, s.opts.StderrPrefix)
+}
+
+// prefixLineWriter 按行加前缀写出，不完整的行缓存到下一次写入或 flush；
+// 超过 maxPrefixLineBytes 仍无换行时按上限拆分写出
+type prefixLineWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func newPrefixLineWriter(out io.Writer, prefix string) *prefixLineWriter {
+	return &prefixLineWriter{out: out, prefix: prefix}
+}
+
+func (w *prefixLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		var line []byte
+		idx := bytes.IndexByte(w.buf, '\n')
+		switch {
+		case idx >= 0 && idx < maxPrefixLineBytes:
+			line = append([]byte(w.prefix), w.buf[:idx+1]...)
+			w.buf = w.buf[idx+1:]
+		case len(w.buf) >= maxPrefixLineBytes:
+			line = append(append([]byte(w.prefix), w.buf[:maxPrefixLineBytes]...), '\n')
+			w.buf = w.buf[maxPrefixLineBytes:]
+		default:
+			return len(p), nil
+		}
+		if _, err := w.out.Write(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// flush 写出缓存中未以换行结尾的内容
+func (w *prefixLineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return
+	}
+	_, _ = w.out.Write(append([]byte(w.prefix), append(w.buf, '\n')...))
+	w.buf = nil
+}
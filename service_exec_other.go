@@ -0,0 +1,23 @@
+//go:build !linux && !darwin
+
+package zcli
+
+import (
+	"os"
+	"syscall"
+)
+
+// execSysProcAttr 当前平台不创建独立进程组
+func execSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// signalProcessGroup 当前平台只向子进程本身发送信号
+func signalProcessGroup(process *os.Process, sig os.Signal) error {
+	return process.Signal(sig)
+}
+
+// killProcessGroup 当前平台只结束子进程本身
+func killProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
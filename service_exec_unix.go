@@ -0,0 +1,27 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"os"
+	"syscall"
+)
+
+// execSysProcAttr 子进程自成进程组，停止时连同其派生的进程一起结束
+func execSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcessGroup 向子进程所在的进程组发送信号
+func signalProcessGroup(process *os.Process, sig os.Signal) error {
+	unixSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return process.Signal(sig)
+	}
+	return syscall.Kill(-process.Pid, unixSig)
+}
+
+// killProcessGroup 强制结束子进程所在的进程组
+func killProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,181 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// runExecService 在带日志 sink 的上下文中运行脚本，返回 Run 的结果通道
+func runExecService(t *testing.T, script string, opts ExecOptions) (*ExecService, *captureSink, context.CancelFunc, <-chan error) {
+	t.Helper()
+	sink := &captureSink{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), logSinkKey{}, LogSink(sink)))
+	t.Cleanup(cancel)
+	svc := NewExecService("/bin/sh", []string{"-c", script}, opts)
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+	return svc, sink, cancel, done
+}
+
+func waitExec(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("exec service did not return")
+		return nil
+	}
+}
+
+// waitOutput 等待子进程输出指定内容
+func waitOutput(t *testing.T, sink *captureSink, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(sink.text(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("output missing %q:\n%s", want, sink.text())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestExecService_PrefixesOutputAndReportsExitCode(t *testing.T) {
+	_, sink, _, done := runExecService(t, `echo hello; printf 'partial'; echo oops >&2; exit 3`, ExecOptions{Name: "worker"})
+
+	err := waitExec(t, done)
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Code != ErrRuntime || serviceErr.Context["exit_code"] != 3 {
+		t.Fatalf("expected exit code 3 in ServiceError, got %#v", err)
+	}
+	out := sink.text()
+	for _, want := range []string{"INFO [worker] hello\n", "INFO [worker] partial\n", "ERROR [worker] oops\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExecService_ForwardsReloadAndStopsGracefully(t *testing.T) {
+	svc, sink, cancel, done := runExecService(t,
+		`trap 'echo reloaded' HUP; trap 'echo bye; exit 0' TERM; echo ready; while :; do sleep 0.02; done`,
+		ExecOptions{})
+	waitOutput(t, sink, "ready")
+
+	// 重载信号由当前进程转发给子进程
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("send SIGHUP: %v", err)
+	}
+	waitOutput(t, sink, "[sh] reloaded")
+
+	cancel()
+	if err := waitExec(t, done); err != nil {
+		t.Fatalf("graceful stop should return nil, got %v", err)
+	}
+	if !strings.Contains(sink.text(), "bye") {
+		t.Fatalf("child should receive SIGTERM:\n%s", sink.text())
+	}
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Stop after exit: %v", err)
+	}
+}
+
+func TestExecService_EscalatesToKillAfterGrace(t *testing.T) {
+	svc, sink, _, done := runExecService(t,
+		`trap '' TERM; echo ready; while :; do sleep 0.02; done`,
+		ExecOptions{ShutdownGrace: 100 * time.Millisecond})
+	waitOutput(t, sink, "ready")
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- svc.Stop() }()
+
+	err := waitExec(t, done)
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrServiceTimeout || serviceErr.Context["signal"] != syscall.SIGKILL.String() {
+		t.Fatalf("expected kill after grace, got %#v", err)
+	}
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
+
+func TestExecService_StopsTheWholeProcessGroup(t *testing.T) {
+	// 派生的进程持有 FIFO 的写端，它退出后读端读到 EOF，不依赖固定等待或进程回收
+	fifo := filepath.Join(t.TempDir(), "grandchild")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Fatalf("mkfifo: %v", err)
+	}
+	_, _, cancel, done := runExecService(t, fmt.Sprintf(`sleep 30 >%q & wait`, fifo), ExecOptions{})
+
+	opened := make(chan *os.File, 1)
+	go func() {
+		f, err := os.Open(fifo)
+		if err != nil {
+			t.Errorf("open fifo: %v", err)
+		}
+		opened <- f
+	}()
+	var f *os.File
+	select {
+	case f = <-opened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("grandchild did not start")
+	}
+	if f == nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	// 派生的进程继承了输出管道，只结束直接子进程时 Run 会一直等待
+	cancel()
+	if err := waitExec(t, done); err != nil {
+		t.Fatalf("stop should return nil, got %v", err)
+	}
+	closed := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(f)
+		closed <- err
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("read fifo: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("grandchild outlived the exec service")
+	}
+}
+
+func TestPrefixLineWriter_SplitsLinesOverTheCap(t *testing.T) {
+	var out bytes.Buffer
+	w := newPrefixLineWriter(&out, "> ")
+	long := strings.Repeat("x", maxPrefixLineBytes+10)
+	if _, err := w.Write([]byte(long)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := out.String(); got != "> "+long[:maxPrefixLineBytes]+"\n" || len(w.buf) != 10 {
+		t.Fatalf("a full buffer should be flushed as a line, got %d bytes out and %d buffered", len(got), len(w.buf))
+	}
+	w.flush()
+	if !strings.HasSuffix(out.String(), "> xxxxxxxxxx\n") {
+		t.Fatalf("remaining bytes should be flushed, got %q", out.String()[out.Len()-20:])
+	}
+}
+
+func TestExecService_StartFailure(t *testing.T) {
+	err := NewExecService("/nonexistent/zcli-binary", nil, ExecOptions{}).Run(context.Background())
+	if !IsErrorCode(err, ErrServiceStart) {
+		t.Fatalf("expected SERVICE_START, got %v", err)
+	}
+}
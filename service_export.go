@@ -0,0 +1,761 @@
+package zcli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// exportSpec 是与目标格式无关的服务定义，由 install 使用的同一份配置生成
+type exportSpec struct {
+	Name        string
+	DisplayName string
+	Description string
+	Version     string
+	User        string
+	Executable  string
+	Arguments   []string
+	WorkDir     string
+	ChRoot      string
+	Env         []envPair
+	Deps        []Dependency
+	RawUnit     []string // 旧式 Dependencies 中形如 Key=Value 的 systemd 指令
+	Directives  []string // 仅 systemd 支持的 [Service] 指令，如加固选项
+	StartTime   time.Duration
+	StopTime    time.Duration
+	Restart     string
+	LimitNOFILE int
+	Image       string
+
+	// 以下为 daemon 模板处理的 systemd 选项，export 渲染完整 unit 时使用
+	UserService       bool
+	LogDir            string // 启用 LogOutput 时标准输出与错误写入的目录
+	SuccessExitStatus string
+	PIDFile           string
+	ReloadSignal      string
+}
+
+type envPair struct {
+	Key   string
+	Value string
+}
+
+// exportRenderer 把服务定义渲染为目标格式
+type exportRenderer func(spec *exportSpec) string
+
+// exportRenderers 已支持的导出格式
+var exportRenderers = map[string]exportRenderer{
+	"systemd":        renderSystemd,
+	"launchd":        renderLaunchd,
+	"openrc":         renderOpenRC,
+	"sysv":           renderSysV,
+	"supervisord":    renderSupervisord,
+	"docker-compose": renderCompose,
+	"kubernetes":     renderKubernetes,
+}
+
+// exportFormatNames 返回排序后的格式列表
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportRenderers))
+	for name := range exportRenderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newExportCmd 创建离线导出服务定义命令
+func (sm *sManager) newExportCmd() *cobra.Command {
+	cmd := sm.buildBaseCommand("export", sm.localizer.GetOperation("export"))
+	overrides := &installOverrides{}
+	sm.bindInstallFlags(cmd, overrides)
+	var format, output, image string
+	cmd.Flags().StringVar(&format, "format", "systemd", sm.localizer.GetFlag("format")+" ("+strings.Join(exportFormatNames(), "|")+")")
+	cmd.Flags().StringVarP(&output, "output", "o", "", sm.localizer.GetFlag("output"))
+	cmd.Flags().StringVar(&image, "image", "", sm.localizer.GetFlag("image"))
+	// 导出在构建机上离线进行，目标主机的路径在本机不存在也不影响生成服务定义
+	cmd.RunE = sm.wrapDiagnosticRunE(func(cmd *cobra.Command, args []string) error {
+		render, ok := exportRenderers[format]
+		if !ok {
+			err := fmt.Errorf("%s", sm.localizer.FormatError("unsupportedFormat", format, strings.Join(exportFormatNames(), ", ")))
+			return sm.wrapServiceError(err, ErrConfigInvalid, "export")
+		}
+
+		spec, err := sm.buildExportSpec(overrides)
+		if err != nil {
+			return err
+		}
+		if image != "" {
+			spec.Image = image
+		}
+
+		content := render(spec)
+		if output == "" {
+			_, err = fmt.Fprint(cmd.OutOrStdout(), content)
+			return err
+		}
+		if err := os.WriteFile(output, []byte(content), 0o644); err != nil {
+			return sm.wrapServiceError(err, ErrPathInvalid, "export")
+		}
+		return nil
+	})
+	return cmd
+}
+
+// buildExportSpec 按 install 的规则合并覆盖项与布局，生成导出用的服务定义
+func (sm *sManager) buildExportSpec(o *installOverrides) (*exportSpec, error) {
+	svcCfg, err := sm.baseServiceConfig()
+	if err != nil {
+		return nil, WrapServiceOperationError(err, ErrConfigInvalid, "export", sm.Name())
+	}
+	if o != nil && !o.empty() {
+		merged, err := o.apply(svcCfg, sm.localizer)
+		if err != nil {
+			return nil, NewError(ErrConfigInvalid).
+				Service(sm.Name()).
+				Operation("export").
+				Message(err.Error()).
+				Cause(err).
+				Build()
+		}
+		svcCfg = merged
+	}
+
+	if err := sm.resolveEnvFiles(&svcCfg); err != nil {
+		return nil, WrapServiceOperationError(err, ErrConfigInvalid, "export", svcCfg.Name)
+	}
+	config, err := sm.translateServiceConfig(svcCfg)
+	if err != nil {
+		return nil, WrapServiceOperationError(err, ErrConfigInvalid, "export", svcCfg.Name)
+	}
+	if prefix := svcCfg.Layout.BinaryPrefix; prefix != "" {
+		config.Executable = filepath.Join(prefix, filepath.Base(config.Executable))
+	}
+	if svcCfg.CreateUser && config.UserName == "" {
+		config.UserName = sm.commands.config.basic.Name
+	}
+
+	return sm.newExportSpec(svcCfg, config), nil
+}
+
+// newExportSpec 由 daemon 配置生成与格式无关的服务定义
+func (sm *sManager) newExportSpec(svcCfg ServiceConfig, config *service.Config) *exportSpec {
+	spec := &exportSpec{
+		Name:        config.Name,
+		DisplayName: config.DisplayName,
+		Description: config.Description,
+		Version:     svcCfg.Version,
+		User:        config.UserName,
+		Executable:  config.Executable,
+		Arguments:   config.Arguments,
+		WorkDir:     config.WorkingDirectory,
+		ChRoot:      config.ChRoot,
+		Deps:        append([]Dependency(nil), config.StructuredDeps...),
+		StartTime:   sm.commands.config.runtime.StartTimeout,
+		StopTime:    sm.commands.config.runtime.StopTimeout,
+		Restart:     "always",
+		Directives:  sm.systemdDirectives(svcCfg),
+	}
+	for _, dep := range config.Dependencies {
+		if strings.Contains(dep, "=") {
+			spec.RawUnit = append(spec.RawUnit, dep)
+			continue
+		}
+		spec.Deps = append(spec.Deps, Dependency{Name: dep, Type: DependencyRequire})
+	}
+
+	keys := make([]string, 0, len(config.EnvVars))
+	for key := range config.EnvVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		spec.Env = append(spec.Env, envPair{Key: key, Value: config.EnvVars[key]})
+	}
+
+	if restart, ok := config.Option["Restart"].(string); ok && restart != "" {
+		spec.Restart = restart
+	}
+	switch limit := config.Option["LimitNOFILE"].(type) {
+	case int:
+		spec.LimitNOFILE = limit
+	case string:
+		spec.LimitNOFILE, _ = strconv.Atoi(limit)
+	}
+	spec.UserService, _ = config.Option["UserService"].(bool)
+	if logOutput, _ := config.Option["LogOutput"].(bool); logOutput {
+		spec.LogDir = "/var/log"
+		if dir, ok := config.Option["LogDirectory"].(string); ok && dir != "" {
+			spec.LogDir = dir
+		}
+	}
+	spec.SuccessExitStatus, _ = config.Option["SuccessExitStatus"].(string)
+	spec.PIDFile, _ = config.Option["PIDFile"].(string)
+	spec.ReloadSignal, _ = config.Option["ReloadSignal"].(string)
+
+	spec.Image = strings.ToLower(spec.Name) + ":latest"
+	if spec.Version != "" {
+		spec.Image = strings.ToLower(spec.Name) + ":" + spec.Version
+	}
+	return spec
+}
+
+// depsOf 返回指定类型的依赖名称
+func (s *exportSpec) depsOf(types ...DependencyType) []string {
+	var names []string
+	for _, dep := range s.Deps {
+		depType := dep.Type
+		if depType == "" {
+			depType = DependencyRequire
+		}
+		for _, t := range types {
+			if depType == t {
+				names = append(names, dep.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// command 返回可执行文件与参数组成的命令行，必要时加引号
+func (s *exportSpec) command() string {
+	parts := make([]string, 0, len(s.Arguments)+1)
+	for _, part := range append([]string{s.Executable}, s.Arguments...) {
+		parts = append(parts, shellQuote(part))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote 对含空白或特殊字符的参数加单引号
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`;&|<>*?()[]{}#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+func renderSystemd(s *exportSpec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	_, _ = fmt.Fprintf(&b, "Description=%s\n", systemdEscape(firstNonEmpty(s.Description, s.DisplayName)))
+	writeLines(&b, s.systemdUnitLines())
+
+	b.WriteString("\n[Service]\nType=simple\n")
+	_, _ = fmt.Fprintf(&b, "ExecStart=%s\n", s.systemdCommand())
+	if s.WorkDir != "" {
+		_, _ = fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdEscape(s.WorkDir))
+	}
+	if s.ChRoot != "" {
+		_, _ = fmt.Fprintf(&b, "RootDirectory=%s\n", systemdEscape(s.ChRoot))
+	}
+	// 用户级服务由用户自己的 systemd 实例运行，不能切换用户
+	if s.User != "" && !s.UserService {
+		_, _ = fmt.Fprintf(&b, "User=%s\n", s.User)
+	}
+	_, _ = fmt.Fprintf(&b, "Restart=%s\n", s.Restart)
+	if s.LimitNOFILE > 0 {
+		_, _ = fmt.Fprintf(&b, "LimitNOFILE=%d\n", s.LimitNOFILE)
+	}
+	if s.SuccessExitStatus != "" {
+		_, _ = fmt.Fprintf(&b, "SuccessExitStatus=%s\n", s.SuccessExitStatus)
+	}
+	if s.PIDFile != "" {
+		_, _ = fmt.Fprintf(&b, "PIDFile=%s\n", systemdEscape(s.PIDFile))
+	}
+	if s.ReloadSignal != "" {
+		_, _ = fmt.Fprintf(&b, "ExecReload=/bin/kill -%s $MAINPID\n", s.ReloadSignal)
+	}
+	if s.LogDir != "" {
+		_, _ = fmt.Fprintf(&b, "StandardOutput=file:%s\n", systemdEscape(filepath.Join(s.LogDir, s.Name+".out")))
+		_, _ = fmt.Fprintf(&b, "StandardError=file:%s\n", systemdEscape(filepath.Join(s.LogDir, s.Name+".err")))
+	}
+	writeLines(&b, s.systemdServiceLines())
+	_, _ = fmt.Fprintf(&b, "\n[Install]\nWantedBy=%s\n", s.systemdTarget())
+	return b.String()
+}
+
+// renderSystemdTemplate 在 daemon 默认的 systemd unit 模板上追加 zcli 的内容，作为 SystemdScript 交给 daemon：
+// Description、工作目录、Restart、LimitNOFILE、日志输出等仍由 daemon 按安装时的选项填充，
+// 依赖、ExecStart、环境变量、超时与专用指令按 systemd 规则转义后以模板字符串常量写入，值中的 {{ 不会被当作模板语法
+func renderSystemdTemplate(s *exportSpec) string {
+	user := "{{if .UserName}}User={{.UserName}}{{end}}"
+	// 用户级服务由用户自己的 systemd 实例运行，不能切换用户
+	if s.UserService {
+		user = ""
+	}
+	return fmt.Sprintf(`[Unit]
+Description={{.Description}}
+ConditionFileIsExecutable={{.Path|cmdEscape}}
+%s
+[Service]
+StartLimitInterval=5
+StartLimitBurst=10
+%s
+{{if .ChRoot}}RootDirectory={{.ChRoot|cmd}}{{end}}
+{{if .WorkingDirectory}}WorkingDirectory={{.WorkingDirectory|cmdEscape}}{{end}}
+%s
+{{if .ReloadSignal}}ExecReload=/bin/kill -{{.ReloadSignal}} "$MAINPID"{{end}}
+{{if .PIDFile}}PIDFile={{.PIDFile|cmd}}{{end}}
+{{if and .LogOutput .HasOutputFileSupport -}}
+StandardOutput=file:{{.LogDirectory}}/{{.Name}}.out
+StandardError=file:{{.LogDirectory}}/{{.Name}}.err
+{{- end}}
+{{if gt .LimitNOFILE -1 }}LimitNOFILE={{.LimitNOFILE}}{{end}}
+{{if .Restart}}Restart={{.Restart}}{{end}}
+{{if .SuccessExitStatus}}SuccessExitStatus={{.SuccessExitStatus}}{{end}}
+RestartSec=120
+EnvironmentFile=-/etc/sysconfig/{{.Name}}
+%s
+[Install]
+WantedBy=%s
+`,
+		templateLiteral(s.systemdUnitLines()),
+		templateLiteral([]string{"ExecStart=" + s.systemdCommand()}),
+		user,
+		templateLiteral(s.systemdServiceLines()),
+		s.systemdTarget(),
+	)
+}
+
+// systemdUnitLines 返回 [Unit] 中的依赖与旧式 Dependencies 指令
+func (s *exportSpec) systemdUnitLines() []string {
+	var lines []string
+	for _, dep := range []struct {
+		key   string
+		types []DependencyType
+	}{
+		{"After", []DependencyType{DependencyAfter, DependencyRequire, DependencyWant}},
+		{"Requires", []DependencyType{DependencyRequire}},
+		{"Wants", []DependencyType{DependencyWant}},
+		{"Before", []DependencyType{DependencyBefore}},
+	} {
+		if names := s.depsOf(dep.types...); len(names) > 0 {
+			lines = append(lines, dep.key+"="+strings.Join(names, " "))
+		}
+	}
+	return append(lines, s.RawUnit...)
+}
+
+// systemdServiceLines 返回 [Service] 末尾的环境变量、超时与专用指令，后出现的同名指令覆盖模板中的默认值
+func (s *exportSpec) systemdServiceLines() []string {
+	var lines []string
+	for _, env := range s.Env {
+		lines = append(lines, "Environment="+systemdQuote(env.Key+"="+env.Value, false))
+	}
+	if s.StartTime > 0 {
+		lines = append(lines, fmt.Sprintf("TimeoutStartSec=%d", seconds(s.StartTime)))
+	}
+	if s.StopTime > 0 {
+		lines = append(lines, fmt.Sprintf("TimeoutStopSec=%d", seconds(s.StopTime)))
+	}
+	return append(lines, s.Directives...)
+}
+
+// systemdTarget 返回 [Install] 的 WantedBy
+func (s *exportSpec) systemdTarget() string {
+	if s.UserService {
+		return "default.target"
+	}
+	return "multi-user.target"
+}
+
+// systemdCommand 返回按 systemd 规则转义的 ExecStart 命令行
+func (s *exportSpec) systemdCommand() string {
+	parts := make([]string, 0, len(s.Arguments)+1)
+	for _, part := range append([]string{s.Executable}, s.Arguments...) {
+		parts = append(parts, systemdQuote(part, true))
+	}
+	return strings.Join(parts, " ")
+}
+
+// systemdEscape 把 % 写作 %%，避免被 systemd 当作说明符展开
+func systemdEscape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// systemdQuote 按 systemd 规则转义单个值：% 写作 %%，ExecStart 中的 $ 写作 $$；
+// 含空白、引号或反斜杠时加双引号并以反斜杠转义。Environment 的值总是加引号
+func systemdQuote(s string, exec bool) string {
+	s = systemdEscape(s)
+	if exec {
+		s = strings.ReplaceAll(s, "$", "$$")
+		if s != "" && !strings.ContainsAny(s, " \t\n\"'\\;") {
+			return s
+		}
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
+
+// templateLiteral 把多行文本写成 text/template 的字符串常量，daemon 执行模板时原样输出
+func templateLiteral(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return "{{" + strconv.Quote(strings.Join(lines, "\n")) + "}}"
+}
+
+// writeLines 逐行写入 b
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+}
+
+func renderLaunchd(s *exportSpec) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	plistString(&b, 1, "Label", s.Name)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{s.Executable}, s.Arguments...) {
+		_, _ = fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	if s.User != "" {
+		plistString(&b, 1, "UserName", s.User)
+	}
+	if s.WorkDir != "" {
+		plistString(&b, 1, "WorkingDirectory", s.WorkDir)
+	}
+	if s.ChRoot != "" {
+		plistString(&b, 1, "RootDirectory", s.ChRoot)
+	}
+	if len(s.Env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, env := range s.Env {
+			plistString(&b, 2, env.Key, env.Value)
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	_, _ = fmt.Fprintf(&b, "\t<key>KeepAlive</key>\n\t<%t/>\n", s.Restart != "no")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	if s.StopTime > 0 {
+		_, _ = fmt.Fprintf(&b, "\t<key>ExitTimeOut</key>\n\t<integer>%d</integer>\n", seconds(s.StopTime))
+	}
+	if s.LimitNOFILE > 0 {
+		_, _ = fmt.Fprintf(&b, "\t<key>SoftResourceLimits</key>\n\t<dict>\n\t\t<key>NumberOfFiles</key>\n\t\t<integer>%d</integer>\n\t</dict>\n", s.LimitNOFILE)
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func plistString(b *strings.Builder, depth int, key, value string) {
+	tabs := strings.Repeat("\t", depth)
+	_, _ = fmt.Fprintf(b, "%s<key>%s</key>\n%s<string>%s</string>\n", tabs, xmlEscape(key), tabs, xmlEscape(value))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func renderOpenRC(s *exportSpec) string {
+	var b strings.Builder
+	b.WriteString("#!/sbin/openrc-run\n\n")
+	_, _ = fmt.Fprintf(&b, "name=%s\n", strconv.Quote(s.DisplayName))
+	if s.Description != "" {
+		_, _ = fmt.Fprintf(&b, "description=%s\n", strconv.Quote(s.Description))
+	}
+	_, _ = fmt.Fprintf(&b, "command=%s\n", strconv.Quote(s.Executable))
+	if len(s.Arguments) > 0 {
+		args := make([]string, 0, len(s.Arguments))
+		for _, arg := range s.Arguments {
+			args = append(args, shellQuote(arg))
+		}
+		_, _ = fmt.Fprintf(&b, "command_args=%s\n", strconv.Quote(strings.Join(args, " ")))
+	}
+	if s.User != "" {
+		_, _ = fmt.Fprintf(&b, "command_user=%s\n", strconv.Quote(s.User))
+	}
+	b.WriteString("command_background=true\n")
+	b.WriteString("pidfile=\"/run/${RC_SVCNAME}.pid\"\n")
+	if s.WorkDir != "" {
+		_, _ = fmt.Fprintf(&b, "directory=%s\n", strconv.Quote(s.WorkDir))
+	}
+	if s.ChRoot != "" {
+		_, _ = fmt.Fprintf(&b, "chroot=%s\n", strconv.Quote(s.ChRoot))
+	}
+	if s.StopTime > 0 {
+		_, _ = fmt.Fprintf(&b, "retry=\"TERM/%d/KILL/5\"\n", seconds(s.StopTime))
+	}
+	if s.LimitNOFILE > 0 {
+		_, _ = fmt.Fprintf(&b, "rc_ulimit=\"-n %d\"\n", s.LimitNOFILE)
+	}
+	if s.Restart != "no" {
+		b.WriteString("supervisor=supervise-daemon\n")
+	}
+	for _, env := range s.Env {
+		_, _ = fmt.Fprintf(&b, "export %s=%s\n", env.Key, shellQuote(env.Value))
+	}
+
+	b.WriteString("\ndepend() {\n")
+	for _, line := range []struct {
+		keyword string
+		types   []DependencyType
+	}{
+		{"need", []DependencyType{DependencyRequire}},
+		{"want", []DependencyType{DependencyWant}},
+		{"after", []DependencyType{DependencyAfter}},
+		{"before", []DependencyType{DependencyBefore}},
+	} {
+		if deps := s.depsOf(line.types...); len(deps) > 0 {
+			_, _ = fmt.Fprintf(&b, "\t%s %s\n", line.keyword, strings.Join(deps, " "))
+		}
+	}
+	b.WriteString("\tuse net logger\n}\n")
+	return b.String()
+}
+
+func renderSysV(s *exportSpec) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n### BEGIN INIT INFO\n")
+	_, _ = fmt.Fprintf(&b, "# Provides:          %s\n", s.Name)
+	_, _ = fmt.Fprintf(&b, "# Required-Start:    %s\n", strings.Join(append([]string{"$remote_fs", "$syslog"}, s.depsOf(DependencyRequire)...), " "))
+	b.WriteString("# Required-Stop:     $remote_fs $syslog\n")
+	if deps := s.depsOf(DependencyWant, DependencyAfter); len(deps) > 0 {
+		_, _ = fmt.Fprintf(&b, "# Should-Start:      %s\n", strings.Join(deps, " "))
+	}
+	if deps := s.depsOf(DependencyBefore); len(deps) > 0 {
+		_, _ = fmt.Fprintf(&b, "# X-Start-Before:    %s\n", strings.Join(deps, " "))
+	}
+	b.WriteString("# Default-Start:     2 3 4 5\n# Default-Stop:      0 1 6\n")
+	_, _ = fmt.Fprintf(&b, "# Short-Description: %s\n", s.DisplayName)
+	_, _ = fmt.Fprintf(&b, "# Description:       %s\n", firstNonEmpty(s.Description, s.DisplayName))
+	b.WriteString("### END INIT INFO\n\n")
+
+	_, _ = fmt.Fprintf(&b, "name=%s\n", shellQuote(s.Name))
+	_, _ = fmt.Fprintf(&b, "cmd=%s\n", shellQuote(s.command()))
+	_, _ = fmt.Fprintf(&b, "user=%s\n", shellQuote(s.User))
+	_, _ = fmt.Fprintf(&b, "workdir=%s\n", shellQuote(s.WorkDir))
+	stopWait := 10
+	if s.StopTime > 0 {
+		stopWait = seconds(s.StopTime)
+	}
+	_, _ = fmt.Fprintf(&b, "stop_wait=%d\n", stopWait)
+	b.WriteString(`pid_file="/var/run/$name.pid"
+stdout_log="/var/log/$name.log"
+stderr_log="/var/log/$name.err"
+`)
+	for _, env := range s.Env {
+		_, _ = fmt.Fprintf(&b, "export %s=%s\n", env.Key, shellQuote(env.Value))
+	}
+	if s.LimitNOFILE > 0 {
+		_, _ = fmt.Fprintf(&b, "ulimit -n %d\n", s.LimitNOFILE)
+	}
+	b.WriteString(`
+is_running() {
+	[ -f "$pid_file" ] && kill -0 "$(cat "$pid_file")" 2>/dev/null
+}
+
+case "$1" in
+	start)
+		if is_running; then
+			echo "$name is already running"
+			exit 0
+		fi
+		[ -n "$workdir" ] && cd "$workdir"
+		if [ -n "$user" ]; then
+			su -s /bin/sh -c "exec $cmd" "$user" >>"$stdout_log" 2>>"$stderr_log" &
+		else
+			sh -c "exec $cmd" >>"$stdout_log" 2>>"$stderr_log" &
+		fi
+		echo $! >"$pid_file"
+		;;
+	stop)
+		if is_running; then
+			kill "$(cat "$pid_file")"
+			i=0
+			while is_running && [ "$i" -lt "$stop_wait" ]; do
+				sleep 1
+				i=$((i + 1))
+			done
+			is_running && kill -9 "$(cat "$pid_file")"
+		fi
+		rm -f "$pid_file"
+		;;
+	restart)
+		"$0" stop
+		"$0" start
+		;;
+	status)
+		if is_running; then
+			echo "$name is running"
+		else
+			echo "$name is stopped"
+			exit 3
+		fi
+		;;
+	*)
+		echo "Usage: $0 {start|stop|restart|status}"
+		exit 1
+		;;
+esac
+`)
+	return b.String()
+}
+
+func renderSupervisord(s *exportSpec) string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "[program:%s]\n", s.Name)
+	_, _ = fmt.Fprintf(&b, "command=%s\n", s.command())
+	if s.WorkDir != "" {
+		_, _ = fmt.Fprintf(&b, "directory=%s\n", s.WorkDir)
+	}
+	if s.User != "" {
+		_, _ = fmt.Fprintf(&b, "user=%s\n", s.User)
+	}
+	if len(s.Env) > 0 {
+		pairs := make([]string, 0, len(s.Env))
+		for _, env := range s.Env {
+			pairs = append(pairs, env.Key+"="+strconv.Quote(env.Value))
+		}
+		_, _ = fmt.Fprintf(&b, "environment=%s\n", strings.Join(pairs, ","))
+	}
+	b.WriteString("autostart=true\n")
+	switch s.Restart {
+	case "no":
+		b.WriteString("autorestart=false\n")
+	case "on-failure":
+		b.WriteString("autorestart=unexpected\n")
+	default:
+		b.WriteString("autorestart=true\n")
+	}
+	if s.StopTime > 0 {
+		_, _ = fmt.Fprintf(&b, "stopwaitsecs=%d\n", seconds(s.StopTime))
+	}
+	return b.String()
+}
+
+func renderCompose(s *exportSpec) string {
+	var b strings.Builder
+	b.WriteString("services:\n")
+	_, _ = fmt.Fprintf(&b, "  %s:\n", s.Name)
+	_, _ = fmt.Fprintf(&b, "    image: %s\n", strconv.Quote(s.Image))
+	_, _ = fmt.Fprintf(&b, "    entrypoint: %s\n", yamlList(s.Executable))
+	if len(s.Arguments) > 0 {
+		_, _ = fmt.Fprintf(&b, "    command: %s\n", yamlList(s.Arguments...))
+	}
+	if s.WorkDir != "" {
+		_, _ = fmt.Fprintf(&b, "    working_dir: %s\n", strconv.Quote(s.WorkDir))
+	}
+	if s.User != "" {
+		_, _ = fmt.Fprintf(&b, "    user: %s\n", strconv.Quote(s.User))
+	}
+	if len(s.Env) > 0 {
+		b.WriteString("    environment:\n")
+		for _, env := range s.Env {
+			_, _ = fmt.Fprintf(&b, "      %s: %s\n", env.Key, strconv.Quote(env.Value))
+		}
+	}
+	if deps := s.depsOf(DependencyRequire, DependencyWant); len(deps) > 0 {
+		b.WriteString("    depends_on:\n")
+		for _, dep := range deps {
+			_, _ = fmt.Fprintf(&b, "      - %s\n", dep)
+		}
+	}
+	switch s.Restart {
+	case "no", "on-failure":
+		_, _ = fmt.Fprintf(&b, "    restart: %s\n", s.Restart)
+	default:
+		b.WriteString("    restart: unless-stopped\n")
+	}
+	if s.StopTime > 0 {
+		_, _ = fmt.Fprintf(&b, "    stop_grace_period: %ds\n", seconds(s.StopTime))
+	}
+	if s.LimitNOFILE > 0 {
+		_, _ = fmt.Fprintf(&b, "    ulimits:\n      nofile: %d\n", s.LimitNOFILE)
+	}
+	return b.String()
+}
+
+func renderKubernetes(s *exportSpec) string {
+	name := dnsLabel(s.Name)
+	var b strings.Builder
+	b.WriteString("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n")
+	_, _ = fmt.Fprintf(&b, "  name: %s\n", name)
+	_, _ = fmt.Fprintf(&b, "  labels:\n    app.kubernetes.io/name: %s\n", name)
+	if s.Version != "" {
+		_, _ = fmt.Fprintf(&b, "    app.kubernetes.io/version: %s\n", strconv.Quote(s.Version))
+	}
+	b.WriteString("spec:\n  replicas: 1\n  selector:\n    matchLabels:\n")
+	_, _ = fmt.Fprintf(&b, "      app.kubernetes.io/name: %s\n", name)
+	b.WriteString("  template:\n    metadata:\n      labels:\n")
+	_, _ = fmt.Fprintf(&b, "        app.kubernetes.io/name: %s\n", name)
+	b.WriteString("    spec:\n")
+	if s.StopTime > 0 {
+		_, _ = fmt.Fprintf(&b, "      terminationGracePeriodSeconds: %d\n", seconds(s.StopTime))
+	}
+	if s.Restart == "no" {
+		b.WriteString("      restartPolicy: Never\n")
+	}
+	b.WriteString("      containers:\n")
+	_, _ = fmt.Fprintf(&b, "        - name: %s\n", name)
+	_, _ = fmt.Fprintf(&b, "          image: %s\n", strconv.Quote(s.Image))
+	_, _ = fmt.Fprintf(&b, "          command: %s\n", yamlList(s.Executable))
+	if len(s.Arguments) > 0 {
+		_, _ = fmt.Fprintf(&b, "          args: %s\n", yamlList(s.Arguments...))
+	}
+	if s.WorkDir != "" {
+		_, _ = fmt.Fprintf(&b, "          workingDir: %s\n", strconv.Quote(s.WorkDir))
+	}
+	if len(s.Env) > 0 {
+		b.WriteString("          env:\n")
+		for _, env := range s.Env {
+			_, _ = fmt.Fprintf(&b, "            - name: %s\n              value: %s\n", env.Key, strconv.Quote(env.Value))
+		}
+	}
+	return b.String()
+}
+
+// yamlList 以 flow 风格输出字符串列表
+func yamlList(items ...string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, strconv.Quote(item))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// dnsLabel 把服务名转换为 Kubernetes 资源名可接受的 DNS label
+func dnsLabel(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	label := strings.Trim(b.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package zcli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExportTestManager(t *testing.T) *sManager {
+	t.Helper()
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	svc := sm.commands.config.service
+	svc.Executable = "/opt/demo/bin/demo"
+	svc.Arguments = []string{"run", "--port=8080"}
+	svc.Username = "demo"
+	svc.EnvVars = map[string]string{"APP_ENV": "prod", "GREETING": "hello world"}
+	svc.StructuredDeps = []Dependency{
+		{Name: "network-online.target", Type: DependencyAfter},
+		{Name: "postgresql", Type: DependencyRequire},
+	}
+	svc.Options = ServiceOptions{"LimitNOFILE": 65536}
+	sm.commands.config.runtime.StartTimeout = 20 * time.Second
+	sm.commands.config.runtime.StopTimeout = 15 * time.Second
+	return sm
+}
+
+func runExport(t *testing.T, sm *sManager, args ...string) string {
+	t.Helper()
+	cmd := sm.newExportCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("export %v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestExportCommand_Systemd(t *testing.T) {
+	out := runExport(t, newExportTestManager(t), "--format", "systemd")
+
+	for _, want := range []string{
+		"After=network-online.target postgresql\n",
+		"Requires=postgresql\n",
+		"ExecStart=/opt/demo/bin/demo run --port=8080\n",
+		"WorkingDirectory=/opt/demo/bin\n",
+		"User=demo\n",
+		"Environment=\"APP_ENV=prod\"\n",
+		"Environment=\"GREETING=hello world\"\n",
+		"TimeoutStartSec=20\n",
+		"TimeoutStopSec=15\n",
+		"LimitNOFILE=65536\n",
+		"WantedBy=multi-user.target\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("systemd unit missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportCommand_SystemdUserServiceAndLogOutput(t *testing.T) {
+	sm := newExportTestManager(t)
+	sm.commands.config.service.Systemd = &SystemdOptions{UserService: true, LogOutput: true}
+	out := runExport(t, sm, "--format", "systemd")
+
+	for _, want := range []string{
+		"StandardOutput=file:/var/log/test-service.out\n",
+		"StandardError=file:/var/log/test-service.err\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("systemd unit missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "User=") {
+		t.Fatalf("user services must not switch users:\n%s", out)
+	}
+}
+
+func TestExportCommand_LaunchdIsWellFormed(t *testing.T) {
+	sm := newExportTestManager(t)
+	sm.commands.config.service.EnvVars["TOKEN"] = "a<b&c"
+	out := runExport(t, sm, "--format", "launchd")
+
+	decoder := xml.NewDecoder(strings.NewReader(out))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Fatalf("launchd plist is not well-formed: %v\n%s", err, out)
+		}
+	}
+	if !strings.Contains(out, "<string>a&lt;b&amp;c</string>") {
+		t.Fatalf("expected escaped env value:\n%s", out)
+	}
+}
+
+func TestExportCommand_AllFormatsUseInstallOverrides(t *testing.T) {
+	sm := newExportTestManager(t)
+	for _, format := range exportFormatNames() {
+		out := runExport(t, sm, "--format", format, "--executable", "/usr/bin/demo-pkg")
+		if !strings.Contains(out, "/usr/bin/demo-pkg") {
+			t.Fatalf("%s output should use overridden executable:\n%s", format, out)
+		}
+	}
+}
+
+func TestExportCommand_WritesOutputFile(t *testing.T) {
+	sm := newExportTestManager(t)
+	path := filepath.Join(t.TempDir(), "demo.yaml")
+	if out := runExport(t, sm, "--format", "kubernetes", "-o", path, "--image", "registry/demo:1.2.3"); out != "" {
+		t.Fatalf("expected nothing on stdout, got %q", out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if !strings.Contains(string(data), `image: "registry/demo:1.2.3"`) {
+		t.Fatalf("unexpected manifest:\n%s", data)
+	}
+}
+
+func TestExportCommand_IgnoresHostPathChecks(t *testing.T) {
+	sm := newExportTestManager(t)
+	sm.configErr = NewError(ErrConfigInvalid).Message("/opt/demo/bin/demo does not exist").Build()
+
+	if out := runExport(t, sm, "--format", "systemd"); !strings.Contains(out, "ExecStart=/opt/demo/bin/demo ") {
+		t.Fatalf("export should not depend on paths of the build host, got:\n%s", out)
+	}
+}
+
+func TestExportCommand_RejectsUnknownFormat(t *testing.T) {
+	sm := newExportTestManager(t)
+	cmd := sm.newExportCmd()
+	if err := cmd.ParseFlags([]string{"--format", "upstart"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("expected ErrConfigInvalid, got %v", err)
+	}
+}
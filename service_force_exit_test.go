@@ -6,39 +6,10 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
-
-	service "github.com/darkit/daemon"
 )
 
-type stubSystemInteractive struct{}
-
-func (stubSystemInteractive) String() string    { return "stub-interactive" }
-func (stubSystemInteractive) Detect() bool      { return true }
-func (stubSystemInteractive) Interactive() bool { return true }
-func (stubSystemInteractive) New(service.Interface, *service.Config) (service.Service, error) {
-	return nil, nil
-}
-
-type stubSystemDaemon struct{}
-
-func (stubSystemDaemon) String() string    { return "stub-daemon" }
-func (stubSystemDaemon) Detect() bool      { return true }
-func (stubSystemDaemon) Interactive() bool { return false }
-func (stubSystemDaemon) New(service.Interface, *service.Config) (service.Service, error) {
-	return nil, nil
-}
-
 func TestForceExit_DaemonOnly(t *testing.T) {
 	t.Run("interactive_skips_force_exit", func(t *testing.T) {
-		origSystems := service.AvailableSystems()
-		origChosen := service.ChosenSystem()
-		service.ChooseSystem(stubSystemInteractive{})
-		if origChosen != nil {
-			t.Cleanup(func() { service.ChooseSystem(origChosen) })
-		} else {
-			t.Cleanup(func() { service.ChooseSystem(origSystems...) })
-		}
-
 		config := NewConfig()
 		config.basic.Name = "force-exit-interactive"
 		config.runtime.Run = func(ctx context.Context) error { <-ctx.Done(); return nil }
@@ -51,6 +22,7 @@ func TestForceExit_DaemonOnly(t *testing.T) {
 		if err != nil {
 			t.Fatalf("newServiceManager: %v", err)
 		}
+		sm.sys.interactive = func() bool { return true }
 
 		origExit := exitFunc
 		defer func() { exitFunc = origExit }()
@@ -65,15 +37,6 @@ func TestForceExit_DaemonOnly(t *testing.T) {
 	})
 
 	t.Run("daemon_schedules_force_exit", func(t *testing.T) {
-		origSystems := service.AvailableSystems()
-		origChosen := service.ChosenSystem()
-		service.ChooseSystem(stubSystemDaemon{})
-		if origChosen != nil {
-			t.Cleanup(func() { service.ChooseSystem(origChosen) })
-		} else {
-			t.Cleanup(func() { service.ChooseSystem(origSystems...) })
-		}
-
 		config := NewConfig()
 		config.basic.Name = "force-exit-daemon"
 		config.runtime.Run = func(ctx context.Context) error { <-ctx.Done(); return nil }
@@ -86,6 +49,7 @@ func TestForceExit_DaemonOnly(t *testing.T) {
 		if err != nil {
 			t.Fatalf("newServiceManager: %v", err)
 		}
+		sm.sys.interactive = func() bool { return false }
 
 		origExit := exitFunc
 		defer func() { exitFunc = origExit }()
@@ -105,15 +69,6 @@ func TestForceExit_DaemonOnly(t *testing.T) {
 }
 
 func TestForceExit_CancelledBeforeTimeout(t *testing.T) {
-	origSystems := service.AvailableSystems()
-	origChosen := service.ChosenSystem()
-	service.ChooseSystem(stubSystemDaemon{})
-	if origChosen != nil {
-		t.Cleanup(func() { service.ChooseSystem(origChosen) })
-	} else {
-		t.Cleanup(func() { service.ChooseSystem(origSystems...) })
-	}
-
 	config := NewConfig()
 	config.basic.Name = "force-exit-cancel"
 	config.runtime.Run = func(ctx context.Context) error { <-ctx.Done(); return nil }
@@ -128,21 +83,24 @@ func TestForceExit_CancelledBeforeTimeout(t *testing.T) {
 	if err != nil {
 		t.Fatalf("newServiceManager: %v", err)
 	}
+	sm.sys.interactive = func() bool { return false }
 
 	origExit := exitFunc
 	defer func() { exitFunc = origExit }()
 	var exitCalled atomic.Bool
 	exitFunc = func(int) { exitCalled.Store(true) }
 
-	go func() {
-		err := sm.Run(sm.getCtx())
-		if err != nil && !errors.Is(err, context.Canceled) {
-			return
-		}
-	}()
+	done := make(chan error, 1)
+	go func() { done <- sm.Run(sm.getCtx()) }()
+	for !sm.running.Load() {
+		time.Sleep(time.Millisecond)
+	}
 
 	_ = sm.Stop()
-	// allow Run to finish and cancel force exit
+	if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run: %v", err)
+	}
+	// wait past the stop timeout to confirm the force exit was cancelled
 	time.Sleep(2 * config.runtime.StopTimeout)
 	if exitCalled.Load() {
 		t.Fatal("force exit should be cancelled when run finishes")
@@ -0,0 +1,323 @@
+package zcli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// CrashLoopExitCode 判定为崩溃循环时的进程退出码，生成的 systemd unit 以 RestartPreventExitStatus 阻止重启
+const CrashLoopExitCode = 78
+
+// historyLimit 历史文件保留的事件数
+const historyLimit = 200
+
+// historyMu 串行化同一进程内对历史文件的读写
+var historyMu sync.Mutex
+
+// LifecycleEventType 生命周期事件类型
+type LifecycleEventType string
+
+const (
+	LifecycleStart          LifecycleEventType = "start"            // 启动
+	LifecycleRestart        LifecycleEventType = "restart"          // 失败后再次启动
+	LifecycleStop           LifecycleEventType = "stop"             // 正常停止
+	LifecycleCrash          LifecycleEventType = "crash"            // Run 返回错误、panic 或进程未正常退出
+	LifecycleForceExit      LifecycleEventType = "force_exit"       // 停止超时被强制退出
+	LifecycleCrashLoop      LifecycleEventType = "crash_loop"       // 判定为崩溃循环，拒绝启动
+	LifecycleCrashLoopReset LifecycleEventType = "crash_loop_reset" // start 或 restart 命令清除崩溃循环状态
+
+	// 计划任务与维护模式事件只通知回调，不写入历史文件
+	LifecycleTaskRun     LifecycleEventType = "task_run"     // 计划任务运行结束，失败时 Error 非空
+	LifecycleTaskSkipped LifecycleEventType = "task_skipped" // 计划任务触发被跳过，原因见 Skipped
+	LifecyclePause       LifecycleEventType = "pause"        // 进入维护模式
+	LifecycleResume      LifecycleEventType = "resume"       // 退出维护模式
+)
+
+// LifecycleEvent 一条生命周期记录
+type LifecycleEvent struct {
+	Type   LifecycleEventType `json:"type"`
+	Time   time.Time          `json:"time"`
+	PID    int                `json:"pid,omitempty"`
+	Reason ShutdownReason     `json:"reason,omitempty"` // 停止原因，取自 ShutdownCause
+	Signal string             `json:"signal,omitempty"`
+	Error  string             `json:"error,omitempty"`
+
+	Task     string        `json:"task,omitempty"`     // 计划任务名称
+	Duration time.Duration `json:"duration,omitempty"` // 计划任务运行耗时
+	Skipped  string        `json:"skipped,omitempty"`  // 计划任务被跳过的原因：overlap、missed 或 paused
+}
+
+// failure 报告事件是否计入崩溃循环
+func (e LifecycleEvent) failure() bool {
+	return e.Type == LifecycleCrash || e.Type == LifecycleForceExit
+}
+
+// detail 返回用于展示的原因描述
+func (e LifecycleEvent) detail() string {
+	switch {
+	case e.Error != "":
+		return e.Error
+	case e.Signal != "":
+		return string(e.Reason) + " (" + e.Signal + ")"
+	default:
+		return string(e.Reason)
+	}
+}
+
+// LifecycleHook 生命周期事件回调，在事件写入历史文件后同步调用；计划任务与维护模式事件在各自的协程中调用，需自行保证并发安全
+type LifecycleHook func(LifecycleEvent)
+
+// CrashLoopPolicy 崩溃循环判定：Window 内失败 MaxFailures 次后不再启动
+type CrashLoopPolicy struct {
+	MaxFailures int
+	Window      time.Duration
+}
+
+// validate 校验崩溃循环参数
+func (p *CrashLoopPolicy) validate() error {
+	if p.MaxFailures < 1 || p.Window <= 0 {
+		return fmt.Errorf("crash loop: MaxFailures must be positive and Window must be greater than 0, got %d/%s", p.MaxFailures, p.Window)
+	}
+	return nil
+}
+
+// historyFile 返回生命周期历史文件：StateDir 下的 <name>.history，未配置 StateDir 时返回空，不记录历史
+func (sm *sManager) historyFile() string {
+	dir := sm.currentLayout().StateDir.Path
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, sm.Name()+".history")
+}
+
+// readHistory 读取历史文件，每行一条 JSON 记录，无法解析的行被忽略
+func readHistory(path string) ([]LifecycleEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var events []LifecycleEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event LifecycleEvent
+		if json.Unmarshal(scanner.Bytes(), &event) == nil && event.Type != "" {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// writeHistory 只保留最近 historyLimit 条记录，经临时文件原子替换
+func writeHistory(path string, events []LifecycleEvent) error {
+	if len(events) > historyLimit {
+		events = events[len(events)-historyLimit:]
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendHistory 追加事件并触发回调。未配置历史文件时只触发回调，写入失败不影响服务运行。
+func (sm *sManager) appendHistory(events ...LifecycleEvent) {
+	for _, event := range events {
+		sm.metrics.observe(event)
+	}
+	if path := sm.historyFile(); path != "" {
+		historyMu.Lock()
+		history, _ := readHistory(path)
+		_ = writeHistory(path, append(history, events...))
+		historyMu.Unlock()
+	}
+
+	sm.notifyLifecycle(events...)
+}
+
+// isCrashLoop 报告错误是否为崩溃循环判定
+func isCrashLoop(err error) bool {
+	var serviceErr *ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.Code == ErrServiceCrashLoop
+}
+
+// notifyLifecycle 依次调用生命周期回调
+func (sm *sManager) notifyLifecycle(events ...LifecycleEvent) {
+	for _, event := range events {
+		for _, hook := range sm.commands.config.runtime.LifecycleHooks {
+			hook(event)
+		}
+	}
+}
+
+// newLifecycleEvent 创建当前进程的事件
+func (sm *sManager) newLifecycleEvent(eventType LifecycleEventType) LifecycleEvent {
+	return LifecycleEvent{Type: eventType, Time: sm.sys.now(), PID: sm.sys.pid()}
+}
+
+// recordStart 记录启动。上次运行未记录退出时补记一次崩溃；
+// 由服务管理器拉起且判定为崩溃循环时记录 crash_loop 并返回 ErrServiceCrashLoop。
+// 崩溃循环状态在 start 或 restart 命令清除之前一直有效，期间由服务管理器拉起的 run 均被拒绝。
+func (sm *sManager) recordStart() error {
+	// 未配置历史文件时没有可判定的历史，仍记录启动事件以触发回调
+	var history []LifecycleEvent
+	if path := sm.historyFile(); path != "" {
+		historyMu.Lock()
+		history, _ = readHistory(path)
+		historyMu.Unlock()
+	}
+	if n := len(history); n > 0 {
+		sm.metrics.observePrevious(history[n-1])
+	}
+
+	policy := sm.commands.config.runtime.CrashLoop
+	managed := policy != nil && !sm.sys.interactive()
+	if managed && crashLooped(history) {
+		return ErrServiceCrashLoopPending(sm.Name())
+	}
+
+	var events []LifecycleEvent
+	if n := len(history); n > 0 && (history[n-1].Type == LifecycleStart || history[n-1].Type == LifecycleRestart) {
+		unclean := sm.newLifecycleEvent(LifecycleCrash)
+		unclean.PID = history[n-1].PID
+		unclean.Error = "process exited without recording a stop"
+		events = append(events, unclean)
+		history = append(history, unclean)
+	}
+
+	if managed {
+		if failures := recentFailures(history, sm.sys.now().Add(-policy.Window)); failures >= policy.MaxFailures {
+			err := ErrServiceCrashLooping(sm.Name(), failures, policy.Window)
+			loop := sm.newLifecycleEvent(LifecycleCrashLoop)
+			loop.Error = err.Message
+			sm.appendHistory(append(events, loop)...)
+			return err
+		}
+	}
+
+	start := sm.newLifecycleEvent(LifecycleStart)
+	if n := len(history); n > 0 && history[n-1].failure() {
+		start.Type = LifecycleRestart
+	}
+	sm.appendHistory(append(events, start)...)
+	return nil
+}
+
+// recentFailures 统计 since 之后的失败次数，正常停止或清除崩溃循环状态之后重新计数
+func recentFailures(history []LifecycleEvent, since time.Time) int {
+	failures := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		event := history[i]
+		if event.Type == LifecycleStop || event.Type == LifecycleCrashLoopReset || event.Time.Before(since) {
+			break
+		}
+		if event.failure() {
+			failures++
+		}
+	}
+	return failures
+}
+
+// crashLooped 报告最近一次崩溃循环判定是否尚未被清除
+func crashLooped(history []LifecycleEvent) bool {
+	for i := len(history) - 1; i >= 0; i-- {
+		switch history[i].Type {
+		case LifecycleCrashLoopReset:
+			return false
+		case LifecycleCrashLoop:
+			return true
+		}
+	}
+	return false
+}
+
+// clearCrashLoop 由 start 与 restart 命令在启动服务前调用，存在崩溃循环状态时记录 crash_loop_reset。
+// 写入失败时返回错误，否则服务会被 run 继续拒绝
+func (sm *sManager) clearCrashLoop() error {
+	path := sm.historyFile()
+	if path == "" {
+		return nil
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	history, err := readHistory(path)
+	if err != nil || !crashLooped(history) {
+		return nil
+	}
+	if err := writeHistory(path, append(history, sm.newLifecycleEvent(LifecycleCrashLoopReset))); err != nil {
+		return err
+	}
+	sm.localizer.LogWarning("%s", sm.localizer.GetMessage("crashLoopClear"))
+	return nil
+}
+
+// recordExit 记录 Run 的退出：返回错误记为崩溃，否则记为停止并附带关闭原因
+func (sm *sManager) recordExit(ctx context.Context, err error) {
+	if err != nil {
+		event := sm.newLifecycleEvent(LifecycleCrash)
+		event.Error = err.Error()
+		sm.appendHistory(event)
+		return
+	}
+	event := sm.newLifecycleEvent(LifecycleStop)
+	if cause, ok := GetShutdownCause(ctx); ok {
+		event.Reason = cause.Reason
+		if cause.Signal != nil {
+			event.Signal = cause.Signal.String()
+		}
+	}
+	sm.appendHistory(event)
+}
+
+// printHistory 按时间顺序输出生命周期历史
+func (sm *sManager) printHistory(out io.Writer) error {
+	path := sm.historyFile()
+	if path == "" {
+		sm.localizer.LogWarning("%s", sm.localizer.GetMessage("noHistory"))
+		return nil
+	}
+	historyMu.Lock()
+	history, err := readHistory(path)
+	historyMu.Unlock()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return sm.wrapServiceError(err, ErrPathInvalid, "status")
+	}
+	if len(history) == 0 {
+		sm.localizer.LogWarning("%s", sm.localizer.GetMessage("noHistory"))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		sm.localizer.GetLabel("time"), sm.localizer.GetLabel("event"),
+		sm.localizer.GetLabel("pid"), sm.localizer.GetLabel("detail"))
+	for _, event := range history {
+		pid := ""
+		if event.PID > 0 {
+			pid = strconv.Itoa(event.PID)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			event.Time.Local().Format("2006-01-02 15:04:05"), event.Type, pid, event.detail())
+	}
+	return w.Flush()
+}
@@ -0,0 +1,253 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newHistoryTestManager 构造使用临时 StateDir 的服务管理器，并固定事件时钟
+func newHistoryTestManager(t *testing.T) (*sManager, func(time.Duration)) {
+	t.Helper()
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.commands.config.service.Layout.StateDir.Path = t.TempDir()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sm.sys.now = func() time.Time { return now }
+	return sm, func(d time.Duration) { now = now.Add(d) }
+}
+
+// setServiceManaged 模拟由服务管理器拉起
+func setServiceManaged(sm *sManager) {
+	sm.sys.interactive = func() bool { return false }
+}
+
+func historyTypes(t *testing.T, sm *sManager) []LifecycleEventType {
+	t.Helper()
+	history, err := readHistory(sm.historyFile())
+	if err != nil {
+		t.Fatalf("readHistory: %v", err)
+	}
+	types := make([]LifecycleEventType, 0, len(history))
+	for _, event := range history {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestHistory_RecordsLifecycle(t *testing.T) {
+	sm, advance := newHistoryTestManager(t)
+
+	if err := sm.recordStart(); err != nil {
+		t.Fatalf("recordStart: %v", err)
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(newShutdownCause(ShutdownReasonSignal, syscall.SIGTERM, nil))
+	sm.recordExit(ctx, nil)
+
+	advance(time.Minute)
+	_ = sm.recordStart()
+	sm.recordExit(context.Background(), errors.New("db unreachable"))
+	advance(time.Minute)
+	_ = sm.recordStart()
+	// 进程被 SIGKILL 等方式结束时不会记录退出，下次启动时补记
+	advance(time.Minute)
+	_ = sm.recordStart()
+
+	want := []LifecycleEventType{
+		LifecycleStart, LifecycleStop,
+		LifecycleStart, LifecycleCrash,
+		LifecycleRestart,
+		LifecycleCrash, LifecycleRestart,
+	}
+	if got := historyTypes(t, sm); strings.Join(eventNames(got), ",") != strings.Join(eventNames(want), ",") {
+		t.Fatalf("history = %v, want %v", got, want)
+	}
+
+	history, _ := readHistory(sm.historyFile())
+	if history[1].Reason != ShutdownReasonSignal || history[1].Signal != syscall.SIGTERM.String() {
+		t.Fatalf("stop should carry the shutdown cause: %+v", history[1])
+	}
+	if history[3].Error != "db unreachable" {
+		t.Fatalf("crash should carry the error: %+v", history[3])
+	}
+}
+
+func eventNames(types []LifecycleEventType) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return names
+}
+
+func TestHistory_IsBounded(t *testing.T) {
+	sm, _ := newHistoryTestManager(t)
+	for i := 0; i < historyLimit+20; i++ {
+		sm.appendHistory(sm.newLifecycleEvent(LifecycleStop))
+	}
+	if got := len(historyTypes(t, sm)); got != historyLimit {
+		t.Fatalf("history should keep %d entries, got %d", historyLimit, got)
+	}
+}
+
+func TestHistory_CrashLoopStopsRestarting(t *testing.T) {
+	sm, advance := newHistoryTestManager(t)
+	setServiceManaged(sm)
+	sm.commands.config.runtime.CrashLoop = &CrashLoopPolicy{MaxFailures: 3, Window: 10 * time.Minute}
+	var events []LifecycleEvent
+	sm.commands.config.runtime.LifecycleHooks = []LifecycleHook{func(e LifecycleEvent) { events = append(events, e) }}
+
+	// 窗口外的失败不计入
+	_ = sm.recordStart()
+	sm.recordExit(context.Background(), errors.New("boom"))
+	advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if err := sm.recordStart(); err != nil {
+			t.Fatalf("start %d should be allowed: %v", i, err)
+		}
+		sm.recordExit(context.Background(), errors.New("boom"))
+		advance(time.Minute)
+	}
+
+	err := sm.recordStart()
+	if !IsErrorCode(err, ErrServiceCrashLoop) {
+		t.Fatalf("expected SERVICE_CRASH_LOOP, got %v", err)
+	}
+	if last := events[len(events)-1]; last.Type != LifecycleCrashLoop {
+		t.Fatalf("crash loop should be emitted to hooks, got %+v", last)
+	}
+
+	// 崩溃循环状态保持到 start 命令清除，窗口过去后服务管理器的重启仍被拒绝
+	advance(time.Hour)
+	if err := sm.recordStart(); !IsErrorCode(err, ErrServiceCrashLoop) {
+		t.Fatalf("the crash loop should stay in effect until cleared, got %v", err)
+	}
+	cmd := sm.newStartCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if got := historyTypes(t, sm); got[len(got)-1] != LifecycleCrashLoopReset {
+		t.Fatalf("start should clear the crash loop, got %v", got)
+	}
+	if err := sm.recordStart(); err != nil {
+		t.Fatalf("run after start should be allowed: %v", err)
+	}
+}
+
+func TestRun_CrashLoopExitsWithoutCallingRun(t *testing.T) {
+	sm, _ := newHistoryTestManager(t)
+	setServiceManaged(sm)
+	sm.commands.config.runtime.CrashLoop = &CrashLoopPolicy{MaxFailures: 1, Window: time.Minute}
+	var ran bool
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		ran = true
+		return errors.New("boom")
+	}
+
+	var exitCode int
+	prevExit := exitFunc
+	exitFunc = func(code int) { exitCode = code }
+	t.Cleanup(func() { exitFunc = prevExit })
+
+	if err := sm.Run(context.Background()); err == nil || !ran {
+		t.Fatalf("first run should fail from user Run, got %v", err)
+	}
+	ran = false
+	if err := sm.Run(context.Background()); !IsErrorCode(err, ErrServiceCrashLoop) {
+		t.Fatalf("expected crash loop, got %v", err)
+	}
+	if ran || exitCode != 0 {
+		t.Fatalf("crash loop must not call Run nor exit inside Run, ran=%v code=%d", ran, exitCode)
+	}
+	if got := historyTypes(t, sm); len(got) != 3 || got[1] != LifecycleCrash || got[2] != LifecycleCrashLoop {
+		t.Fatalf("unexpected history: %v", got)
+	}
+
+	// 清除后再次失败，run 命令在 Run 返回、清理完成后以专用退出码退出
+	if err := sm.clearCrashLoop(); err != nil {
+		t.Fatalf("clearCrashLoop: %v", err)
+	}
+	if err := sm.Run(context.Background()); err == nil || !ran {
+		t.Fatalf("manual start after a crash loop should call Run, got %v", err)
+	}
+	ran = false
+	sm.exitChan = make(chan struct{})
+	sm.service = &runningDaemonService{run: func() error { return sm.Run(nil) }}
+	cmd := sm.newRunCmd()
+	if err := cmd.RunE(cmd, nil); !isCrashLoop(err) {
+		t.Fatalf("run command should surface the crash loop, got %v", err)
+	}
+	if ran || exitCode != CrashLoopExitCode || sm.running.Load() {
+		t.Fatalf("run command must exit with %d after cleanup, ran=%v code=%d", CrashLoopExitCode, ran, exitCode)
+	}
+}
+
+// runningDaemonService 在 Run 中同步执行服务逻辑
+type runningDaemonService struct {
+	fakeDaemonService
+	run func() error
+}
+
+func (r *runningDaemonService) Run() error { return r.run() }
+
+func TestAppendHistory_NotifiesHooksWithoutHistoryFile(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	var events []LifecycleEventType
+	sm.commands.config.runtime.LifecycleHooks = []LifecycleHook{func(e LifecycleEvent) { events = append(events, e.Type) }}
+
+	if err := sm.recordStart(); err != nil {
+		t.Fatalf("recordStart: %v", err)
+	}
+	sm.recordExit(context.Background(), nil)
+	if len(events) != 2 || events[0] != LifecycleStart || events[1] != LifecycleStop {
+		t.Fatalf("hooks should fire without a history file, got %v", events)
+	}
+}
+
+func TestStatusHistoryAndSystemdDirective(t *testing.T) {
+	sm, _ := newHistoryTestManager(t)
+	_ = sm.recordStart()
+	sm.recordExit(context.Background(), errors.New("db unreachable"))
+
+	cmd := sm.newStatusCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	if err := cmd.ParseFlags([]string{"--history"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("status --history: %v", err)
+	}
+	for _, want := range []string{"start", "crash", "db unreachable"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("history output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	export := newExportTestManager(t)
+	export.commands.config.runtime.CrashLoop = &CrashLoopPolicy{MaxFailures: 5, Window: time.Minute}
+	unit := runExport(t, export, "--format", "systemd")
+	if !strings.Contains(unit, "RestartPreventExitStatus="+strconv.Itoa(CrashLoopExitCode)+"\n") {
+		t.Fatalf("systemd unit should not restart a crash-looping service:\n%s", unit)
+	}
+}
+
+func TestBuilder_CrashLoopRequiresStateDir(t *testing.T) {
+	_, err := NewBuilder("en").WithName("demo").WithCrashLoopDetection(3, time.Minute).BuildWithError()
+	if err == nil || !strings.Contains(err.Error(), "state directory") {
+		t.Fatalf("expected state directory error, got %v", err)
+	}
+	_, err = NewBuilder("en").WithName("demo").WithStateDir(t.TempDir()).WithCrashLoopDetection(0, time.Minute).BuildWithError()
+	if err == nil {
+		t.Fatal("expected invalid MaxFailures to fail")
+	}
+	if _, err := NewBuilder("en").WithName("demo").WithStateDir(t.TempDir()).WithCrashLoopDetection(3, time.Minute).BuildWithError(); err != nil {
+		t.Fatalf("valid crash loop config: %v", err)
+	}
+}
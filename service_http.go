@@ -0,0 +1,327 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 与 Runtime.ShutdownInitial、Runtime.ShutdownGrace 的默认值一致
+const (
+	defaultHTTPShutdownTimeout = 15 * time.Second
+	defaultHTTPShutdownGrace   = 5 * time.Second
+)
+
+// socket 激活传入的首个文件描述符，见 sd_listen_fds(3)
+const listenFDsStart = 3
+
+// shutdownBudgetKey 运行上下文中保存的 ShutdownInitial 与 ShutdownGrace
+type shutdownBudgetKey struct{}
+
+type shutdownBudget struct {
+	initial, grace time.Duration
+}
+
+// withShutdownBudget 把分级停止时长放入运行上下文，供内置适配器与框架的停止等待保持一致
+func (sm *sManager) withShutdownBudget(ctx context.Context) context.Context {
+	runtime := sm.commands.config.runtime
+	return context.WithValue(ctx, shutdownBudgetKey{}, shutdownBudget{initial: runtime.ShutdownInitial, grace: runtime.ShutdownGrace})
+}
+
+// HTTPOptions HTTP 服务适配器的配置
+type HTTPOptions struct {
+	Name             string        // 服务名称，默认 "http"
+	Listener         net.Listener  // 已打开的监听，如从父进程继承；优先于 socket 激活与 Server.Addr
+	SocketActivation bool          // 存在 systemd socket 激活传入的监听（LISTEN_FDS）时使用它
+	SocketName       string        // socket 激活时按 FileDescriptorName 选择监听，为空取第一个
+	CertFile         string        // 与 KeyFile 一起设置时以 HTTPS 提供服务
+	KeyFile          string        // 证书私钥
+	ShutdownTimeout  time.Duration // Shutdown 等待请求处理完毕的期限，默认取 Runtime.ShutdownInitial
+	ShutdownGrace    time.Duration // 超过期限改用 Close 后，等待处理函数返回的时长，默认取 Runtime.ShutdownGrace
+	OnReady          func(addr net.Addr)
+	OnShutdown       func(report HTTPShutdownReport)
+}
+
+// HTTPShutdownReport 一次停止的结果
+type HTTPShutdownReport struct {
+	InFlight    int           // 开始停止时正在处理的请求数
+	Interrupted int           // 超过 ShutdownTimeout 被强制关闭连接时仍在处理的请求数
+	Abandoned   int           // 宽限期结束后仍未返回的处理函数数
+	Forced      bool          // 是否因超时调用了 Close
+	Duration    time.Duration // 停止耗时
+}
+
+// HTTPRunner 把 *http.Server 作为 ServiceRunner 运行，由 HTTPService 创建
+type HTTPRunner struct {
+	server   *http.Server
+	opts     HTTPOptions
+	inFlight atomic.Int64
+	ready    chan struct{}
+
+	mu       sync.Mutex
+	started  bool // http.Server 关闭后不能再次启动，第二次 Run 返回错误
+	listener net.Listener
+	stopCh   chan struct{}
+	done     chan struct{}
+	report   HTTPShutdownReport
+}
+
+// HTTPService 创建 HTTP 服务适配器，配合 Builder.WithServiceRunner 使用。Run 完成监听后即视为就绪，
+// 停止时先以 Shutdown 等待进行中的请求，超过期限再 Close。server.Handler 会被包装以统计进行中的请求；
+// http.Server 关闭后不能再次启动，每个适配器只能运行一次。
+func HTTPService(server *http.Server, opts HTTPOptions) *HTTPRunner {
+	if opts.Name == "" {
+		opts.Name = "http"
+	}
+	r := &HTTPRunner{server: server, opts: opts, ready: make(chan struct{})}
+	if server != nil {
+		handler := server.Handler
+		if handler == nil {
+			handler = http.DefaultServeMux
+		}
+		server.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.inFlight.Add(1)
+			defer r.inFlight.Add(-1)
+			handler.ServeHTTP(w, req)
+		})
+	}
+	return r
+}
+
+// Name 返回服务名称
+func (r *HTTPRunner) Name() string {
+	return r.opts.Name
+}
+
+// Ready 返回监听建立后关闭的通道
+func (r *HTTPRunner) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// Addr 返回实际监听地址，就绪前返回 nil
+func (r *HTTPRunner) Addr() net.Addr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listener == nil {
+		return nil
+	}
+	return r.listener.Addr()
+}
+
+// InFlight 返回正在处理的请求数
+func (r *HTTPRunner) InFlight() int {
+	return int(r.inFlight.Load())
+}
+
+// LastShutdown 返回最近一次停止的结果
+func (r *HTTPRunner) LastShutdown() HTTPShutdownReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report
+}
+
+// Run 建立监听并提供服务，直到 ctx 取消或调用 Stop。请求在期限内处理完毕时返回 nil，
+// 被强制关闭时返回 ErrServiceTimeout，Context 中带有进行中的请求数。
+func (r *HTTPRunner) Run(ctx context.Context) error {
+	if r.server == nil {
+		return NewError(ErrServiceStart).Service(r.Name()).Operation("listen").Message("http server is nil").Build()
+	}
+	r.mu.Lock()
+	started := r.started
+	r.started = true
+	r.mu.Unlock()
+	if started {
+		return NewError(ErrServiceStart).Service(r.Name()).Operation("listen").Message("http runner can only run once").Build()
+	}
+
+	listener, err := r.listen()
+	if err != nil {
+		// 监听失败时服务器尚未启动，允许重试
+		r.mu.Lock()
+		r.started = false
+		r.mu.Unlock()
+		return NewError(ErrServiceStart).
+			Service(r.Name()).
+			Operation("listen").
+			Message("failed to listen").
+			Cause(err).
+			Context("addr", r.server.Addr).
+			Build()
+	}
+
+	stopCh, done := make(chan struct{}), make(chan struct{})
+	r.mu.Lock()
+	r.listener, r.stopCh, r.done = listener, stopCh, done
+	r.mu.Unlock()
+	defer close(done)
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- r.serve(listener) }()
+	if r.opts.OnReady != nil {
+		r.opts.OnReady(listener.Addr())
+	}
+	close(r.ready)
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return WrapServiceOperationError(err, ErrRuntime, "serve", r.Name())
+	case <-ctx.Done():
+	case <-stopCh:
+	}
+	return r.shutdown(ctx, serveErr)
+}
+
+// Stop 请求停止并等待 Run 返回，可重复调用
+func (r *HTTPRunner) Stop() error {
+	r.mu.Lock()
+	stopCh, done := r.stopCh, r.done
+	if stopCh != nil {
+		select {
+		case <-stopCh:
+		default:
+			close(stopCh)
+		}
+	}
+	r.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return nil
+}
+
+// listen 按 Listener、socket 激活、Server.Addr 的顺序取得监听
+func (r *HTTPRunner) listen() (net.Listener, error) {
+	if r.opts.Listener != nil {
+		return r.opts.Listener, nil
+	}
+	if r.opts.SocketActivation {
+		if listener, err := activatedListener(r.opts.SocketName); listener != nil || err != nil {
+			return listener, err
+		}
+	}
+	addr := r.server.Addr
+	if addr == "" {
+		addr = ":http"
+		if r.tls() {
+			addr = ":https"
+		}
+	}
+	return net.Listen("tcp", addr)
+}
+
+func (r *HTTPRunner) tls() bool {
+	if r.opts.CertFile != "" && r.opts.KeyFile != "" {
+		return true
+	}
+	config := r.server.TLSConfig
+	return config != nil && (len(config.Certificates) > 0 || config.GetCertificate != nil)
+}
+
+func (r *HTTPRunner) serve(listener net.Listener) error {
+	if r.tls() {
+		return r.server.ServeTLS(listener, r.opts.CertFile, r.opts.KeyFile)
+	}
+	return r.server.Serve(listener)
+}
+
+// shutdown 先以 Shutdown 等待请求处理完毕，超过期限后 Close 并在宽限期内等待处理函数返回
+func (r *HTTPRunner) shutdown(ctx context.Context, serveErr <-chan error) error {
+	timeout, grace := r.shutdownTimeouts(ctx)
+	start := time.Now()
+	report := HTTPShutdownReport{InFlight: r.InFlight()}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := r.server.Shutdown(shutdownCtx)
+	cancel()
+	// 监听关闭失败等其他错误不影响等待结果，只有超时才改用 Close
+	if errors.Is(err, context.DeadlineExceeded) {
+		report.Forced = true
+		report.Interrupted = r.InFlight()
+		_ = r.server.Close()
+		report.Abandoned = r.waitHandlers(grace)
+	}
+	<-serveErr
+	report.Duration = time.Since(start)
+
+	r.mu.Lock()
+	r.report = report
+	r.mu.Unlock()
+	if r.opts.OnShutdown != nil {
+		r.opts.OnShutdown(report)
+	}
+
+	if !report.Forced {
+		return nil
+	}
+	return NewError(ErrServiceTimeout).
+		Service(r.Name()).
+		Operation("shutdown").
+		Messagef("%d requests still in flight after %v, connections closed", report.Interrupted, timeout).
+		Cause(err).
+		Context("in_flight", report.InFlight).
+		Context("interrupted", report.Interrupted).
+		Context("abandoned", report.Abandoned).
+		Build()
+}
+
+// shutdownTimeouts 选项优先，其次取运行上下文中的 ShutdownInitial 与 ShutdownGrace
+func (r *HTTPRunner) shutdownTimeouts(ctx context.Context) (timeout, grace time.Duration) {
+	timeout, grace = defaultHTTPShutdownTimeout, defaultHTTPShutdownGrace
+	if budget, ok := ctx.Value(shutdownBudgetKey{}).(shutdownBudget); ok {
+		if budget.initial > 0 {
+			timeout = budget.initial
+		}
+		if budget.grace > 0 {
+			grace = budget.grace
+		}
+	}
+	if r.opts.ShutdownTimeout > 0 {
+		timeout = r.opts.ShutdownTimeout
+	}
+	if r.opts.ShutdownGrace > 0 {
+		grace = r.opts.ShutdownGrace
+	}
+	return timeout, grace
+}
+
+// waitHandlers 等待处理函数返回，返回宽限期结束时仍在运行的数量
+func (r *HTTPRunner) waitHandlers(grace time.Duration) int {
+	deadline := time.Now().Add(grace)
+	for r.InFlight() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return r.InFlight()
+}
+
+// activatedListener 返回 systemd socket 激活传入的监听：name 为空取第一个，否则按 LISTEN_FDNAMES 匹配。
+// 当前进程没有被激活时返回 nil
+func activatedListener(name string) (net.Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := range count {
+		if name != "" && (i >= len(names) || names[i] != name) {
+			continue
+		}
+		file := os.NewFile(uintptr(listenFDsStart+i), "listen-fd-"+strconv.Itoa(i))
+		listener, err := net.FileListener(file)
+		_ = file.Close()
+		return listener, err
+	}
+	return nil, errors.New("socket activation: no listener named " + name)
+}
@@ -0,0 +1,167 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// startHTTPService 在本地随机端口运行适配器，handler 收到请求后先通知 entered，再等待 release
+func startHTTPService(t *testing.T, ctx context.Context, opts HTTPOptions) (*HTTPRunner, chan struct{}, chan struct{}, <-chan error) {
+	t.Helper()
+	entered, release := make(chan struct{}, 4), make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		_, _ = io.WriteString(w, "ok")
+	})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	opts.Listener = listener
+	svc := HTTPService(server, opts)
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+	select {
+	case <-svc.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("http service not ready")
+	}
+	return svc, entered, release, done
+}
+
+// request 在后台发起请求，返回响应状态或错误
+func request(svc *HTTPRunner) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + svc.Addr().String() + "/")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		result <- err
+	}()
+	return result
+}
+
+func TestHTTPService_DrainsInFlightRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ready net.Addr
+	reports := make(chan HTTPShutdownReport, 1)
+	svc, entered, release, done := startHTTPService(t, ctx, HTTPOptions{
+		OnReady:    func(addr net.Addr) { ready = addr },
+		OnShutdown: func(report HTTPShutdownReport) { reports <- report },
+	})
+	if ready == nil || ready.String() != svc.Addr().String() {
+		t.Fatalf("OnReady should receive the listening address, got %v", ready)
+	}
+
+	result := request(svc)
+	<-entered
+	cancel()
+	// 停止开始后不再接受新连接
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", svc.Addr().String())
+		if err != nil {
+			break
+		}
+		_ = conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("listener should be closed during shutdown")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("graceful shutdown should return nil, got %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Fatalf("in-flight request should complete: %v", err)
+	}
+	report := <-reports
+	if report.InFlight != 1 || report.Forced || report != svc.LastShutdown() {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestHTTPService_ForcesCloseAfterDeadline(t *testing.T) {
+	// 未设置选项时取运行上下文中的 ShutdownInitial 与 ShutdownGrace
+	ctx := context.WithValue(context.Background(), shutdownBudgetKey{}, shutdownBudget{initial: 50 * time.Millisecond, grace: 50 * time.Millisecond})
+	svc, entered, release, done := startHTTPService(t, ctx, HTTPOptions{Name: "api"})
+	defer close(release)
+
+	result := request(svc)
+	<-entered
+	stopped := make(chan error, 1)
+	go func() { stopped <- svc.Stop() }()
+
+	err := <-done
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrServiceTimeout || serviceErr.Service != "api" {
+		t.Fatalf("expected SERVICE_TIMEOUT, got %#v", err)
+	}
+	if serviceErr.Context["in_flight"] != 1 || serviceErr.Context["interrupted"] != 1 || serviceErr.Context["abandoned"] != 1 {
+		t.Fatalf("unexpected request counts: %v", serviceErr.Context)
+	}
+	if report := svc.LastShutdown(); !report.Forced || report.Duration < 100*time.Millisecond {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := <-result; err == nil {
+		t.Fatal("interrupted request should fail on the client")
+	}
+}
+
+func TestHTTPService_ListenFailure(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = occupied.Close() }()
+
+	err = HTTPService(&http.Server{Addr: occupied.Addr().String()}, HTTPOptions{}).Run(context.Background())
+	if !IsErrorCode(err, ErrServiceStart) {
+		t.Fatalf("expected SERVICE_START, got %v", err)
+	}
+}
+
+func TestHTTPService_SecondRunReturnsError(t *testing.T) {
+	runner := HTTPService(&http.Server{Addr: "127.0.0.1:0"}, HTTPOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- runner.Run(ctx) }()
+	<-runner.Ready()
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	if err := runner.Run(context.Background()); !IsErrorCode(err, ErrServiceStart) {
+		t.Fatalf("expected SERVICE_START on reuse, got %v", err)
+	}
+}
+
+func TestActivatedListener(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "web")
+	if listener, err := activatedListener(""); listener != nil || err != nil {
+		t.Fatalf("listeners for another process must be ignored, got %v/%v", listener, err)
+	}
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	if _, err := activatedListener("api"); err == nil {
+		t.Fatal("a missing named listener should be an error")
+	}
+}
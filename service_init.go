@@ -0,0 +1,86 @@
+package zcli
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// initChildEnvVar 由 init 模式拉起的服务进程通过该变量获知自己是子进程
+const initChildEnvVar = "ZCLI_INIT_CHILD"
+
+// InitMode 容器 init（PID 1）模式
+type InitMode int
+
+const (
+	InitModeAuto InitMode = iota // 进程号为 1 时启用
+	InitModeOn                   // 始终启用，适用于由 tini 等以外方式拉起的容器入口
+	InitModeOff                  // 始终关闭
+)
+
+// initChild 报告当前进程是否由 init 模式拉起，首次调用时读取并清除环境变量，
+// 避免业务代码再拉起的子进程误认为自己是服务进程
+var initChild = sync.OnceValue(func() bool {
+	if os.Getenv(initChildEnvVar) == "" {
+		return false
+	}
+	_ = os.Unsetenv(initChildEnvVar)
+	return true
+})
+
+// initMode 报告是否以容器 init 模式运行：没有服务管理器，run 按前台方式执行
+func (sm *sManager) initMode() bool {
+	switch sm.commands.config.runtime.InitMode {
+	case InitModeOn:
+		return true
+	case InitModeOff:
+		return false
+	}
+	return sm.sys.pid() == 1 || initChild()
+}
+
+// initSupervisorRequired 报告 run 是否需要先充当 init：当前进程负责回收与转发信号，服务在子进程中运行
+func (sm *sManager) initSupervisorRequired() bool {
+	return initSupervisorSupported && sm.initMode() && !initChild()
+}
+
+// runInitSupervisor 以与服务管理器相同的方式拉起服务进程，转发信号并回收孤儿进程，
+// 服务进程退出后以其退出码结束
+func (sm *sManager) runInitSupervisor(args []string) error {
+	exe, err := executablePath()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrExecutableInvalid, "run")
+	}
+	child := exec.Command(exe, sm.runArguments(args)...)
+	child.Env = append(os.Environ(), initChildEnvVar+"=1")
+	if sm.instance != "" {
+		child.Env = append(child.Env, instanceEnvVar+"="+sm.instance)
+	}
+	child.Stdin, child.Stdout, child.Stderr = os.Stdin, os.Stdout, os.Stderr
+	child.Dir = sm.config.WorkingDirectory
+
+	code, err := superviseInit(child)
+	if err != nil {
+		return sm.wrapServiceError(err, ErrServiceStart, "run")
+	}
+	if code != 0 {
+		exitFunc(code)
+	}
+	return nil
+}
+
+// restrictInitModeCommands 容器中没有服务管理器，隐藏并禁用依赖它的命令
+func (sm *sManager) restrictInitModeCommands(cmds ...*cobra.Command) {
+	if !sm.initMode() {
+		return
+	}
+	for _, cmd := range cmds {
+		name := cmd.Name()
+		cmd.Hidden = true
+		cmd.RunE = func(*cobra.Command, []string) error {
+			return sm.handleError(ErrUnsupportedInInitMode(sm.Name(), name))
+		}
+	}
+}
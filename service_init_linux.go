@@ -0,0 +1,95 @@
+//go:build linux
+
+package zcli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// initSupervisorSupported 当前平台支持 init 模式下的回收与信号转发
+const initSupervisorSupported = true
+
+// prSetChildSubreaper prctl(PR_SET_CHILD_SUBREAPER)
+const prSetChildSubreaper = 36
+
+// initForwardSignals 转发给服务进程组的信号
+var initForwardSignals = []os.Signal{
+	syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// superviseInit 在独立进程组中启动 child 并充当 init：转发信号到该进程组、回收所有退出的子进程，
+// child 退出后通知组内残留进程并返回其退出码。非 PID 1 时先成为子进程收养者，孤儿进程同样由当前进程回收。
+func superviseInit(child *exec.Cmd) (int, error) {
+	if os.Getpid() != 1 {
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0); errno != 0 {
+			return 0, errno
+		}
+	}
+
+	// 先注册再启动，子进程很快退出时也不会漏掉 SIGCHLD
+	signals := make(chan os.Signal, 16)
+	signal.Notify(signals, append(initForwardSignals, syscall.SIGCHLD)...)
+	defer signal.Stop(signals)
+
+	child.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if tty, ok := foregroundTTY(); ok {
+		// 终端中交互运行时把服务进程组设为前台，Ctrl+C 等终端信号与终端读写都直接交给服务
+		child.SysProcAttr.Foreground = true
+		child.SysProcAttr.Ctty = tty
+	}
+	if err := child.Start(); err != nil {
+		return 0, err
+	}
+	pid := child.Process.Pid
+
+	for sig := range signals {
+		if sig != syscall.SIGCHLD {
+			_ = syscall.Kill(-pid, sig.(syscall.Signal))
+			continue
+		}
+		if code, exited := reapChildren(pid); exited {
+			_ = syscall.Kill(-pid, syscall.SIGTERM)
+			return code, nil
+		}
+	}
+	return 0, nil
+}
+
+// foregroundTTY 当标准输入是控制终端且当前进程组位于前台时返回其描述符
+func foregroundTTY() (int, bool) {
+	fd := int(os.Stdin.Fd())
+	var pgrp int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&pgrp))); errno != 0 {
+		return 0, false
+	}
+	return fd, int(pgrp) == syscall.Getpgrp()
+}
+
+// reapChildren 回收所有已退出的子进程，mainPID 退出时返回其退出码
+func reapChildren(mainPID int) (code int, exited bool) {
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil || pid <= 0 {
+			return code, exited
+		}
+		if pid != mainPID {
+			continue
+		}
+		exited = true
+		switch {
+		case status.Exited():
+			code = status.ExitStatus()
+		case status.Signaled():
+			code = 128 + int(status.Signal())
+		}
+	}
+}
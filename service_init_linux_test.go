@@ -0,0 +1,103 @@
+//go:build linux
+
+package zcli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	service "github.com/darkit/daemon"
+)
+
+// superviseScript 在 dir 中以 init 方式运行 shell 脚本，返回退出码通道
+func superviseScript(t *testing.T, dir, script string) <-chan int {
+	t.Helper()
+	child := exec.Command("/bin/sh", "-c", script)
+	child.Dir = dir
+	done := make(chan int, 1)
+	go func() {
+		code, err := superviseInit(child)
+		if err != nil {
+			t.Errorf("superviseInit: %v", err)
+		}
+		done <- code
+	}()
+	return done
+}
+
+func waitFile(t *testing.T, path string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+			return strings.TrimSpace(string(data))
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not written", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func waitCode(t *testing.T, done <-chan int) int {
+	t.Helper()
+	select {
+	case code := <-done:
+		return code
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not return")
+		return 0
+	}
+}
+
+func TestSuperviseInit_ForwardsSignalsToProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	done := superviseScript(t, dir, `trap 'exit 7' TERM; echo ready > ready; while :; do sleep 0.02; done`)
+	waitFile(t, filepath.Join(dir, "ready"))
+
+	// 发给当前进程的终止信号由 init 转发给服务进程组
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("send SIGTERM: %v", err)
+	}
+	if code := waitCode(t, done); code != 7 {
+		t.Fatalf("exit code = %d, want 7", code)
+	}
+}
+
+func TestSuperviseInit_ReapsOrphans(t *testing.T) {
+	dir := t.TempDir()
+	// 子 shell 退出后 sleep 成为孤儿，由 init 收养并回收
+	done := superviseScript(t, dir, `(sleep 0.05 & echo $! > orphan); sleep 0.5; exit 3`)
+	pid, err := strconv.Atoi(waitFile(t, filepath.Join(dir, "orphan")))
+	if err != nil {
+		t.Fatalf("orphan pid: %v", err)
+	}
+	if code := waitCode(t, done); code != 3 {
+		t.Fatalf("exit code = %d, want 3", code)
+	}
+	if _, err := os.Stat("/proc/" + strconv.Itoa(pid)); !os.IsNotExist(err) {
+		t.Fatalf("orphan %d should have been reaped, stat: %v", pid, err)
+	}
+}
+
+func TestRunInitSupervisor_StartsInWorkingDirectory(t *testing.T) {
+	prev := executablePath
+	executablePath = func() (string, error) { return "/bin/sh", nil }
+	t.Cleanup(func() { executablePath = prev })
+
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	dir := t.TempDir()
+	sm.config = &service.Config{Name: sm.Name(), WorkingDirectory: dir}
+	if err := sm.runInitSupervisor([]string{"-c", "pwd > cwd"}); err != nil {
+		t.Fatalf("runInitSupervisor: %v", err)
+	}
+	if got := waitFile(t, filepath.Join(dir, "cwd")); got != dir {
+		t.Fatalf("service should start in %s, got %s", dir, got)
+	}
+}
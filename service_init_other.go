@@ -0,0 +1,17 @@
+//go:build !linux
+
+package zcli
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+// initSupervisorSupported 当前平台由 run 直接前台运行，不做回收与信号转发
+const initSupervisorSupported = false
+
+// superviseInit 当前平台不支持 init 模式下的回收与信号转发
+func superviseInit(*exec.Cmd) (int, error) {
+	return 0, errors.New("init supervisor not supported on " + runtime.GOOS)
+}
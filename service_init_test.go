@@ -0,0 +1,80 @@
+package zcli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func useProcessID(sm *sManager, pid int) {
+	sm.sys.pid = func() int { return pid }
+}
+
+func TestInitMode_Detection(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	cases := []struct {
+		mode InitMode
+		pid  int
+		want bool
+	}{
+		{InitModeAuto, 1, true},
+		{InitModeAuto, 4242, false},
+		{InitModeOn, 4242, true},
+		{InitModeOff, 1, false},
+	}
+	for _, tc := range cases {
+		useProcessID(sm, tc.pid)
+		sm.commands.config.runtime.InitMode = tc.mode
+		if got := sm.initMode(); got != tc.want {
+			t.Errorf("mode=%d pid=%d: initMode() = %v, want %v", tc.mode, tc.pid, got, tc.want)
+		}
+	}
+}
+
+func TestInitMode_RestrictsServiceManagerCommands(t *testing.T) {
+	build := func(mode InitMode) *Cli {
+		cli, err := NewBuilder("en").
+			WithName("demo").
+			WithInitMode(mode).
+			WithService(func(context.Context) error { return nil }).
+			BuildWithError()
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		return cli
+	}
+
+	cli := build(InitModeOn)
+	for _, name := range []string{"install", "uninstall", "start", "stop", "restart"} {
+		cmd, _, err := cli.Find([]string{name})
+		if err != nil || cmd.Name() != name {
+			t.Fatalf("find %s: %v", name, err)
+		}
+		if !cmd.Hidden {
+			t.Errorf("%s should be hidden in init mode", name)
+		}
+		if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrInitModeUnsupported) || !strings.Contains(err.Error(), name) {
+			t.Errorf("%s should be rejected in init mode, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"run", "status", "logs"} {
+		if cmd, _, _ := cli.Find([]string{name}); cmd == nil || cmd.Hidden {
+			t.Errorf("%s should stay available in init mode", name)
+		}
+	}
+
+	if cmd, _, _ := build(InitModeOff).Find([]string{"install"}); cmd.Hidden {
+		t.Fatal("install should be visible outside init mode")
+	}
+}
+
+func TestDoctor_InitModeNeedsNoBackend(t *testing.T) {
+	sm := newDoctorTestManager(t)
+	useServiceSystem(sm, nil)
+	sm.commands.config.runtime.InitMode = InitModeOn
+
+	report := sm.runDoctor(context.Background())
+	if report.Checks[0].Check != "backend" || report.Checks[0].Status != doctorPass {
+		t.Fatalf("backend should pass in init mode: %+v", report.Checks[0])
+	}
+}
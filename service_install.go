@@ -0,0 +1,210 @@
+package zcli
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// installOverrides 描述 install 命令行对构建期 ServiceConfig 的覆盖项。
+// 它们在安装时叠加到 Builder 生成的配置之上，不修改 Cli 持有的原始配置。
+type installOverrides struct {
+	env          []string
+	envFiles     []string
+	workDir      string
+	user         string
+	args         []string
+	executable   string
+	dependencies []string
+}
+
+// bindInstallFlags 为 install 命令注册覆盖参数。
+func (sm *sManager) bindInstallFlags(cmd *cobra.Command, o *installOverrides) {
+	flags := cmd.Flags()
+	flags.StringArrayVar(&o.env, "env", nil, sm.localizer.GetFlag("env"))
+	flags.StringArrayVar(&o.envFiles, "env-file", nil, sm.localizer.GetFlag("envFile"))
+	flags.StringVar(&o.workDir, "workdir", "", sm.localizer.GetFlag("workDir"))
+	flags.StringVar(&o.user, "user", "", sm.localizer.GetFlag("user"))
+	flags.StringArrayVar(&o.args, "arg", nil, sm.localizer.GetFlag("arg"))
+	flags.StringVar(&o.executable, "executable", "", sm.localizer.GetFlag("executable"))
+	flags.StringArrayVar(&o.dependencies, "dependency", nil, sm.localizer.GetFlag("dependency"))
+}
+
+// empty 判断是否未提供任何覆盖项
+func (o *installOverrides) empty() bool {
+	return len(o.env) == 0 &&
+		len(o.envFiles) == 0 &&
+		o.workDir == "" &&
+		o.user == "" &&
+		len(o.args) == 0 &&
+		o.executable == "" &&
+		len(o.dependencies) == 0
+}
+
+// apply 将覆盖项合并到 base 的副本上并返回。
+// 环境变量按 构建期配置 → --env-file（按顺序）→ --env 的顺序覆盖。
+func (o *installOverrides) apply(base ServiceConfig, localizer *ServiceLocalizer) (ServiceConfig, error) {
+	cfg := cloneService(&base)
+
+	if o.workDir != "" {
+		cfg.WorkDir = o.workDir
+	}
+	if o.user != "" {
+		cfg.Username = o.user
+	}
+	if o.executable != "" {
+		cfg.Executable = o.executable
+	}
+	if len(o.args) > 0 {
+		cfg.Arguments = append([]string(nil), o.args...)
+	}
+
+	if len(o.envFiles) > 0 || len(o.env) > 0 {
+		if cfg.EnvVars == nil {
+			cfg.EnvVars = make(map[string]string)
+		}
+	}
+	for _, path := range o.envFiles {
+		vars, err := parseEnvFile(path, localizer)
+		if err != nil {
+			return cfg, err
+		}
+		maps.Copy(cfg.EnvVars, vars)
+	}
+	for _, kv := range o.env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return cfg, fmt.Errorf("%s", localizer.FormatError("invalidEnv", kv))
+		}
+		cfg.EnvVars[strings.TrimSpace(key)] = value
+	}
+
+	for _, raw := range o.dependencies {
+		dep, err := parseDependencyFlag(raw, localizer)
+		if err != nil {
+			return cfg, err
+		}
+		// 与旧式 Dependencies 并存时同样保留依赖类型
+		cfg.StructuredDeps = append(cfg.StructuredDeps, dep)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// parseDependencyFlag 解析 name[:type] 形式的依赖参数，默认类型为 require。
+func parseDependencyFlag(raw string, localizer *ServiceLocalizer) (Dependency, error) {
+	name, depType, hasType := strings.Cut(strings.TrimSpace(raw), ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Dependency{}, fmt.Errorf("%s", localizer.FormatError("invalidDepend", raw))
+	}
+	if !hasType {
+		return Dependency{Name: name, Type: DependencyRequire}, nil
+	}
+
+	switch DependencyType(strings.ToLower(strings.TrimSpace(depType))) {
+	case DependencyAfter:
+		return Dependency{Name: name, Type: DependencyAfter}, nil
+	case DependencyBefore:
+		return Dependency{Name: name, Type: DependencyBefore}, nil
+	case DependencyRequire:
+		return Dependency{Name: name, Type: DependencyRequire}, nil
+	case DependencyWant:
+		return Dependency{Name: name, Type: DependencyWant}, nil
+	default:
+		return Dependency{}, fmt.Errorf("%s", localizer.FormatError("invalidDepend", raw))
+	}
+}
+
+// resolveInstallTarget 根据覆盖项与环境变量文件生成本次安装使用的 daemon 服务实例。
+// 两者都没有时直接复用管理器当前的服务实例。
+func (sm *sManager) resolveInstallTarget(o *installOverrides) (service.Service, *service.Config, error) {
+	base, err := sm.baseServiceConfig()
+	if err != nil {
+		return nil, nil, WrapServiceOperationError(err, ErrConfigInvalid, "install", sm.Name())
+	}
+	if (o == nil || o.empty()) && len(base.EnvFiles) == 0 {
+		if sm.service == nil {
+			svc, err := newDaemonService(sm.buildRunner(), sm.config)
+			if err != nil {
+				return nil, nil, WrapError(err, ErrServiceCreate, "install")
+			}
+			sm.service = svc
+		}
+		return sm.service, sm.config, nil
+	}
+
+	svcCfg := base
+	if o != nil && !o.empty() {
+		if svcCfg, err = o.apply(base, sm.localizer); err != nil {
+			return nil, nil, NewError(ErrConfigInvalid).
+				Service(sm.Name()).
+				Operation("install").
+				Message(err.Error()).
+				Cause(err).
+				Build()
+		}
+	}
+	if err := sm.resolveEnvFiles(&svcCfg); err != nil {
+		return nil, nil, WrapServiceOperationError(err, ErrConfigInvalid, "install", svcCfg.Name)
+	}
+
+	config, err := sm.buildServiceConfig(svcCfg)
+	if err != nil {
+		return nil, nil, WrapServiceOperationError(err, ErrConfigInvalid, "install", svcCfg.Name)
+	}
+	sm.applyRuntimeOptions(config)
+
+	svc, err := newDaemonService(sm.buildRunner(), config)
+	if err != nil {
+		return nil, nil, WrapServiceOperationError(err, ErrServiceCreate, "install", config.Name)
+	}
+	return svc, config, nil
+}
+
+// logInstallSummary 输出安装后生效的服务配置摘要。
+// 环境变量只展示键名，避免把敏感值写入终端或日志。
+func (sm *sManager) logInstallSummary(config *service.Config) {
+	if config == nil {
+		return
+	}
+
+	sm.localizer.LogDetail("name", config.Name)
+	sm.localizer.LogDetail("executable", config.Executable)
+	if config.WorkingDirectory != "" {
+		sm.localizer.LogDetail("workDir", config.WorkingDirectory)
+	}
+	if config.UserName != "" {
+		sm.localizer.LogDetail("user", config.UserName)
+	}
+	if len(config.Arguments) > 0 {
+		sm.localizer.LogDetail("arguments", strings.Join(config.Arguments, " "))
+	}
+	if len(config.EnvVars) > 0 {
+		keys := make([]string, 0, len(config.EnvVars))
+		for key := range config.EnvVars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sm.localizer.LogDetail("envVars", strings.Join(keys, ", "))
+	}
+
+	deps := append([]string(nil), config.Dependencies...)
+	for _, dep := range config.StructuredDeps {
+		if dep.Type == "" {
+			deps = append(deps, dep.Name)
+			continue
+		}
+		deps = append(deps, fmt.Sprintf("%s(%s)", dep.Name, dep.Type))
+	}
+	if len(deps) > 0 {
+		sm.localizer.LogDetail("dependencies", strings.Join(deps, ", "))
+	}
+}
@@ -0,0 +1,147 @@
+package zcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+func TestInstallOverrides_MergeOnTopOfBuiltConfig(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "app.env")
+	content := "# comment\nFROM_FILE=1\nSHARED=file\n"
+	if err := os.WriteFile(envFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+
+	base := ServiceConfig{
+		Name:           "demo",
+		DisplayName:    "Demo",
+		WorkDir:        "/srv/demo",
+		Arguments:      []string{"run"},
+		EnvVars:        map[string]string{"BUILT": "yes", "SHARED": "built"},
+		StructuredDeps: []Dependency{{Name: "network", Type: DependencyAfter}},
+	}
+	overrides := &installOverrides{
+		env:          []string{"SHARED=flag", "EXTRA=a=b"},
+		envFiles:     []string{envFile},
+		workDir:      "/var/lib/demo",
+		user:         "demo",
+		args:         []string{"serve", "--port", "8080"},
+		executable:   "/usr/local/bin/demo",
+		dependencies: []string{"postgresql", "redis:want"},
+	}
+
+	localizer := NewServiceLocalizer(NewLanguageManager("en"), nil)
+	cfg, err := overrides.apply(base, localizer)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if cfg.Name != "demo" || cfg.DisplayName != "Demo" {
+		t.Fatalf("overrides must keep the service name, got %q / %q", cfg.Name, cfg.DisplayName)
+	}
+	if cfg.WorkDir != "/var/lib/demo" || cfg.Username != "demo" || cfg.Executable != "/usr/local/bin/demo" {
+		t.Fatalf("expected scalar overrides, got %#v", cfg)
+	}
+	if len(cfg.Arguments) != 3 || cfg.Arguments[0] != "serve" {
+		t.Fatalf("expected arguments to be replaced, got %#v", cfg.Arguments)
+	}
+	wantEnv := map[string]string{"BUILT": "yes", "FROM_FILE": "1", "SHARED": "flag", "EXTRA": "a=b"}
+	for k, v := range wantEnv {
+		if cfg.EnvVars[k] != v {
+			t.Fatalf("expected env %s=%s, got %#v", k, v, cfg.EnvVars)
+		}
+	}
+	if len(cfg.StructuredDeps) != 3 ||
+		cfg.StructuredDeps[1] != (Dependency{Name: "postgresql", Type: DependencyRequire}) ||
+		cfg.StructuredDeps[2] != (Dependency{Name: "redis", Type: DependencyWant}) {
+		t.Fatalf("unexpected dependencies: %#v", cfg.StructuredDeps)
+	}
+	if base.EnvVars["SHARED"] != "built" {
+		t.Fatal("overrides must not mutate the built config")
+	}
+}
+
+func TestInstallOverrides_KeepsDependencyTypeWithLegacyDeps(t *testing.T) {
+	localizer := NewServiceLocalizer(NewLanguageManager("en"), nil)
+	base := ServiceConfig{Name: "demo", Dependencies: []string{"After=network.target"}}
+	cfg, err := (&installOverrides{dependencies: []string{"redis:want"}}).apply(base, localizer)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(cfg.Dependencies) != 1 || len(cfg.StructuredDeps) != 1 || cfg.StructuredDeps[0] != (Dependency{Name: "redis", Type: DependencyWant}) {
+		t.Fatalf("--dependency should keep its type next to legacy deps, got %#v / %#v", cfg.Dependencies, cfg.StructuredDeps)
+	}
+}
+
+func TestInstallOverrides_RejectsInvalidInput(t *testing.T) {
+	localizer := NewServiceLocalizer(NewLanguageManager("en"), nil)
+	base := ServiceConfig{Name: "demo"}
+
+	cases := map[string]*installOverrides{
+		"env":        {env: []string{"NOVALUE"}},
+		"dependency": {dependencies: []string{"db:sometimes"}},
+		"envFile":    {envFiles: []string{filepath.Join(t.TempDir(), "missing.env")}},
+	}
+	for name, overrides := range cases {
+		if _, err := overrides.apply(base, localizer); err == nil {
+			t.Fatalf("%s: expected apply to fail", name)
+		}
+	}
+}
+
+func TestInstallCommand_AppliesFlagOverrides(t *testing.T) {
+	prevGOOS := serviceManagerGOOS
+	prevExecutable := executablePath
+	prevNew := newDaemonService
+	serviceManagerGOOS = "windows"
+	executablePath = func() (string, error) { return "/opt/demo/bin/demo", nil }
+
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	var installed *service.Config
+	newDaemonService = func(_ service.Interface, c *service.Config) (service.Service, error) {
+		installed = c
+		return stub, nil
+	}
+	t.Cleanup(func() {
+		serviceManagerGOOS = prevGOOS
+		executablePath = prevExecutable
+		newDaemonService = prevNew
+	})
+
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--env", "A=1", "--user", "svc"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	if stub.installCalled != 1 {
+		t.Fatalf("expected overridden service to be installed once, got %d", stub.installCalled)
+	}
+	if installed == nil || installed.Name != "test-service" {
+		t.Fatalf("expected the base service name, got %#v", installed)
+	}
+	if installed.UserName != "svc" || installed.EnvVars["A"] != "1" {
+		t.Fatalf("expected user/env overrides, got %#v", installed)
+	}
+	if _, ok := installed.Option["RunWait"]; !ok {
+		t.Fatal("expected runtime options to be applied to the overridden config")
+	}
+}
+
+func TestInstallCommand_InvalidOverrideReturnsConfigError(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--env", "broken"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	err := cmd.RunE(cmd, nil)
+	if !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("expected ErrConfigInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package zcli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// instanceEnvVar 安装实例时写入服务环境，run 据此识别自身实例
+const instanceEnvVar = "ZCLI_INSTANCE"
+
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// instanceTemplateData 是服务配置模板可引用的字段
+type instanceTemplateData struct {
+	Name     string // 基础服务名
+	Instance string // 实例名，未指定实例时为空
+}
+
+// instanceServiceName 返回实例的服务名，如 name@eu1
+func instanceServiceName(name, instance string) string {
+	if instance == "" {
+		return name
+	}
+	return name + "@" + instance
+}
+
+// expandTemplate 展开含 {{ 的字符串模板
+func expandTemplate(text string, data instanceTemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("instance").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// expandInstanceTemplates 展开运行参数、环境变量、环境变量文件、工作目录与布局路径中的实例模板
+func expandInstanceTemplates(cfg *ServiceConfig, data instanceTemplateData) error {
+	var errs []error
+	expand := func(field string, text *string) {
+		value, err := expandTemplate(*text, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+			return
+		}
+		*text = value
+	}
+
+	for i := range cfg.Arguments {
+		expand(fmt.Sprintf("arguments[%d]", i), &cfg.Arguments[i])
+	}
+	for key, value := range cfg.EnvVars {
+		expand("env "+key, &value)
+		cfg.EnvVars[key] = value
+	}
+	for i := range cfg.EnvFiles {
+		expand(fmt.Sprintf("envFiles[%d]", i), &cfg.EnvFiles[i])
+	}
+	expand("workdir", &cfg.WorkDir)
+	expand("stateDir", &cfg.Layout.StateDir.Path)
+	expand("logDir", &cfg.Layout.LogDir.Path)
+	expand("runtimeDir", &cfg.Layout.RuntimeDir.Path)
+	expand("configDir", &cfg.Layout.ConfigDir.Path)
+	return errors.Join(errs...)
+}
+
+// validateInstanceTemplates 以示例实例试展开模板，使语法错误在构建期暴露
+func (sc *ServiceConfig) validateInstanceTemplates() []error {
+	probe := cloneService(sc)
+	if err := expandInstanceTemplates(&probe, instanceTemplateData{Name: sc.Name, Instance: "probe"}); err != nil {
+		return []error{fmt.Errorf("instance template: %w", err)}
+	}
+	return nil
+}
+
+// serviceConfigFor 返回指定实例的服务配置，未指定实例时即为构建期配置；模板展开失败时返回错误
+func (sm *sManager) serviceConfigFor(instance string) (ServiceConfig, error) {
+	name := sm.commands.config.basic.Name
+	svcCfg := cloneService(sm.commands.config.service)
+	svcCfg.Name = instanceServiceName(name, instance)
+	svcCfg.DisplayName = sm.commands.config.basic.DisplayName
+	if svcCfg.DisplayName == "" {
+		svcCfg.DisplayName = name
+	}
+	if instance != "" {
+		svcCfg.DisplayName += " (" + instance + ")"
+	}
+	svcCfg.Description = sm.commands.config.basic.Description
+
+	if err := expandInstanceTemplates(&svcCfg, instanceTemplateData{Name: name, Instance: instance}); err != nil {
+		return svcCfg, fmt.Errorf("%s: %w", svcCfg.Name, err)
+	}
+	if instance != "" {
+		if svcCfg.EnvVars == nil {
+			svcCfg.EnvVars = make(map[string]string)
+		}
+		svcCfg.EnvVars[instanceEnvVar] = instance
+	}
+	return svcCfg, nil
+}
+
+// bindInstanceFlag 为服务命令注册 --instance 参数
+func (sm *sManager) bindInstanceFlag(cmd *cobra.Command) {
+	cmd.Flags().String("instance", "", sm.localizer.GetFlag("instance"))
+}
+
+// selectInstance 按 --instance 或 ZCLI_INSTANCE 切换当前实例，并重建 daemon 服务
+func (sm *sManager) selectInstance(cmd *cobra.Command) error {
+	instance := os.Getenv(instanceEnvVar)
+	if cmd != nil {
+		if flag := cmd.Flags().Lookup("instance"); flag != nil && flag.Changed {
+			instance = flag.Value.String()
+		}
+	}
+	if instance == sm.instance {
+		return nil
+	}
+	if instance != "" && !instanceNamePattern.MatchString(instance) {
+		err := fmt.Errorf("%s", sm.localizer.FormatError("invalidInstance", instance))
+		return WrapError(err, ErrConfigInvalid, "instance")
+	}
+
+	prev, prevErr := sm.instance, sm.configErr
+	sm.instance = instance
+	config, err := sm.loadServiceConfig()
+	if err != nil {
+		sm.instance, sm.configErr = prev, prevErr
+		return WrapServiceOperationError(err, ErrConfigInvalid, "instance", instanceServiceName(sm.commands.config.basic.Name, instance))
+	}
+	sm.applyRuntimeOptions(config)
+	svc, err := newDaemonService(sm.buildRunner(), config)
+	if err != nil {
+		sm.instance, sm.configErr = prev, prevErr
+		return WrapServiceOperationError(err, ErrServiceCreate, "instance", config.Name)
+	}
+	sm.config, sm.service = config, svc
+	return nil
+}
+
+// instanceRegistryDir 返回记录已安装实例的目录：基础服务 StateDir 下的 instances，
+// 未配置 StateDir 时使用默认状态目录，Windows 上为 ProgramData 下的同名目录
+func (sm *sManager) instanceRegistryDir() string {
+	name := sm.commands.config.basic.Name
+	// 实例共用一份登记，按未指定实例展开 StateDir 模板
+	base, _ := sm.serviceConfigFor("")
+	dir := base.Layout.StateDir.Path
+	switch {
+	case dir != "":
+	case serviceManagerGOOS == "windows":
+		dir = filepath.Join(os.Getenv("ProgramData"), name)
+	default:
+		dir = base.Layout.withDefaultStateDir(name).StateDir.Path
+	}
+	return filepath.Join(dir, "instances")
+}
+
+// registerInstance 记录已安装的实例
+func (sm *sManager) registerInstance() error {
+	if sm.instance == "" {
+		return nil
+	}
+	dir := sm.instanceRegistryDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, sm.instance), nil, 0o644)
+}
+
+// unregisterInstance 删除实例记录
+func (sm *sManager) unregisterInstance() error {
+	if sm.instance == "" {
+		return nil
+	}
+	err := os.Remove(filepath.Join(sm.instanceRegistryDir(), sm.instance))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// registeredInstances 返回已记录的实例名
+func (sm *sManager) registeredInstances() ([]string, error) {
+	entries, err := os.ReadDir(sm.instanceRegistryDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var instances []string
+	for _, entry := range entries {
+		if !entry.IsDir() && instanceNamePattern.MatchString(entry.Name()) {
+			instances = append(instances, entry.Name())
+		}
+	}
+	sort.Strings(instances)
+	return instances, nil
+}
+
+// instanceStatus 查询指定实例的状态，不改变当前选中的实例
+func (sm *sManager) instanceStatus(instance string) (string, service.Status, error) {
+	svcCfg, err := sm.serviceConfigFor(instance)
+	if err != nil {
+		return instanceServiceName(sm.commands.config.basic.Name, instance), service.StatusUnknown, err
+	}
+	config, err := sm.translateServiceConfig(svcCfg)
+	if err != nil {
+		return "", service.StatusUnknown, err
+	}
+	svc, err := newDaemonService(sm.buildRunner(), config)
+	if err != nil {
+		return config.Name, service.StatusUnknown, WrapServiceOperationError(err, ErrServiceCreate, "list", config.Name)
+	}
+	status, err := sm.queryStatusOf(svc, config.Name)
+	return config.Name, status, err
+}
+
+// newListCmd 创建列出服务实例命令
+func (sm *sManager) newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: sm.localizer.GetOperation("list"),
+	}
+	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
+		instances, err := sm.registeredInstances()
+		if err != nil {
+			return sm.wrapServiceError(err, ErrServiceStatus, "list")
+		}
+
+		// 未使用实例安装的基础服务同样列出
+		found := 0
+		for _, instance := range append([]string{""}, instances...) {
+			name, status, err := sm.instanceStatus(instance)
+			switch {
+			case err != nil && isNotInstalled(err):
+				if instance == "" {
+					continue
+				}
+				sm.localizer.LogInfo(name, "notInstalled")
+			case err != nil:
+				return err
+			case status == service.StatusRunning:
+				sm.localizer.LogInfo(name, "running")
+			case status == service.StatusStopped:
+				sm.localizer.LogInfo(name, "stopped")
+			default:
+				if instance == "" {
+					continue
+				}
+				sm.localizer.LogInfo(name, "unknown")
+			}
+			found++
+		}
+
+		if found == 0 {
+			sm.localizer.LogWarning("%s", sm.localizer.GetMessage("noInstances"))
+		}
+		return nil
+	})
+	return cmd
+}
@@ -0,0 +1,187 @@
+package zcli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+// newInstanceTestManager 返回按服务名分派 fake daemon 的管理器，并把实例登记目录指向临时目录
+func newInstanceTestManager(t *testing.T, services map[string]*fakeDaemonService) (*sManager, *[]*service.Config) {
+	t.Helper()
+
+	prevNew, prevGOOS := newDaemonService, serviceManagerGOOS
+	serviceManagerGOOS = "windows"
+
+	var created []*service.Config
+	newDaemonService = func(_ service.Interface, config *service.Config) (service.Service, error) {
+		created = append(created, config)
+		if svc, ok := services[config.Name]; ok {
+			return svc, nil
+		}
+		return &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}, nil
+	}
+	t.Cleanup(func() {
+		newDaemonService, serviceManagerGOOS = prevNew, prevGOOS
+	})
+
+	sm := newTestServiceManager(t, services["test-service"])
+	svc := sm.commands.config.service
+	svc.Arguments = []string{"run", "--port={{if eq .Instance \"eu1\"}}8081{{else}}8080{{end}}"}
+	svc.EnvVars = map[string]string{"TENANT": "{{.Instance}}"}
+	svc.WorkDir = "/srv/{{.Name}}/{{.Instance}}"
+	svc.Layout.StateDir.Path = t.TempDir()
+	return sm, &created
+}
+
+func TestServiceConfigFor_ExpandsInstanceTemplates(t *testing.T) {
+	sm, _ := newInstanceTestManager(t, nil)
+
+	cfg, err := sm.serviceConfigFor("eu1")
+	if err != nil {
+		t.Fatalf("serviceConfigFor: %v", err)
+	}
+	if cfg.Name != "test-service@eu1" {
+		t.Fatalf("unexpected instance name %q", cfg.Name)
+	}
+	if cfg.Arguments[1] != "--port=8081" || cfg.WorkDir != "/srv/test-service/eu1" {
+		t.Fatalf("templates not expanded: args=%v workdir=%q", cfg.Arguments, cfg.WorkDir)
+	}
+	if cfg.EnvVars["TENANT"] != "eu1" || cfg.EnvVars[instanceEnvVar] != "eu1" {
+		t.Fatalf("unexpected env: %v", cfg.EnvVars)
+	}
+
+	base, _ := sm.serviceConfigFor("")
+	if base.Name != "test-service" || base.EnvVars["TENANT"] != "" {
+		t.Fatalf("base service should expand with an empty instance: %+v", base)
+	}
+	if _, ok := base.EnvVars[instanceEnvVar]; ok {
+		t.Fatal("base service must not carry the instance env var")
+	}
+}
+
+func TestInstallCommand_InstanceRegistersAndUsesInstanceName(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, created := newInstanceTestManager(t, map[string]*fakeDaemonService{"test-service@eu1": stub})
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--instance", "eu1"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install --instance: %v", err)
+	}
+	if stub.installCalled != 1 {
+		t.Fatalf("expected instance service to be installed once, got %d", stub.installCalled)
+	}
+	last := (*created)[len(*created)-1]
+	if last.Name != "test-service@eu1" || last.WorkingDirectory != "/srv/test-service/eu1" {
+		t.Fatalf("unexpected daemon config: name=%q workdir=%q", last.Name, last.WorkingDirectory)
+	}
+
+	instances, err := sm.registeredInstances()
+	if err != nil || len(instances) != 1 || instances[0] != "eu1" {
+		t.Fatalf("expected eu1 to be registered, got %v, %v", instances, err)
+	}
+}
+
+func TestListCommand_ReportsInstanceStates(t *testing.T) {
+	sm, _ := newInstanceTestManager(t, map[string]*fakeDaemonService{
+		"test-service@eu1": {status: service.StatusRunning},
+		"test-service@eu2": {status: service.StatusStopped},
+	})
+	var out bytes.Buffer
+	sm.localizer.ConfigureOutput(&out, &out, false, false)
+
+	dir := sm.instanceRegistryDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"eu1", "eu2"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("write registry: %v", err)
+		}
+	}
+
+	cmd := sm.newListCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{"test-service@eu1", "test-service@eu2"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("list output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Count(text, "test-service") != 2 {
+		t.Fatalf("base service is not installed and must not be listed:\n%s", text)
+	}
+}
+
+func TestServiceCommand_RejectsInvalidInstance(t *testing.T) {
+	sm, _ := newInstanceTestManager(t, nil)
+
+	cmd := sm.newStatusCmd()
+	if err := cmd.ParseFlags([]string{"--instance", "../etc"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("expected ErrConfigInvalid, got %v", err)
+	}
+	if sm.Name() != "test-service" {
+		t.Fatalf("invalid instance must not change the selected service, got %q", sm.Name())
+	}
+}
+
+func TestBuilder_RejectsInvalidInstanceTemplate(t *testing.T) {
+	_, err := NewBuilder("zh").
+		WithName("tmpl-app").
+		WithArguments("run", "--tenant={{.Tenant}}").
+		BuildWithError()
+	if err == nil || !strings.Contains(err.Error(), "instance template") {
+		t.Fatalf("expected instance template error, got %v", err)
+	}
+}
+
+func TestInstallCommand_InstanceRegistryFailureRollsBack(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, _ := newInstanceTestManager(t, map[string]*fakeDaemonService{"test-service@eu1": stub})
+	if dir := sm.instanceRegistryDir(); filepath.Dir(dir) != sm.commands.config.service.Layout.StateDir.Path {
+		t.Fatalf("registry should live under the state dir, got %q", dir)
+	}
+	// 登记目录位置被普通文件占用，写入必然失败
+	if err := os.WriteFile(sm.instanceRegistryDir(), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.ParseFlags([]string{"--instance", "eu1"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrServiceInstall) {
+		t.Fatalf("expected ErrServiceInstall, got %v", err)
+	}
+	if stub.uninstallCalled != 1 {
+		t.Fatalf("an unregistered instance must be uninstalled again, got %d", stub.uninstallCalled)
+	}
+}
+
+func TestServiceCommand_PropagatesInstanceTemplateErrors(t *testing.T) {
+	sm, _ := newInstanceTestManager(t, nil)
+	sm.commands.config.service.WorkDir = "/srv/{{index .Instance 9}}"
+
+	if _, err := sm.serviceConfigFor("eu1"); err == nil {
+		t.Fatal("template execution errors should be returned")
+	}
+	cmd := sm.newStatusCmd()
+	if err := cmd.ParseFlags([]string{"--instance", "eu1"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("expected ErrConfigInvalid, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -53,11 +54,18 @@ type ServiceConfig struct {
 	Dependencies      []string          `validate:"dive,required"`
 	StructuredDeps    []Dependency      `validate:"dive"`
 	EnvVars           map[string]string `validate:"dive,keys,required,endkeys,required"`
+	EnvFiles          []string          `validate:"dive,required"`
 	Arguments         []string
 	Executable        string
 	ChRoot            string
 	Options           ServiceOptions
 	AllowSudoFallback bool
+	CreateUser        bool
+	CheckDependencies bool
+	Layout            ServiceLayout
+	Systemd           *SystemdOptions
+	Launchd           *LaunchdOptions
+	Windows           *WindowsOptions
 }
 
 // Validate 验证配置的有效性
@@ -83,6 +91,11 @@ func (sc *ServiceConfig) Validate() error {
 			errs = append(errs, fmt.Errorf("dependency[%d] must not be empty", i))
 		}
 	}
+	for i, path := range sc.EnvFiles {
+		if strings.TrimPrefix(path, "-") == "" {
+			errs = append(errs, fmt.Errorf("env file[%d] must not be empty", i))
+		}
+	}
 	for i, dep := range sc.StructuredDeps {
 		if dep.Name == "" {
 			errs = append(errs, fmt.Errorf("structured dependency[%d] name must not be empty", i))
@@ -102,6 +115,10 @@ func (sc *ServiceConfig) Validate() error {
 		}
 	}
 
+	// 验证类型化平台选项
+	errs = append(errs, sc.validatePlatformOptions()...)
+	errs = append(errs, sc.validateInstanceTemplates()...)
+
 	if len(errs) > 0 {
 		return &ValidationError{Errors: errs}
 	}
@@ -340,21 +357,207 @@ type ServiceLifecycle interface {
 	AfterStop() error
 }
 
+// ContextLifecycle 带上下文的服务生命周期接口，runner 实现后 ManagedService 与 ServiceLifecycle 一并调用。
+// 每个阶段的 ctx 带有该阶段的超时，启动阶段还会随 Run 的 ctx 取消；停止阶段收到本次关闭的原因。
+type ContextLifecycle interface {
+	// BeforeStart 服务启动前调用
+	BeforeStart(ctx context.Context) error
+
+	// AfterStart 服务启动后调用
+	AfterStart(ctx context.Context) error
+
+	// BeforeStop 服务停止前调用
+	BeforeStop(ctx context.Context, cause *ShutdownCause) error
+
+	// AfterStop 服务停止后调用
+	AfterStop(ctx context.Context, cause *ShutdownCause) error
+}
+
+// LifecyclePhase 生命周期阶段
+type LifecyclePhase string
+
+const (
+	PhaseBeforeStart LifecyclePhase = "BeforeStart"
+	PhaseAfterStart  LifecyclePhase = "AfterStart"
+	PhaseBeforeStop  LifecyclePhase = "BeforeStop"
+	PhaseAfterStop   LifecyclePhase = "AfterStop"
+)
+
+const (
+	// DefaultLifecycleStartTimeout BeforeStart、AfterStart 的默认超时
+	DefaultLifecycleStartTimeout = 30 * time.Second
+	// DefaultLifecycleStopTimeout BeforeStop、AfterStop 的默认超时
+	DefaultLifecycleStopTimeout = 10 * time.Second
+)
+
+// LifecycleTimeouts ContextLifecycle 各阶段的超时，零值使用默认值，负数表示不限时
+type LifecycleTimeouts struct {
+	BeforeStart time.Duration
+	AfterStart  time.Duration
+	BeforeStop  time.Duration
+	AfterStop   time.Duration
+}
+
+// forPhase 返回阶段的实际超时，0 表示不限时
+func (t LifecycleTimeouts) forPhase(phase LifecyclePhase) time.Duration {
+	timeout, fallback := t.BeforeStart, DefaultLifecycleStartTimeout
+	switch phase {
+	case PhaseAfterStart:
+		timeout = t.AfterStart
+	case PhaseBeforeStop:
+		timeout, fallback = t.BeforeStop, DefaultLifecycleStopTimeout
+	case PhaseAfterStop:
+		timeout, fallback = t.AfterStop, DefaultLifecycleStopTimeout
+	}
+	switch {
+	case timeout == 0:
+		return fallback
+	case timeout < 0:
+		return 0
+	}
+	return timeout
+}
+
 // ManagedService 带生命周期管理的服务
 type ManagedService struct {
 	ServiceRunner
-	lifecycle ServiceLifecycle
-	stopMu    sync.Mutex
-	stopOnce  *sync.Once
-	stopErr   error
-}
-
-// NewManagedService 创建带生命周期管理的服务
+	lifecycle    ServiceLifecycle
+	ctxLifecycle ContextLifecycle
+	pausable     Pausable // 被包装的 runner 实现的暂停接口，嵌入的 ServiceRunner 不会提升 Pause 与 Resume
+	timeouts     LifecycleTimeouts
+	stopMu       sync.Mutex
+	stopOnce     *sync.Once
+	stopErr      error
+}
+
+// NewManagedService 创建带生命周期管理的服务。
+// runner 同时实现 ContextLifecycle 时两者都会调用：启动阶段先调用 runner 的钩子，
+// 停止阶段先调用 lifecycle 的钩子。
 func NewManagedService(runner ServiceRunner, lifecycle ServiceLifecycle) *ManagedService {
-	return &ManagedService{
+	ms := &ManagedService{
 		ServiceRunner: runner,
 		lifecycle:     lifecycle,
 	}
+	ms.pausable, _ = pausableOf(runner)
+	if cl, ok := runner.(ContextLifecycle); ok {
+		ms.ctxLifecycle = cl
+	}
+	return ms
+}
+
+// NewContextManagedService 创建使用 ContextLifecycle 的服务
+func NewContextManagedService(runner ServiceRunner, lifecycle ContextLifecycle) *ManagedService {
+	ms := &ManagedService{
+		ServiceRunner: runner,
+		ctxLifecycle:  lifecycle,
+	}
+	ms.pausable, _ = pausableOf(runner)
+	return ms
+}
+
+// pausableOf 返回服务实现的暂停接口，ManagedService 转发其包装的 runner
+func pausableOf(runner ServiceRunner) (Pausable, bool) {
+	if ms, ok := runner.(*ManagedService); ok {
+		return ms.pausable, ms.pausable != nil
+	}
+	pausable, ok := runner.(Pausable)
+	return pausable, ok
+}
+
+// WithLifecycleTimeouts 设置 ContextLifecycle 各阶段的超时
+func (ms *ManagedService) WithLifecycleTimeouts(timeouts LifecycleTimeouts) *ManagedService {
+	ms.timeouts = timeouts
+	return ms
+}
+
+// callPhase 在阶段超时内执行钩子。钩子未响应 ctx 时也不再等待，
+// 超时返回 ErrLifecycleTimeout，Run 的 ctx 被取消时返回其关闭原因。
+func (ms *ManagedService) callPhase(parent context.Context, phase LifecyclePhase, hook func(context.Context) error) error {
+	timeout := ms.timeouts.forPhase(phase)
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- hook(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+		if err == nil || ctx.Err() == nil {
+			return err
+		}
+	case <-ctx.Done():
+	}
+	if parent.Err() != nil {
+		return shutdownCauseFromContext(parent, nil)
+	}
+	return ErrLifecyclePhaseTimeout(ms.serviceName(), phase, timeout, err)
+}
+
+func (ms *ManagedService) serviceName() string {
+	if ms.ServiceRunner == nil {
+		return ""
+	}
+	return ms.ServiceRunner.Name()
+}
+
+// beforeStart 执行启动前钩子，先 ContextLifecycle 后 ServiceLifecycle，失败即停止
+func (ms *ManagedService) beforeStart(ctx context.Context) error {
+	if ms.ctxLifecycle != nil {
+		if err := ms.callPhase(ctx, PhaseBeforeStart, ms.ctxLifecycle.BeforeStart); err != nil {
+			return err
+		}
+	}
+	if ms.lifecycle != nil {
+		return ms.lifecycle.BeforeStart()
+	}
+	return nil
+}
+
+// afterStart 执行启动后钩子，顺序同 beforeStart
+func (ms *ManagedService) afterStart(ctx context.Context) error {
+	if ms.ctxLifecycle != nil {
+		if err := ms.callPhase(ctx, PhaseAfterStart, ms.ctxLifecycle.AfterStart); err != nil {
+			return err
+		}
+	}
+	if ms.lifecycle != nil {
+		return ms.lifecycle.AfterStart()
+	}
+	return nil
+}
+
+// stopPhase 执行停止钩子，与启动顺序相反，一方失败仍调用另一方。
+// 停止阶段不继承已取消的 Run ctx，只受阶段超时约束。
+func (ms *ManagedService) stopPhase(phase LifecyclePhase, cause *ShutdownCause) error {
+	var errs []error
+	if ms.lifecycle != nil {
+		hook := ms.lifecycle.BeforeStop
+		if phase == PhaseAfterStop {
+			hook = ms.lifecycle.AfterStop
+		}
+		if err := hook(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if ms.ctxLifecycle != nil {
+		hook := ms.ctxLifecycle.BeforeStop
+		if phase == PhaseAfterStop {
+			hook = ms.ctxLifecycle.AfterStop
+		}
+		if err := ms.callPhase(context.Background(), phase, func(ctx context.Context) error {
+			return hook(ctx, cause)
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return CombineErrors(errs...)
 }
 
 // Run 运行带生命周期管理的服务
@@ -362,10 +565,8 @@ func (ms *ManagedService) Run(ctx context.Context) error {
 	ms.resetStopState()
 
 	// 启动前处理
-	if ms.lifecycle != nil {
-		if err := ms.lifecycle.BeforeStart(); err != nil {
-			return fmt.Errorf("before start hook failed: %w", err)
-		}
+	if err := ms.beforeStart(ctx); err != nil {
+		return fmt.Errorf("before start hook failed: %w", err)
 	}
 
 	runCtx, cancel := context.WithCancel(ctx)
@@ -378,17 +579,15 @@ func (ms *ManagedService) Run(ctx context.Context) error {
 	}()
 
 	// 启动后处理
-	if ms.lifecycle != nil {
-		if err := ms.lifecycle.AfterStart(); err != nil {
-			cancel()
-			stopErr := ms.Stop()
-			runErr := <-errChan
-			return CombineErrors(
-				fmt.Errorf("after start hook failed: %w", err),
-				stopErr,
-				runErr,
-			)
-		}
+	if err := ms.afterStart(ctx); err != nil {
+		cancel()
+		stopErr := ms.stop(newShutdownCause(ShutdownReasonServiceStop, nil, err))
+		runErr := <-errChan
+		return CombineErrors(
+			fmt.Errorf("after start hook failed: %w", err),
+			stopErr,
+			runErr,
+		)
 	}
 
 	// 等待服务结束或上下文取消
@@ -401,7 +600,11 @@ func (ms *ManagedService) Run(ctx context.Context) error {
 	case <-ctx.Done():
 		var errs []error
 
-		if err := ms.Stop(); err != nil {
+		cause, ok := GetShutdownCause(ctx)
+		if !ok {
+			cause = newShutdownCause(ShutdownReasonExternalCancel, nil, ctx.Err())
+		}
+		if err := ms.stop(cause); err != nil {
 			errs = append(errs, err)
 		}
 		if runErr := <-errChan; runErr != nil && !isExpectedShutdownError(runErr) {
@@ -419,6 +622,11 @@ func (ms *ManagedService) Run(ctx context.Context) error {
 // 生命周期停止钩子和底层 Stop 在并发关闭路径中只执行一次，避免 Ctrl+C
 // 同时触发 service manager 与 ManagedService 时重复清理。
 func (ms *ManagedService) Stop() error {
+	return ms.stop(newShutdownCause(ShutdownReasonServiceStop, nil, nil))
+}
+
+// stop 执行一次停止流程，cause 传给 ContextLifecycle 的停止钩子
+func (ms *ManagedService) stop(cause *ShutdownCause) error {
 	ms.stopMu.Lock()
 	if ms.stopOnce == nil {
 		ms.stopOnce = &sync.Once{}
@@ -429,10 +637,8 @@ func (ms *ManagedService) Stop() error {
 	once.Do(func() {
 		var errs []error
 
-		if ms.lifecycle != nil {
-			if err := ms.lifecycle.BeforeStop(); err != nil {
-				errs = append(errs, fmt.Errorf("BeforeStop: %w", err))
-			}
+		if err := ms.stopPhase(PhaseBeforeStop, cause); err != nil {
+			errs = append(errs, fmt.Errorf("BeforeStop: %w", err))
 		}
 
 		if ms.ServiceRunner != nil {
@@ -441,10 +647,8 @@ func (ms *ManagedService) Stop() error {
 			}
 		}
 
-		if ms.lifecycle != nil {
-			if err := ms.lifecycle.AfterStop(); err != nil {
-				errs = append(errs, fmt.Errorf("AfterStop: %w", err))
-			}
+		if err := ms.stopPhase(PhaseAfterStop, cause); err != nil {
+			errs = append(errs, fmt.Errorf("AfterStop: %w", err))
 		}
 
 		ms.stopMu.Lock()
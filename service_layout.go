@@ -0,0 +1,333 @@
+package zcli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// ServiceDirectory 描述一个由 zcli 在安装时创建、在 purge 时清理的目录
+type ServiceDirectory struct {
+	Path  string      // 目录路径，必须为绝对路径，空表示不管理
+	Owner string      // 属主用户名，空表示保持当前用户
+	Group string      // 属组名，空表示保持当前属组
+	Mode  os.FileMode // 目录权限，默认 0755
+}
+
+// ServiceLayout 描述安装服务时的受管文件系统布局
+type ServiceLayout struct {
+	StateDir   ServiceDirectory // 持久状态目录，如 /var/lib/<name>
+	LogDir     ServiceDirectory // 日志目录，如 /var/log/<name>
+	RuntimeDir ServiceDirectory // 运行时目录，如 /run/<name>
+	ConfigDir  ServiceDirectory // 配置目录，如 /etc/<name>
+
+	// BinaryPrefix 非空时，install 会把可执行文件复制到该目录，
+	// 使服务定义不再指向构建目录
+	BinaryPrefix string
+}
+
+// DefaultServiceLayout 返回 Linux FHS 风格的默认布局
+func DefaultServiceLayout(name string) ServiceLayout {
+	return ServiceLayout{
+		StateDir:   ServiceDirectory{Path: filepath.Join("/var/lib", name), Mode: 0o750},
+		LogDir:     ServiceDirectory{Path: filepath.Join("/var/log", name), Mode: 0o750},
+		RuntimeDir: ServiceDirectory{Path: filepath.Join("/run", name), Mode: 0o755},
+		ConfigDir:  ServiceDirectory{Path: filepath.Join("/etc", name), Mode: 0o755},
+	}
+}
+
+// directories 按固定顺序返回布局中已配置的目录及其标签
+func (l ServiceLayout) directories() []layoutEntry {
+	entries := []layoutEntry{
+		{label: "stateDir", dir: l.StateDir},
+		{label: "logDir", dir: l.LogDir},
+		{label: "runtimeDir", dir: l.RuntimeDir},
+		{label: "configDir", dir: l.ConfigDir},
+	}
+	configured := entries[:0]
+	for _, entry := range entries {
+		if entry.dir.Path != "" {
+			configured = append(configured, entry)
+		}
+	}
+	return configured
+}
+
+// copiesBinary 报告 install 是否会把 src 复制到 BinaryPrefix；src 已位于目标位置时不复制
+func (l ServiceLayout) copiesBinary(src string) bool {
+	return l.BinaryPrefix != "" && filepath.Join(l.BinaryPrefix, filepath.Base(src)) != filepath.Clean(src)
+}
+
+// IsZero 判断布局是否未配置任何内容
+func (l ServiceLayout) IsZero() bool {
+	return len(l.directories()) == 0 && l.BinaryPrefix == ""
+}
+
+type layoutEntry struct {
+	label string
+	dir   ServiceDirectory
+}
+
+// layoutRollback 记录 install 过程中新建的路径，安装失败时按逆序清理
+type layoutRollback struct {
+	created  []string
+	cleanups []func() error
+}
+
+func (r *layoutRollback) track(path string) {
+	if path != "" {
+		r.created = append(r.created, path)
+	}
+}
+
+// onUndo 注册路径清理之后执行的回滚动作
+func (r *layoutRollback) onUndo(fn func() error) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+// undo 删除本次新建的路径，已存在的路径不受影响
+func (r *layoutRollback) undo() error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	for i := len(r.created) - 1; i >= 0; i-- {
+		if err := os.RemoveAll(r.created[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, fn := range r.cleanups {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.created, r.cleanups = nil, nil
+	return CombineErrors(errs...)
+}
+
+// prepareLayout 创建布局目录并设置属主与权限，必要时复制可执行文件。
+// 返回的 rollback 仅包含本次新建的路径，copied 为本次复制的可执行文件，未复制时为空。
+func (sm *sManager) prepareLayout(layout ServiceLayout, config *service.Config) (rollback *layoutRollback, copied string, err error) {
+	rollback = &layoutRollback{}
+
+	for _, entry := range layout.directories() {
+		if err := sm.ensureServiceDirectory(entry.dir, rollback); err != nil {
+			_ = rollback.undo()
+			return nil, "", fmt.Errorf("%s", sm.localizer.FormatError("layoutFailed", entry.dir.Path, err))
+		}
+	}
+
+	if config != nil && layout.copiesBinary(config.Executable) {
+		dest, err := installBinary(config.Executable, layout.BinaryPrefix, rollback)
+		if err != nil {
+			_ = rollback.undo()
+			return nil, "", fmt.Errorf("%s", sm.localizer.FormatError("layoutFailed", layout.BinaryPrefix, err))
+		}
+		config.Executable, copied = dest, dest
+	}
+
+	return rollback, copied, nil
+}
+
+// ensureServiceDirectory 创建目录并应用属主与权限
+func (sm *sManager) ensureServiceDirectory(dir ServiceDirectory, rollback *layoutRollback) error {
+	if !filepath.IsAbs(dir.Path) {
+		return fmt.Errorf("path must be absolute: %s", dir.Path)
+	}
+
+	mode := dir.Mode
+	if mode == 0 {
+		mode = 0o755
+	}
+
+	rollback.track(firstMissingAncestor(dir.Path))
+	if err := os.MkdirAll(dir.Path, mode); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir.Path, mode); err != nil {
+		return err
+	}
+	return sm.chownPath(dir.Path, dir.Owner, dir.Group)
+}
+
+// firstMissingAncestor 返回 path 及其祖先中最靠上的不存在路径；全部存在时返回空串
+func firstMissingAncestor(path string) string {
+	missing := ""
+	for current := filepath.Clean(path); ; current = filepath.Dir(current) {
+		if _, err := os.Lstat(current); err == nil {
+			return missing
+		}
+		missing = current
+		if parent := filepath.Dir(current); parent == current {
+			return missing
+		}
+	}
+}
+
+// installBinary 将可执行文件复制到 prefix 目录并返回目标路径
+func installBinary(src, prefix string, rollback *layoutRollback) (string, error) {
+	if !filepath.IsAbs(prefix) {
+		return "", fmt.Errorf("path must be absolute: %s", prefix)
+	}
+	dest := filepath.Join(prefix, filepath.Base(src))
+	if filepath.Clean(src) == dest {
+		return dest, nil
+	}
+
+	rollback.track(firstMissingAncestor(prefix))
+	if err := os.MkdirAll(prefix, 0o755); err != nil {
+		return "", err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = in.Close() }()
+
+	if _, err := os.Lstat(dest); os.IsNotExist(err) {
+		rollback.track(dest)
+	}
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return dest, nil
+}
+
+// chownPath 按用户名/组名设置属主，Windows 上忽略
+func (sm *sManager) chownPath(path, owner, group string) error {
+	if serviceManagerGOOS == "windows" || (owner == "" && group == "") {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if owner != "" {
+		u, err := sm.sys.lookupUser(owner)
+		if err != nil {
+			return err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return err
+		}
+		if group == "" {
+			if gid, err = strconv.Atoi(u.Gid); err != nil {
+				return err
+			}
+		}
+	}
+	if group != "" {
+		g, err := sm.sys.lookupGroup(group)
+		if err != nil {
+			return err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return err
+		}
+	}
+	return os.Chown(path, uid, gid)
+}
+
+// systemDirs 即使配置为受管目录也拒绝清理的系统目录
+var systemDirs = map[string]bool{
+	"/usr/bin": true, "/usr/sbin": true, "/usr/lib": true, "/usr/lib64": true, "/usr/share": true,
+	"/usr/local": true, "/usr/local/bin": true, "/usr/local/sbin": true, "/usr/local/lib": true,
+	"/usr/local/share": true, "/usr/local/etc": true,
+	"/var/lib": true, "/var/log": true, "/var/run": true, "/var/cache": true, "/var/spool": true,
+	"/var/tmp": true, "/var/opt": true, "/var/lock": true, "/var/mail": true, "/var/www": true,
+	"/run/user": true, "/run/lock": true, "/etc/systemd": true, "/etc/default": true,
+	"/Library/LaunchDaemons": true, "/Library/LaunchAgents": true, "/private/var": true, "/private/etc": true,
+}
+
+// purgeTargets 返回 uninstall --purge 需要删除的路径：通过检查的受管目录，以及安装记录中 install 复制的可执行文件
+func (sm *sManager) purgeTargets(layout ServiceLayout, record *installRecord) ([]string, error) {
+	names := []string{sm.commands.config.basic.Name, sm.instance}
+	var targets []string
+	for _, entry := range layout.directories() {
+		if err := checkPurgePath(entry.dir.Path, names...); err != nil {
+			return nil, fmt.Errorf("%s: %w", sm.localizer.FormatError("unsafePurge", entry.dir.Path), err)
+		}
+		targets = append(targets, filepath.Clean(entry.dir.Path))
+	}
+	// 只删除 install 实际复制的文件，且仍位于当前 BinaryPrefix 下
+	if record != nil && record.binary != "" && layout.BinaryPrefix != "" &&
+		filepath.Dir(filepath.Clean(record.binary)) == filepath.Clean(layout.BinaryPrefix) {
+		targets = append(targets, filepath.Clean(record.binary))
+	}
+	return targets, nil
+}
+
+// checkPurgePath 拒绝删除相对路径、根目录、一级目录与已知系统目录，
+// 并要求最后一级目录名包含服务名（或实例名），避免误删共享目录
+func checkPurgePath(path string, names ...string) error {
+	clean := filepath.Clean(path)
+	if !filepath.IsAbs(clean) {
+		return fmt.Errorf("path must be absolute: %s", path)
+	}
+	if filepath.Dir(clean) == clean || filepath.Dir(filepath.Dir(clean)) == filepath.Dir(clean) {
+		return fmt.Errorf("refusing to remove top-level path: %s", path)
+	}
+	if systemDirs[filepath.ToSlash(clean)] {
+		return fmt.Errorf("refusing to remove system directory: %s", path)
+	}
+	base := filepath.Base(clean)
+	for _, name := range names {
+		if name != "" && strings.Contains(base, name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to remove %s: last path element does not contain the service name", path)
+}
+
+// confirmPurge 列出将被删除的路径与账号并等待用户确认
+func (sm *sManager) confirmPurge(cmd *cobra.Command, targets []string, account *serviceAccount) bool {
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintln(out, sm.localizer.GetMessage("purgeTargets"))
+	for _, target := range targets {
+		_, _ = fmt.Fprintf(out, "%s%s\n", indent, target)
+	}
+	if account != nil {
+		_, _ = fmt.Fprintf(out, "%s%s: %s\n", indent, sm.localizer.GetLabel("account"), account)
+	}
+	_, _ = fmt.Fprint(out, sm.localizer.GetMessage("purgeConfirm"))
+
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// purgeLayout 删除受管目录与复制的可执行文件
+func purgeLayout(targets []string) error {
+	var errs []error
+	for _, target := range targets {
+		if err := os.RemoveAll(target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return CombineErrors(errs...)
+}
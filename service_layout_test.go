@@ -0,0 +1,212 @@
+package zcli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+func newLayoutTestManager(t *testing.T, stub *fakeDaemonService, layout ServiceLayout) (*sManager, string) {
+	t.Helper()
+
+	exe := filepath.Join(t.TempDir(), "demo")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write executable: %v", err)
+	}
+
+	prevNew := newDaemonService
+	newDaemonService = func(_ service.Interface, _ *service.Config) (service.Service, error) {
+		return stub, nil
+	}
+	t.Cleanup(func() { newDaemonService = prevNew })
+
+	sm := newTestServiceManager(t, stub)
+	sm.commands.config.service.Layout = layout
+	sm.config = &service.Config{Name: sm.Name(), Executable: exe}
+	return sm, exe
+}
+
+func TestInstallCommand_CreatesLayoutAndCopiesBinary(t *testing.T) {
+	root := t.TempDir()
+	layout := ServiceLayout{
+		StateDir:     ServiceDirectory{Path: filepath.Join(root, "lib", "test-service"), Mode: 0o750},
+		LogDir:       ServiceDirectory{Path: filepath.Join(root, "log", "test-service")},
+		BinaryPrefix: filepath.Join(root, "bin"),
+	}
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, exe := newLayoutTestManager(t, stub, layout)
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	info, err := os.Stat(layout.StateDir.Path)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("expected state dir to be created: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0o750 {
+		t.Fatalf("expected state dir mode 0750, got %o", got)
+	}
+	if _, err := os.Stat(layout.LogDir.Path); err != nil {
+		t.Fatalf("expected log dir to be created: %v", err)
+	}
+
+	copied := filepath.Join(layout.BinaryPrefix, filepath.Base(exe))
+	if _, err := os.Stat(copied); err != nil {
+		t.Fatalf("expected binary to be copied into prefix: %v", err)
+	}
+	if sm.config.Executable != exe {
+		t.Fatal("install must not mutate the manager's base config")
+	}
+}
+
+func TestInstallCommand_RollsBackLayoutOnFailure(t *testing.T) {
+	root := t.TempDir()
+	layout := ServiceLayout{
+		StateDir:     ServiceDirectory{Path: filepath.Join(root, "lib", "test-service")},
+		BinaryPrefix: filepath.Join(root, "bin"),
+	}
+	stub := &fakeDaemonService{
+		status:     service.StatusUnknown,
+		statusErr:  service.ErrNotInstalled,
+		installErr: errors.New("install failed"),
+	}
+	sm, _ := newLayoutTestManager(t, stub, layout)
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrServiceInstall) {
+		t.Fatalf("expected ErrServiceInstall, got %v", err)
+	}
+	for _, path := range []string{filepath.Join(root, "lib"), layout.BinaryPrefix} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be rolled back, stat err=%v", path, err)
+		}
+	}
+}
+
+func TestUninstallCommand_PurgeRequiresConfirmation(t *testing.T) {
+	root := t.TempDir()
+	layout := ServiceLayout{StateDir: ServiceDirectory{Path: filepath.Join(root, "lib", "test-service")}}
+	if err := os.MkdirAll(layout.StateDir.Path, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	stub := &fakeDaemonService{status: service.StatusStopped}
+	sm, _ := newLayoutTestManager(t, stub, layout)
+
+	cmd := sm.newUninstallCmd()
+	cmd.SetIn(strings.NewReader("n\n"))
+	cmd.SetOut(&strings.Builder{})
+	if err := cmd.ParseFlags([]string{"--purge"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("uninstall --purge (declined): %v", err)
+	}
+	if stub.uninstallCalled != 0 {
+		t.Fatal("declined purge must not uninstall the service")
+	}
+	if _, err := os.Stat(layout.StateDir.Path); err != nil {
+		t.Fatalf("declined purge must keep directories: %v", err)
+	}
+
+	cmd = sm.newUninstallCmd()
+	cmd.SetIn(strings.NewReader("y\n"))
+	cmd.SetOut(&strings.Builder{})
+	if err := cmd.ParseFlags([]string{"--purge"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("uninstall --purge: %v", err)
+	}
+	if stub.uninstallCalled != 1 {
+		t.Fatalf("expected uninstall to run once, got %d", stub.uninstallCalled)
+	}
+	if _, err := os.Stat(layout.StateDir.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected state dir to be purged, stat err=%v", err)
+	}
+}
+
+func TestCheckPurgePath_RejectsUnsafePaths(t *testing.T) {
+	for _, path := range []string{"/", "/var", "relative/demo", "/var/log", "/usr/local", "/usr/local/bin", "/srv/shared"} {
+		if err := checkPurgePath(path, "demo"); err == nil {
+			t.Fatalf("expected %q to be rejected", path)
+		}
+	}
+	for _, path := range []string{"/var/lib/demo", "/var/log/demo-eu1", "/srv/eu1"} {
+		if err := checkPurgePath(path, "demo", "eu1"); err != nil {
+			t.Fatalf("expected %q to be accepted: %v", path, err)
+		}
+	}
+}
+
+func TestUninstallCommand_PurgeRemovesCopiedBinaryOnly(t *testing.T) {
+	root := t.TempDir()
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, exe := newLayoutTestManager(t, stub, ServiceLayout{})
+	// 可执行文件已位于 BinaryPrefix 中，install 不复制，purge 也不能删除
+	sm.commands.config.service.Layout = ServiceLayout{
+		StateDir:     ServiceDirectory{Path: filepath.Join(root, "lib", "test-service")},
+		BinaryPrefix: filepath.Dir(exe),
+	}
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if record, _ := readInstallRecord(sm.installRecordDir(sm.Name())); record != nil {
+		t.Fatalf("nothing was copied, no record expected: %+v", record)
+	}
+
+	stub.statusErr, stub.status = nil, service.StatusStopped
+	cmd = sm.newUninstallCmd()
+	if err := cmd.ParseFlags([]string{"--purge", "--yes"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("uninstall --purge: %v", err)
+	}
+	if _, err := os.Stat(exe); err != nil {
+		t.Fatalf("binary not copied by install must be kept: %v", err)
+	}
+}
+
+func TestUninstallCommand_PurgeRemovesRecordedBinary(t *testing.T) {
+	skipUnlessRoot(t)
+	root := t.TempDir()
+	layout := ServiceLayout{
+		StateDir:     ServiceDirectory{Path: filepath.Join(root, "lib", "test-service")},
+		BinaryPrefix: filepath.Join(root, "bin"),
+	}
+	stub := &fakeDaemonService{status: service.StatusUnknown, statusErr: service.ErrNotInstalled}
+	sm, exe := newLayoutTestManager(t, stub, layout)
+
+	cmd := sm.newInstallCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	copied := filepath.Join(layout.BinaryPrefix, filepath.Base(exe))
+	record, err := readInstallRecord(sm.installRecordDir(sm.Name()))
+	if err != nil || record == nil || record.binary != copied {
+		t.Fatalf("expected copied binary to be recorded, got %+v, %v", record, err)
+	}
+
+	stub.statusErr, stub.status = nil, service.StatusStopped
+	cmd = sm.newUninstallCmd()
+	if err := cmd.ParseFlags([]string{"--purge", "--yes"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("uninstall --purge: %v", err)
+	}
+	if _, err := os.Stat(copied); !os.IsNotExist(err) {
+		t.Fatalf("expected copied binary to be purged, stat err=%v", err)
+	}
+	if _, err := os.Stat(exe); err != nil {
+		t.Fatalf("source binary must be kept: %v", err)
+	}
+}
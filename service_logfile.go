@@ -0,0 +1,292 @@
+package zcli
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLogMaxSize 未设置 MaxSize 时单个日志文件的上限
+const defaultLogMaxSize = 100 << 20
+
+// rotateTimeFormat 轮转文件名中的时间格式
+const rotateTimeFormat = "20060102T150405.000"
+
+// logTimestampFormat Timestamp 选项写入的行首时间格式
+const logTimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// RotateOptions 日志文件轮转参数
+type RotateOptions struct {
+	MaxSize    int64         // 单个文件上限字节数，默认 100MB
+	MaxAge     time.Duration // 轮转文件保留时长，0 表示不按时间清理
+	MaxBackups int           // 轮转文件保留数量，0 表示不按数量清理
+	Compress   bool          // 以 gzip 压缩轮转文件
+	Timestamp  bool          // 每行前添加时间戳与级别
+}
+
+// RotatingFile 按大小轮转的日志文件，轮转文件命名为 name-20060102T150405.000.ext。
+// 文件在首次写入时打开，Close 后再次写入会重新打开
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+	now  func() time.Time // 时间戳与轮转文件名使用的时钟
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	midLine bool
+
+	millMu sync.Mutex
+	millWg sync.WaitGroup
+}
+
+// NewRotatingFile 校验参数并返回日志文件，首次写入时才创建目录与文件，
+// 构建 CLI 时不会触碰文件系统
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if path == "" {
+		return nil, errors.New("log file path is required")
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultLogMaxSize
+	}
+	if opts.MaxAge < 0 || opts.MaxBackups < 0 {
+		return nil, errors.New("log file MaxAge and MaxBackups must not be negative")
+	}
+	return &RotatingFile{path: path, opts: opts, now: time.Now}, nil
+}
+
+// Path 返回当前日志文件路径
+func (f *RotatingFile) Path() string {
+	return f.path
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.midLine = false
+	return nil
+}
+
+// Write 以 Info 级别写入
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	return f.WriteLevel(LogLevelInfo, p)
+}
+
+// WriteLevel 写入日志，写满 MaxSize 前轮转文件
+func (f *RotatingFile) WriteLevel(level LogLevel, p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	data := p
+	if f.opts.Timestamp {
+		data = f.stamp(level, p)
+	}
+	if f.size > 0 && f.size+int64(len(data)) > f.opts.MaxSize {
+		if err := f.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(data)
+	f.size += int64(n)
+	if len(data) > 0 {
+		f.midLine = data[len(data)-1] != '\n'
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stamp 在每行行首添加时间与级别
+func (f *RotatingFile) stamp(level LogLevel, p []byte) []byte {
+	prefix := f.now().Format(logTimestampFormat) + " " + level.String() + " "
+	out := make([]byte, 0, len(p)+len(prefix))
+	start := !f.midLine
+	for _, c := range p {
+		if start {
+			out = append(out, prefix...)
+			start = false
+		}
+		out = append(out, c)
+		if c == '\n' {
+			start = true
+		}
+	}
+	return out
+}
+
+// Rotate 立即轮转当前文件
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	return f.rotateLocked()
+}
+
+// rotateLocked 关闭并改名当前文件后重新创建。改名失败时继续写入原文件；
+// 新文件创建失败时 f.file 为空，下次写入会再次尝试打开，不会一直失败
+func (f *RotatingFile) rotateLocked() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return errors.Join(err, f.open())
+	}
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(err, f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.millWg.Add(1)
+	go func() {
+		defer f.millWg.Done()
+		_ = f.mill()
+	}()
+	return nil
+}
+
+// backupName 生成轮转文件名，时间统一使用 UTC
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, base := filepath.Split(f.path)
+	ext := filepath.Ext(base)
+	return filepath.Join(dir, strings.TrimSuffix(base, ext)+"-"+t.UTC().Format(rotateTimeFormat)+ext)
+}
+
+// rotatedLog 已轮转的日志文件
+type rotatedLog struct {
+	path string
+	at   time.Time
+}
+
+// backups 列出已轮转文件，按时间从新到旧排序
+func (f *RotatingFile) backups() ([]rotatedLog, error) {
+	dir, base := filepath.Split(f.path)
+	if dir == "" {
+		dir = "."
+	}
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var logs []rotatedLog
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		at, err := time.Parse(rotateTimeFormat, strings.TrimSuffix(stamp, ext))
+		if err != nil {
+			continue
+		}
+		logs = append(logs, rotatedLog{path: filepath.Join(dir, name), at: at})
+	}
+	slices.SortFunc(logs, func(a, b rotatedLog) int { return b.at.Compare(a.at) })
+	return logs, nil
+}
+
+// mill 按数量与时间清理轮转文件，并压缩未压缩的轮转文件
+func (f *RotatingFile) mill() error {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	logs, err := f.backups()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	cutoff := f.now().Add(-f.opts.MaxAge)
+	for i, log := range logs {
+		expired := (f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups) ||
+			(f.opts.MaxAge > 0 && log.at.Before(cutoff))
+		switch {
+		case expired:
+			errs = append(errs, os.Remove(log.path))
+		case f.opts.Compress && !strings.HasSuffix(log.path, ".gz"):
+			errs = append(errs, compressLogFile(log.path))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compressLogFile 压缩为 .gz 并删除原文件
+func compressLogFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return fmt.Errorf("compress %s: %w", path, err)
+	}
+	if err = zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Close 关闭日志文件，并等待进行中的压缩与清理结束；之后再次写入会重新打开
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.millWg.Wait()
+	return err
+}
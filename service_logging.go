@@ -0,0 +1,156 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// errLogSinkUnsupported 当前平台不支持的日志输出
+var errLogSinkUnsupported = errors.New("log sink not supported on " + runtime.GOOS)
+
+// LogLevel 日志级别，取值与 syslog severity 一致
+type LogLevel int
+
+const (
+	LogLevelError   LogLevel = 3
+	LogLevelWarning LogLevel = 4
+	LogLevelInfo    LogLevel = 6
+	LogLevelDebug   LogLevel = 7
+)
+
+// String 返回级别名称
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelError:
+		return "ERROR"
+	case LogLevelWarning:
+		return "WARN"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelDebug:
+		return "DEBUG"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// LogSink 日志输出目标。Write 以 Info 级别写入，可直接交给 log、slog 等使用；
+// WriteLevel 指定级别写入。
+type LogSink interface {
+	io.Writer
+	WriteLevel(level LogLevel, p []byte) (int, error)
+	Close() error
+}
+
+// LevelWriter 返回以固定级别写入 sink 的 io.Writer
+func LevelWriter(sink LogSink, level LogLevel) io.Writer {
+	return levelWriter{sink: sink, level: level}
+}
+
+type levelWriter struct {
+	sink  LogSink
+	level LogLevel
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	return w.sink.WriteLevel(w.level, p)
+}
+
+// MultiLogSink 将日志同时写入多个 sink，单个 sink 失败不影响其余 sink
+func MultiLogSink(sinks ...LogSink) LogSink {
+	multi := make(multiLogSink, 0, len(sinks))
+	for _, sink := range sinks {
+		if sink != nil {
+			multi = append(multi, sink)
+		}
+	}
+	return multi
+}
+
+type multiLogSink []LogSink
+
+func (m multiLogSink) Write(p []byte) (int, error) {
+	return m.WriteLevel(LogLevelInfo, p)
+}
+
+func (m multiLogSink) WriteLevel(level LogLevel, p []byte) (int, error) {
+	var errs []error
+	for _, sink := range m {
+		if _, err := sink.WriteLevel(level, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+func (m multiLogSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// eachLogLine 按行调用 fn，跳过空行并去掉行尾换行
+func eachLogLine(p []byte, fn func(line []byte) error) error {
+	for len(p) > 0 {
+		line := p
+		if idx := bytes.IndexByte(p, '\n'); idx >= 0 {
+			line, p = p[:idx], p[idx+1:]
+		} else {
+			p = nil
+		}
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultLogTag 默认的日志标识，取可执行文件名
+func defaultLogTag() string {
+	return filepath.Base(os.Args[0])
+}
+
+// serviceLogSink 返回非前台运行（含 run --detach 的后台进程）时生效的日志输出，未配置或前台运行时返回 nil
+func (sm *sManager) serviceLogSink() LogSink {
+	rt := sm.commands.config.runtime
+	if rt == nil || len(rt.LogSinks) == 0 || (sm.sys.interactive() && !detachedChild()) {
+		return nil
+	}
+	return MultiLogSink(rt.LogSinks...)
+}
+
+// logSinkKey 是日志输出在运行上下文中的键
+type logSinkKey struct{}
+
+// withLogSink 把生效的日志输出附加到运行上下文。sink 在首次写入时打开，
+// 返回的函数在 Run 退出时关闭它们释放文件句柄与连接
+func (sm *sManager) withLogSink(ctx context.Context) (context.Context, func()) {
+	sink := sm.serviceLogSink()
+	if sink == nil {
+		return ctx, func() {}
+	}
+	return context.WithValue(ctx, logSinkKey{}, sink), func() { _ = sink.Close() }
+}
+
+// LogWriter 返回服务日志的写入目标，ctx 为传给 Run 的上下文。
+// 以服务方式运行且配置了 WithLogOutput 时写入这些 sink，否则写入标准错误。
+func LogWriter(ctx context.Context) io.Writer {
+	if ctx != nil {
+		if sink, ok := ctx.Value(logSinkKey{}).(LogSink); ok {
+			return sink
+		}
+	}
+	return os.Stderr
+}
@@ -0,0 +1,73 @@
+//go:build linux
+
+package zcli
+
+import (
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// listenUnixgram 在临时目录监听数据报套接字
+func listenUnixgram(t *testing.T) (string, *net.UnixConn) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return path, conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) []byte {
+	t.Helper()
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return buf[:n]
+}
+
+func TestSyslogSink_WritesOneRecordPerLine(t *testing.T) {
+	path, conn := listenUnixgram(t)
+	sink, err := NewSyslogSink("demo")
+	if err != nil {
+		t.Fatalf("NewSyslogSink: %v", err)
+	}
+	sink.sockets = []string{path}
+	defer func() { _ = sink.Close() }()
+
+	if _, err := sink.WriteLevel(LogLevelError, []byte("first\n\nsecond\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, want := range []string{"first", "second"} {
+		record := string(readDatagram(t, conn))
+		if !strings.HasPrefix(record, "<27>") || !strings.Contains(record, " demo[") || !strings.HasSuffix(record, "]: "+want+"\n") {
+			t.Fatalf("unexpected syslog record %q", record)
+		}
+	}
+}
+
+func TestJournalSink_EncodesNativeProtocol(t *testing.T) {
+	path, conn := listenUnixgram(t)
+	sink, err := NewJournalSink("demo")
+	if err != nil {
+		t.Fatalf("NewJournalSink: %v", err)
+	}
+	sink.socket = path
+	defer func() { _ = sink.Close() }()
+
+	if _, err := sink.WriteLevel(LogLevelWarning, []byte("panic: boom\ngoroutine 1\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	message := "panic: boom\ngoroutine 1"
+	size := binary.LittleEndian.AppendUint64(nil, uint64(len(message)))
+	want := "PRIORITY=4\nSYSLOG_IDENTIFIER=demo\nMESSAGE\n" + string(size) + message + "\n"
+	if got := string(readDatagram(t, conn)); got != want {
+		t.Fatalf("unexpected journal record %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+package zcli
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// captureSink 记录写入的级别与内容
+type captureSink struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (c *captureSink) Write(p []byte) (int, error) { return c.WriteLevel(LogLevelInfo, p) }
+
+func (c *captureSink) WriteLevel(level LogLevel, p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, level.String()+" "+string(p))
+	return len(p), nil
+}
+
+func (c *captureSink) Close() error { return nil }
+
+func (c *captureSink) text() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return strings.Join(c.records, "")
+}
+
+// fixLogClock 固定日志时钟，返回推进时钟的函数
+func fixLogClock(f *RotatingFile, at time.Time) func(time.Duration) {
+	var mu sync.Mutex
+	now := at
+	f.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	return func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+}
+
+func TestRotatingFile_RotatesBySizeAndKeepsBackups(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotateOptions{MaxSize: 16, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	advance := fixLogClock(f, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	for i := range 4 {
+		advance(time.Second)
+		if _, err := f.Write([]byte("0123456789ab-" + string(rune('a'+i)) + "\n")); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups to be kept, got %v", backups)
+	}
+	current, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if string(current) != "0123456789ab-d\n" {
+		t.Fatalf("current file should hold the last write, got %q", current)
+	}
+	newest, _ := os.ReadFile(filepath.Join(dir, "app-20260102T030409.000.log"))
+	if string(newest) != "0123456789ab-c\n" {
+		t.Fatalf("newest backup mismatch: %q", newest)
+	}
+}
+
+func TestRotatingFile_CompressAndExpireByAge(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "app-20251201T000000.000.log")
+	if err := os.WriteFile(stale, []byte("old\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	f, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotateOptions{MaxAge: 24 * time.Hour, Compress: true, Timestamp: true})
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	advance := fixLogClock(f, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))
+	if _, err := f.WriteLevel(LogLevelWarning, []byte("disk almost full\nsecond line\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	advance(time.Minute)
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("backup older than MaxAge should be removed, stat err=%v", err)
+	}
+	gz, err := os.Open(filepath.Join(dir, "app-20260102T000100.000.log.gz"))
+	if err != nil {
+		t.Fatalf("compressed backup missing: %v", err)
+	}
+	defer func() { _ = gz.Close() }()
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	data, _ := io.ReadAll(zr)
+	want := "2026-01-02T00:00:00.000Z WARN disk almost full\n2026-01-02T00:00:00.000Z WARN second line\n"
+	if string(data) != want {
+		t.Fatalf("unexpected backup content:\n%q\nwant\n%q", data, want)
+	}
+}
+
+func TestServiceLogSink_OnlyWhenNotInteractive(t *testing.T) {
+	sink := &captureSink{}
+	config := NewConfig()
+	config.basic.Name = "log-service"
+	config.runtime.LogSinks = []LogSink{sink}
+	cli := &Cli{config: config, colors: newColors(), lang: GetLanguageManager().GetPrimary()}
+
+	sm := newLogTestManager(t, cli)
+	sm.sys.interactive = func() bool { return true }
+	if ctx, _ := sm.withLogSink(context.Background()); LogWriter(ctx) != os.Stderr {
+		t.Fatal("foreground runs should keep standard error")
+	}
+
+	sm.sys.interactive = func() bool { return false }
+	sm.configureOutput()
+	sm.localizer.LogWarning("low memory")
+	sm.localizer.LogInfo(sm.Name(), "running")
+	ctx, _ := sm.withLogSink(context.Background())
+	_, _ = io.WriteString(LogWriter(ctx), "from user code\n")
+
+	text := sink.text()
+	for _, want := range []string{"ERROR Warning: low memory", "INFO ", "INFO from user code"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("sink missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func newLogTestManager(t *testing.T, cli *Cli) *sManager {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	sm, err := newServiceManager(cli, ctx, cancel)
+	if err != nil {
+		t.Fatalf("newServiceManager: %v", err)
+	}
+	sm.localizer.colors = nil
+	return sm
+}
+
+func TestRotatingFile_OpensLazilyAndRecoversFromFailedRotation(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path, RotateOptions{MaxSize: 8})
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("the log file should not be created before the first write, stat err=%v", err)
+	}
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// 目录只读时改名失败，原文件应继续可写
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o755) })
+	if os.Getuid() != 0 {
+		if err := f.Rotate(); err == nil {
+			t.Fatal("rotation in a read-only directory should fail")
+		}
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if _, err := f.Write([]byte("second\n")); err != nil {
+		t.Fatalf("writes after a failed rotation should succeed: %v", err)
+	}
+
+	// Close 后再次写入重新打开
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := f.Write([]byte("third\n")); err != nil {
+		t.Fatalf("write after close: %v", err)
+	}
+	_ = f.Close()
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "third") {
+		t.Fatalf("expected the reopened file to receive writes, got %q, %v", data, err)
+	}
+}
+
+func TestRun_ClosesLogSinksOnExit(t *testing.T) {
+	sink := &closeCountingSink{}
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.sys.interactive = func() bool { return false }
+	sm.commands.config.runtime.LogSinks = []LogSink{sink}
+	sm.commands.config.runtime.Run = func(ctx context.Context) error {
+		_, _ = io.WriteString(LogWriter(ctx), "working\n")
+		return errors.New("done")
+	}
+	_ = sm.Run(context.Background())
+	if sink.closed.Load() != 1 {
+		t.Fatalf("log sinks should be closed once when Run exits, got %d", sink.closed.Load())
+	}
+}
+
+// closeCountingSink 记录 Close 调用次数
+type closeCountingSink struct {
+	captureSink
+	closed atomic.Int32
+}
+
+func (c *closeCountingSink) Close() error {
+	c.closed.Add(1)
+	return nil
+}
@@ -0,0 +1,88 @@
+//go:build linux
+
+package zcli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// journalSocketPath journald 原生协议套接字
+const journalSocketPath = "/run/systemd/journal/socket"
+
+// JournalSink 通过 journald 原生协议写入日志，每次写入为一条记录，多行内容保持在同一条记录中。
+// 首次写入时连接，Close 后再次写入会重新连接
+type JournalSink struct {
+	mu         sync.Mutex
+	conn       net.Conn
+	identifier string
+	socket     string
+}
+
+// NewJournalSink 返回写入 journald 的 sink，identifier 为空时使用可执行文件名
+func NewJournalSink(identifier string) (*JournalSink, error) {
+	if identifier == "" {
+		identifier = defaultLogTag()
+	}
+	return &JournalSink{identifier: identifier, socket: journalSocketPath}, nil
+}
+
+// Write 以 Info 级别写入
+func (j *JournalSink) Write(p []byte) (int, error) {
+	return j.WriteLevel(LogLevelInfo, p)
+}
+
+// WriteLevel 写入一条带 PRIORITY 与 SYSLOG_IDENTIFIER 的记录
+func (j *JournalSink) WriteLevel(level LogLevel, p []byte) (int, error) {
+	message := bytes.TrimRight(p, "\r\n")
+	if len(message) == 0 {
+		return len(p), nil
+	}
+	var record []byte
+	record = appendJournalField(record, "PRIORITY", []byte(strconv.Itoa(int(level))))
+	record = appendJournalField(record, "SYSLOG_IDENTIFIER", []byte(j.identifier))
+	record = appendJournalField(record, "MESSAGE", message)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		conn, err := net.Dial("unixgram", j.socket)
+		if err != nil {
+			return 0, fmt.Errorf("connect journald: %w", err)
+		}
+		j.conn = conn
+	}
+	if _, err := j.conn.Write(record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendJournalField 编码单个字段，值含换行时使用长度前缀的二进制格式
+func appendJournalField(b []byte, key string, value []byte) []byte {
+	b = append(b, key...)
+	if bytes.IndexByte(value, '\n') < 0 {
+		b = append(b, '=')
+	} else {
+		b = append(b, '\n')
+		b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	}
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// Close 关闭 journald 连接
+func (j *JournalSink) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
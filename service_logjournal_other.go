@@ -0,0 +1,17 @@
+//go:build !linux
+
+package zcli
+
+// JournalSink 当前平台没有 journald
+type JournalSink struct{}
+
+// NewJournalSink 当前平台没有 journald，始终返回错误
+func NewJournalSink(string) (*JournalSink, error) {
+	return nil, errLogSinkUnsupported
+}
+
+func (j *JournalSink) Write([]byte) (int, error) { return 0, errLogSinkUnsupported }
+
+func (j *JournalSink) WriteLevel(LogLevel, []byte) (int, error) { return 0, errLogSinkUnsupported }
+
+func (j *JournalSink) Close() error { return nil }
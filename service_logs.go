@@ -0,0 +1,294 @@
+package zcli
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultLogLines logs 命令默认显示的行数
+const defaultLogLines = 50
+
+// runJournalctl 执行 journalctl 并把输出写入命令输出
+func runJournalctl(ctx context.Context, path string, args []string, out, errOut io.Writer) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	return cmd.Run()
+}
+
+// logsOptions logs 命令参数
+type logsOptions struct {
+	follow   bool
+	since    time.Time
+	lines    int
+	interval time.Duration // 跟随日志文件时的轮询间隔
+}
+
+// newLogsCmd 创建查看服务日志命令
+func (sm *sManager) newLogsCmd() *cobra.Command {
+	cmd := sm.buildBaseCommand("logs", sm.localizer.GetOperation("logs"))
+	var since string
+	opts := logsOptions{}
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false, sm.localizer.GetFlag("follow"))
+	cmd.Flags().StringVar(&since, "since", "", sm.localizer.GetFlag("since"))
+	cmd.Flags().IntVarP(&opts.lines, "lines", "n", defaultLogLines, sm.localizer.GetFlag("lines"))
+	// 路径检查失败时往往正需要查看日志，不要求配置通过检查
+	cmd.RunE = sm.wrapDiagnosticRunE(func(cmd *cobra.Command, args []string) error {
+		if since != "" {
+			at, err := parseSince(since, time.Now())
+			if err != nil {
+				return sm.wrapServiceError(fmt.Errorf("%s", sm.localizer.FormatError("invalidSince", since)), ErrConfigInvalid, "logs")
+			}
+			opts.since = at
+			// 指定起始时间而未指定行数时显示全部匹配的日志
+			if !cmd.Flags().Changed("lines") {
+				opts.lines = 0
+			}
+		}
+		return sm.showLogs(sm.commandContext(cmd), cmd.OutOrStdout(), cmd.ErrOrStderr(), opts)
+	})
+	return cmd
+}
+
+// parseSince 解析相对时长或绝对时间，绝对时间按本地时区解析
+func parseSince(text string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(text); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if at, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", text)
+}
+
+// showLogs 按服务配置的日志输出选择来源：轮转文件、journald，最后尝试 journalctl
+func (sm *sManager) showLogs(ctx context.Context, out, errOut io.Writer, opts logsOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	file, journal := configuredLogSources(sm.commands.config.runtime.LogSinks)
+	if file != nil {
+		opts.interval = sm.sys.logFollowInterval
+		if err := showLogFile(ctx, out, file, opts); err != nil {
+			return sm.wrapServiceError(err, ErrPathInvalid, "logs")
+		}
+		return nil
+	}
+
+	path, err := sm.sys.lookJournalctl()
+	if err != nil {
+		if journal {
+			return sm.wrapServiceError(err, ErrPathNotFound, "logs")
+		}
+		sm.localizer.LogWarning("%s", sm.localizer.GetMessage("noLogSource"))
+		return nil
+	}
+	if err := sm.sys.runJournalctl(ctx, path, journalctlArgs(sm.Name(), opts), out, errOut); err != nil && ctx.Err() == nil {
+		return sm.wrapServiceError(err, ErrRuntime, "logs")
+	}
+	return nil
+}
+
+// configuredLogSources 返回第一个可回读的日志来源：轮转文件或是否配置了 journald
+func configuredLogSources(sinks []LogSink) (*RotatingFile, bool) {
+	for _, sink := range sinks {
+		switch s := sink.(type) {
+		case *RotatingFile:
+			return s, false
+		case *JournalSink:
+			return nil, true
+		case multiLogSink:
+			if file, journal := configuredLogSources(s); file != nil || journal {
+				return file, journal
+			}
+		}
+	}
+	return nil, false
+}
+
+// journalctlArgs 生成 journalctl 参数
+func journalctlArgs(unit string, opts logsOptions) []string {
+	args := []string{"-u", unit, "--no-pager"}
+	if opts.lines > 0 {
+		args = append(args, "-n", strconv.Itoa(opts.lines))
+	}
+	if !opts.since.IsZero() {
+		args = append(args, "--since", opts.since.Format("2006-01-02 15:04:05"))
+	}
+	if opts.follow {
+		args = append(args, "-f")
+	}
+	return args
+}
+
+// showLogFile 输出日志文件及其轮转文件中满足条件的行，follow 时继续跟随当前文件
+func showLogFile(ctx context.Context, out io.Writer, file *RotatingFile, opts logsOptions) error {
+	offset, err := printLogFiles(out, file, opts)
+	if err != nil {
+		return err
+	}
+	if !opts.follow {
+		return nil
+	}
+	return followLogFile(ctx, out, file.Path(), offset, opts.interval)
+}
+
+// printLogFiles 由新到旧读取日志文件，凑够行数后按时间顺序输出，返回当前文件已读取的长度
+func printLogFiles(out io.Writer, file *RotatingFile, opts logsOptions) (int64, error) {
+	backups, err := file.backups()
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	sources := append([]rotatedLog{{path: file.Path()}}, backups...)
+
+	var offset int64
+	var lines [][]byte
+	for i, src := range sources {
+		if i > 0 && !opts.since.IsZero() && src.at.Before(opts.since) {
+			break
+		}
+		data, modTime, err := readLogFile(src.path)
+		if err != nil {
+			if i == 0 && os.IsNotExist(err) {
+				continue
+			}
+			return 0, err
+		}
+		if i == 0 {
+			offset = int64(len(data))
+		}
+		lines = append(filterLogLines(data, modTime, opts.since), lines...)
+		if opts.lines > 0 && len(lines) >= opts.lines {
+			break
+		}
+	}
+	if opts.lines > 0 && len(lines) > opts.lines {
+		lines = lines[len(lines)-opts.lines:]
+	}
+
+	w := bufio.NewWriter(out)
+	for _, line := range lines {
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+	}
+	return offset, w.Flush()
+}
+
+// readLogFile 读取日志文件，.gz 文件自动解压
+func readLogFile(path string) ([]byte, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("%s: %w", path, err)
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	return data, info.ModTime(), err
+}
+
+// filterLogLines 拆分行并按起始时间过滤。带时间戳的行按自身时间判断，
+// 续行沿用上一行的结果；整个文件没有时间戳时按修改时间判断。
+func filterLogLines(data []byte, modTime, since time.Time) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	if since.IsZero() {
+		return lines
+	}
+	keep := !modTime.Before(since)
+	kept := make([][]byte, 0, len(lines))
+	for _, line := range lines {
+		if at, ok := logLineTime(line); ok {
+			keep = !at.Before(since)
+		}
+		if keep {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// logLineTime 解析 Timestamp 选项写入的行首时间
+func logLineTime(line []byte) (time.Time, bool) {
+	head, _, ok := bytes.Cut(line, []byte(" "))
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(logTimestampFormat, string(head))
+	return at, err == nil
+}
+
+// followLogFile 从 offset 开始持续输出日志文件新增内容。
+// 文件被轮转（路径指向新文件）时读完旧文件再切换，被截断时从头读取。
+func followLogFile(ctx context.Context, out io.Writer, path string, offset int64, interval time.Duration) error {
+	var current *os.File
+	defer func() {
+		if current != nil {
+			_ = current.Close()
+		}
+	}()
+
+	for {
+		if current == nil {
+			if f, err := os.Open(path); err == nil {
+				if _, err := f.Seek(offset, io.SeekStart); err != nil {
+					_ = f.Close()
+					return err
+				}
+				current = f
+			}
+		}
+		if current != nil {
+			if _, err := io.Copy(out, current); err != nil {
+				return err
+			}
+			pos, _ := current.Seek(0, io.SeekCurrent)
+			opened, _ := current.Stat()
+			info, err := os.Stat(path)
+			switch {
+			case err == nil && opened != nil && !os.SameFile(info, opened):
+				// 轮转：再读一次旧文件的剩余内容，然后从新文件开头读取
+				_, _ = io.Copy(out, current)
+				_ = current.Close()
+				current, offset = nil, 0
+				continue
+			case err == nil && info.Size() < pos:
+				if _, err := current.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer 可并发读写的缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func runLogs(t *testing.T, sm *sManager, args ...string) string {
+	t.Helper()
+	cmd := sm.newLogsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("logs %v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestLogsCommand_ReadsAcrossRotatedFiles(t *testing.T) {
+	file, err := NewRotatingFile(filepath.Join(t.TempDir(), "app.log"), RotateOptions{Compress: true, Timestamp: true})
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	advance := fixLogClock(file, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	for i, line := range []string{"boot", "ready", "request"} {
+		if i > 0 {
+			advance(time.Hour)
+			if err := file.Rotate(); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+		}
+		_, _ = file.Write([]byte(line + "\n"))
+	}
+	_ = file.Close()
+
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.commands.config.runtime.LogSinks = []LogSink{file}
+
+	// 路径检查失败不影响查看日志
+	sm.configErr = NewError(ErrConfigInvalid).Message("work dir does not exist").Build()
+
+	out := runLogs(t, sm, "-n", "2")
+	if strings.Contains(out, "boot") || !strings.Contains(out, "ready") || !strings.HasSuffix(out, "INFO request\n") {
+		t.Fatalf("expected the last two lines in order, got:\n%s", out)
+	}
+
+	since := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC).Local().Format("2006-01-02 15:04:05")
+	out = runLogs(t, sm, "--since", since)
+	if strings.Contains(out, "boot") || strings.Count(out, "\n") != 2 {
+		t.Fatalf("--since should drop older entries, got:\n%s", out)
+	}
+}
+
+func TestFollowLogFile_HandlesRotation(t *testing.T) {
+	file, err := NewRotatingFile(filepath.Join(t.TempDir(), "app.log"), RotateOptions{})
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	_, _ = file.Write([]byte("old\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- showLogFile(ctx, &out, file, logsOptions{follow: true, lines: 10, interval: 5 * time.Millisecond})
+	}()
+
+	waitForOutput := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q, got:\n%s", want, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitForOutput("old\n")
+	_, _ = file.Write([]byte("before rotate\n"))
+	waitForOutput("before rotate\n")
+	if err := file.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	_, _ = file.Write([]byte("after rotate\n"))
+	waitForOutput("after rotate\n")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if got := out.String(); got != "old\nbefore rotate\nafter rotate\n" {
+		t.Fatalf("each line should appear once, got %q", got)
+	}
+}
+
+func TestLogsCommand_UsesJournalctlOrWarns(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	var gotArgs []string
+	sm.sys.lookJournalctl = func() (string, error) { return "/usr/bin/journalctl", nil }
+	sm.sys.runJournalctl = func(_ context.Context, _ string, args []string, out, _ io.Writer) error {
+		gotArgs = args
+		_, _ = io.WriteString(out, "journal line\n")
+		return nil
+	}
+
+	if out := runLogs(t, sm, "-f", "-n", "20"); out != "journal line\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	want := []string{"-u", "test-service", "--no-pager", "-n", "20", "-f"}
+	if !reflect.DeepEqual(gotArgs, want) {
+		t.Fatalf("journalctl args = %v, want %v", gotArgs, want)
+	}
+
+	sm.sys.lookJournalctl = func() (string, error) { return "", errors.New("not found") }
+	var warn bytes.Buffer
+	sm.localizer.ConfigureOutput(io.Discard, &warn, false, false)
+	sm.localizer.colors = nil
+	if out := runLogs(t, sm); out != "" {
+		t.Fatalf("no source should print nothing to stdout, got %q", out)
+	}
+	if !strings.Contains(warn.String(), sm.localizer.GetMessage("noLogSource")) {
+		t.Fatalf("expected a no-source warning, got %q", warn.String())
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	if at, err := parseSince("90m", now); err != nil || !at.Equal(now.Add(-90*time.Minute)) {
+		t.Fatalf("duration: %v %v", at, err)
+	}
+	if at, err := parseSince("2026-02-28 08:30:00", now); err != nil || at.Hour() != 8 || at.Day() != 28 {
+		t.Fatalf("absolute: %v %v", at, err)
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Fatal("expected an error for unsupported input")
+	}
+}
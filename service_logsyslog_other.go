@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+
+package zcli
+
+// SyslogSink 当前平台不支持本地 syslog
+type SyslogSink struct{}
+
+// NewSyslogSink 当前平台不支持本地 syslog，始终返回错误
+func NewSyslogSink(string) (*SyslogSink, error) {
+	return nil, errLogSinkUnsupported
+}
+
+func (s *SyslogSink) Write([]byte) (int, error) { return 0, errLogSinkUnsupported }
+
+func (s *SyslogSink) WriteLevel(LogLevel, []byte) (int, error) { return 0, errLogSinkUnsupported }
+
+func (s *SyslogSink) Close() error { return nil }
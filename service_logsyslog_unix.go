@@ -0,0 +1,98 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultSyslogSockets 返回本地 syslog 套接字，依次尝试
+func defaultSyslogSockets() []string {
+	return []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+}
+
+// syslogFacilityDaemon syslog 的 daemon facility
+const syslogFacilityDaemon = 3
+
+// SyslogSink 通过本地 syslog 套接字写入日志，facility 为 daemon，多行内容按行拆分为独立记录。
+// 首次写入时连接，Close 后再次写入会重新连接
+type SyslogSink struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	tag     string
+	pid     int
+	sockets []string
+}
+
+// NewSyslogSink 返回写入本地 syslog 的 sink，tag 为空时使用可执行文件名
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	if tag == "" {
+		tag = defaultLogTag()
+	}
+	return &SyslogSink{tag: tag, pid: os.Getpid(), sockets: defaultSyslogSockets()}, nil
+}
+
+// dialLocalSocket 依次以数据报与流方式连接本地套接字
+func dialLocalSocket(paths []string) (net.Conn, error) {
+	var lastErr error
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// Write 以 Info 级别写入
+func (s *SyslogSink) Write(p []byte) (int, error) {
+	return s.WriteLevel(LogLevelInfo, p)
+}
+
+// WriteLevel 以 RFC 3164 本地格式写入，未连接时先连接，连接断开时重连一次
+func (s *SyslogSink) WriteLevel(level LogLevel, p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := eachLogLine(p, func(line []byte) error {
+		record := fmt.Sprintf("<%d>%s %s[%d]: %s\n",
+			syslogFacilityDaemon*8+int(level), time.Now().Format(time.Stamp), s.tag, s.pid, line)
+		if s.conn != nil {
+			if _, err := s.conn.Write([]byte(record)); err == nil {
+				return nil
+			}
+		}
+		conn, err := dialLocalSocket(s.sockets)
+		if err != nil {
+			return fmt.Errorf("connect syslog: %w", err)
+		}
+		if s.conn != nil {
+			_ = s.conn.Close()
+		}
+		s.conn = conn
+		_, err = conn.Write([]byte(record))
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close 关闭 syslog 连接
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,228 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// metricsShutdownTimeout 关闭指标服务时等待进行中请求的上限
+const metricsShutdownTimeout = 2 * time.Second
+
+// serviceMetrics 框架内置指标，未启用指标时 sManager.metrics 为 nil，所有方法均可在 nil 上调用
+type serviceMetrics struct {
+	registry         *MetricsRegistry
+	starts           *Counter
+	restarts         *Counter
+	forceExits       *Counter
+	errors           *CounterVec
+	startDuration    *Gauge
+	shutdownDuration *Gauge
+	paused           *Gauge
+
+	startedAt      atomic.Int64 // 用户 Run 开始的时间，未运行时为 0
+	stopRequested  atomic.Int64 // 收到停止的时间，未停止时为 0
+	pendingRestart atomic.Bool  // 历史记录判定本次启动为失败后重启
+	now            func() time.Time
+}
+
+// newServiceMetrics 在注册表中注册框架指标、Go 运行时指标与构建信息，now 为计时使用的时钟
+func newServiceMetrics(registry *MetricsRegistry, info *VersionInfo, now func() time.Time) *serviceMetrics {
+	if registry == nil {
+		return nil
+	}
+	m := &serviceMetrics{
+		registry:         registry,
+		starts:           registry.NewCounter("zcli_service_starts_total", "Number of times the service Run function was started."),
+		restarts:         registry.NewCounter("zcli_service_restarts_total", "Number of starts that followed a failure or an earlier run in the same process."),
+		forceExits:       registry.NewCounter("zcli_service_force_exits_total", "Number of times the process was force-exited after the stop timeout."),
+		errors:           registry.NewCounterVec("zcli_errors_total", "Number of errors handled by the framework, by error code.", "code"),
+		startDuration:    registry.NewGauge("zcli_service_last_start_duration_seconds", "Time from run until the user Run function was called, for the last start."),
+		shutdownDuration: registry.NewGauge("zcli_service_last_shutdown_duration_seconds", "Time from the stop request until Run returned, for the last shutdown."),
+		paused:           registry.NewGauge("zcli_service_paused", "1 while the service is paused for maintenance, 0 otherwise."),
+		now:              now,
+	}
+	registry.NewGaugeFunc("zcli_service_uptime_seconds", "Seconds since the user Run function was called, 0 when not running.", m.uptime)
+	registerRuntimeMetrics(registry)
+	registerBuildInfo(registry, info)
+	return m
+}
+
+func (m *serviceMetrics) uptime() float64 {
+	startedAt := m.startedAt.Load()
+	if startedAt == 0 {
+		return 0
+	}
+	return m.now().Sub(time.Unix(0, startedAt)).Seconds()
+}
+
+// started 记录一次启动：同一进程内的再次启动或历史判定的失败后启动计为重启
+func (m *serviceMetrics) started() {
+	if m == nil {
+		return
+	}
+	restart := m.pendingRestart.Swap(false)
+	if m.starts.Value() > 0 || restart {
+		m.restarts.Inc()
+	}
+	m.starts.Inc()
+	m.stopRequested.Store(0)
+}
+
+// ready 在调用用户 Run 前记录启动耗时
+func (m *serviceMetrics) ready(begin time.Time) {
+	if m == nil {
+		return
+	}
+	now := m.now()
+	m.startDuration.Set(now.Sub(begin).Seconds())
+	m.startedAt.Store(now.UnixNano())
+}
+
+// stopping 记录首次收到停止的时间
+func (m *serviceMetrics) stopping() {
+	if m == nil {
+		return
+	}
+	m.stopRequested.CompareAndSwap(0, m.now().UnixNano())
+}
+
+// watchStop 在 ctx 取消时记录停止时间，返回的释放函数会等待已触发的记录完成
+func (m *serviceMetrics) watchStop(ctx context.Context) func() {
+	if m == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		m.stopping()
+		close(done)
+	})
+	return func() {
+		if !stop() {
+			<-done
+		}
+	}
+}
+
+// setPaused 记录维护模式状态
+func (m *serviceMetrics) setPaused(paused bool) {
+	if m == nil {
+		return
+	}
+	if paused {
+		m.paused.Set(1)
+	} else {
+		m.paused.Set(0)
+	}
+}
+
+// stopped 在 Run 返回时记录停止耗时并清零运行时长
+func (m *serviceMetrics) stopped() {
+	if m == nil {
+		return
+	}
+	if requested := m.stopRequested.Swap(0); requested != 0 {
+		m.shutdownDuration.Set(m.now().Sub(time.Unix(0, requested)).Seconds())
+	}
+	m.startedAt.Store(0)
+}
+
+// observe 接收生命周期事件，识别失败后重启与强制退出
+func (m *serviceMetrics) observe(event LifecycleEvent) {
+	if m == nil {
+		return
+	}
+	switch event.Type {
+	case LifecycleRestart:
+		m.pendingRestart.Store(true)
+	case LifecycleForceExit:
+		m.forceExits.Inc()
+	}
+}
+
+// observePrevious 计入上一个进程被强制退出的记录，强制退出的进程自身来不及被采集
+func (m *serviceMetrics) observePrevious(last LifecycleEvent) {
+	if m == nil {
+		return
+	}
+	if last.Type == LifecycleForceExit && last.PID != os.Getpid() {
+		m.forceExits.Inc()
+	}
+}
+
+// observeError 按错误码计数，非 ServiceError 计为 RUNTIME_ERROR
+func (m *serviceMetrics) observeError(err error) {
+	if m == nil || err == nil {
+		return
+	}
+	code := ErrRuntime
+	var se *ServiceError
+	if errors.As(err, &se) && se.Code != "" {
+		code = se.Code
+	}
+	m.errors.With(string(code)).Inc()
+}
+
+// registerRuntimeMetrics 注册 Go 运行时指标，每次输出时统一读取一次 MemStats
+func registerRuntimeMetrics(registry *MetricsRegistry) {
+	goroutines := registry.NewGauge("go_goroutines", "Number of goroutines that currently exist.")
+	alloc := registry.NewGauge("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.")
+	heapInuse := registry.NewGauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.")
+	sys := registry.NewGauge("go_memstats_sys_bytes", "Number of bytes obtained from the system.")
+	gcCycles := registry.NewCounter("go_gc_cycles_total", "Number of completed GC cycles.")
+	gcPause := registry.NewCounter("go_gc_pause_seconds_total", "Cumulative time spent in GC stop-the-world pauses.")
+	registry.onCollect(func() {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		goroutines.Set(float64(runtime.NumGoroutine()))
+		alloc.Set(float64(stats.Alloc))
+		heapInuse.Set(float64(stats.HeapInuse))
+		sys.Set(float64(stats.Sys))
+		gcCycles.mirror(float64(stats.NumGC))
+		gcPause.mirror(time.Duration(stats.PauseTotalNs).Seconds())
+	})
+}
+
+// registerBuildInfo 以标签形式输出 VersionInfo，值恒为 1
+func registerBuildInfo(registry *MetricsRegistry, info *VersionInfo) {
+	if info == nil {
+		info = NewVersion()
+	}
+	goVersion := info.GoVersion
+	if goVersion == "" {
+		goVersion = runtime.Version()
+	}
+	registry.NewGaugeVec("zcli_build_info", "Build information from VersionInfo, always 1.",
+		"version", "go_version", "git_commit", "git_branch", "git_tag", "platform", "arch").
+		With(info.Version, goVersion, info.GitCommit, info.GitBranch, info.GitTag, info.Platform, info.Architecture).
+		Set(1)
+}
+
+// serveMetrics 在配置了监听地址时于 /metrics 输出指标，返回关闭函数。
+// 监听失败只记录警告，不影响服务启动。
+func (sm *sManager) serveMetrics() func() {
+	addr := sm.commands.config.runtime.MetricsAddr
+	if sm.metrics == nil || addr == "" {
+		return func() {}
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		sm.localizer.LogWarning(sm.localizer.GetMessage("metricsFailed"), addr, err)
+		return func() {}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", sm.metrics.registry.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,127 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newMetricsTestManager 构造启用指标的服务管理器，并固定指标时钟
+func newMetricsTestManager(t *testing.T) (*sManager, *MetricsRegistry, func(time.Duration)) {
+	t.Helper()
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.exitChan = make(chan struct{})
+
+	// 停止时间由 context.AfterFunc 的协程记录，时钟需并发安全
+	var now atomic.Int64
+	now.Store(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano())
+	sm.sys.now = func() time.Time { return time.Unix(0, now.Load()) }
+	registry := NewMetricsRegistry()
+	sm.metrics = newServiceMetrics(registry, &VersionInfo{Version: "1.2.3", GitCommit: "abc123"}, sm.sys.now)
+	return sm, registry, func(d time.Duration) { now.Add(int64(d)) }
+}
+
+func scrape(t *testing.T, registry *MetricsRegistry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRun_RecordsServiceMetrics(t *testing.T) {
+	sm, registry, advance := newMetricsTestManager(t)
+
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		return errors.New("boom")
+	}
+	if err := sm.Run(context.Background()); err == nil {
+		t.Fatal("first run should fail")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var uptime string
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		advance(3 * time.Second)
+		uptime = scrape(t, registry)
+		cancel()
+		for sm.metrics.stopRequested.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}
+	sm.commands.config.runtime.Stop = func() error {
+		advance(2 * time.Second)
+		return nil
+	}
+	if err := sm.Run(ctx); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	_ = sm.handleError(NewError(ErrTimeout).Message("slow").Build())
+	_ = sm.handleError(errors.New("plain"))
+
+	if !strings.Contains(uptime, "zcli_service_uptime_seconds 3\n") {
+		t.Fatalf("uptime should count from the user Run call:\n%s", uptime)
+	}
+	out := scrape(t, registry)
+	for _, want := range []string{
+		"zcli_service_starts_total 2\n",
+		"zcli_service_restarts_total 1\n",
+		"zcli_service_uptime_seconds 0\n",
+		"zcli_service_last_shutdown_duration_seconds 2\n",
+		"zcli_service_force_exits_total 0\n",
+		`zcli_errors_total{code="RUNTIME_ERROR"} 1`,
+		`zcli_errors_total{code="TIMEOUT"} 1`,
+		`zcli_build_info{version="1.2.3",go_version="go`,
+		`git_commit="abc123"`,
+		"# TYPE go_goroutines gauge\n",
+		"# TYPE go_memstats_alloc_bytes gauge\n",
+		"# TYPE go_gc_cycles_total counter\n",
+		"# TYPE go_gc_pause_seconds_total counter\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetrics_ForceExitFromPreviousProcess(t *testing.T) {
+	sm, registry, _ := newMetricsTestManager(t)
+	sm.commands.config.service.Layout.StateDir.Path = t.TempDir()
+
+	forced := sm.newLifecycleEvent(LifecycleForceExit)
+	forced.PID = -1
+	if err := writeHistory(sm.historyFile(), []LifecycleEvent{sm.newLifecycleEvent(LifecycleStart), forced}); err != nil {
+		t.Fatalf("writeHistory: %v", err)
+	}
+	if err := sm.recordStart(); err != nil {
+		t.Fatalf("recordStart: %v", err)
+	}
+	sm.metrics.started()
+
+	out := scrape(t, registry)
+	for _, want := range []string{"zcli_service_force_exits_total 1\n", "zcli_service_restarts_total 1\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBuilder_WithMetrics(t *testing.T) {
+	cli, err := NewBuilder("en").WithName("demo").WithMetrics(nil, "127.0.0.1:0").BuildWithError()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if cli.config.runtime.Metrics == nil || cli.config.runtime.MetricsAddr != "127.0.0.1:0" {
+		t.Fatalf("metrics should be configured: %+v", cli.config.runtime)
+	}
+	if cloned := cloneRuntime(cli.config.runtime); cloned.Metrics != cli.config.runtime.Metrics {
+		t.Fatal("cloned runtime should share the registry")
+	}
+}
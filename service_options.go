@@ -0,0 +1,257 @@
+package zcli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemdOptions systemd 平台的类型化选项，零值字段不生效。
+// 加固类指令没有对应的 daemon 选项键，会写入生成的 unit 文件。
+type SystemdOptions struct {
+	Restart           string        // no|always|on-success|on-failure|on-abnormal|on-abort|on-watchdog
+	RestartSec        time.Duration // 重启间隔
+	LimitNOFILE       int           // 文件描述符上限
+	SuccessExitStatus string        // 视为成功的退出码/信号，如 "1 SIGKILL"
+	ReloadSignal      string        // reload 时发送的信号，如 "SIGHUP"
+	PIDFile           string        // PID 文件路径
+	UserService       bool          // 安装为用户级服务
+	LogOutput         bool          // 将输出写入日志文件
+
+	NoNewPrivileges bool     // 禁止进程及其子进程提升权限
+	ProtectSystem   string   // true|full|strict
+	ProtectHome     string   // true|read-only|tmpfs
+	PrivateTmp      bool     // 使用私有 /tmp
+	MemoryMax       string   // 内存上限，如 512M、2G、80%、infinity
+	CPUQuota        string   // CPU 配额，如 50%、200%
+	ReadWritePaths  []string // ProtectSystem 生效时仍可写的绝对路径
+}
+
+// LaunchdOptions launchd 平台的类型化选项，设置后整体生效。
+type LaunchdOptions struct {
+	KeepAlive     bool // 退出后自动拉起
+	RunAtLoad     bool // 加载时立即启动
+	SessionCreate bool // 创建独立的安全会话
+	UserService   bool // 安装为 LaunchAgent
+}
+
+// WindowsOptions Windows 服务的类型化选项，零值字段不生效。
+type WindowsOptions struct {
+	StartType            string        // automatic|manual|disabled
+	DelayedAutoStart     bool          // 延迟自动启动，仅 automatic 有效
+	Password             string        // 运行账号密码
+	OnFailure            string        // restart|reboot|noaction
+	OnFailureDelay       time.Duration // 失败后执行动作前的延迟
+	OnFailureResetPeriod time.Duration // 失败计数重置周期
+}
+
+// knownServiceOptionKeys daemon 识别的平台选项键
+var knownServiceOptionKeys = map[string]struct{}{
+	// 通用
+	"RunWait": {}, "UserService": {}, "LogOutput": {}, "LogDirectory": {},
+	// Linux
+	"SystemdScript": {}, "SysvScript": {}, "UpstartScript": {}, "OpenRCScript": {},
+	"Restart": {}, "SuccessExitStatus": {}, "ReloadSignal": {}, "PIDFile": {}, "LimitNOFILE": {},
+	// macOS
+	"KeepAlive": {}, "RunAtLoad": {}, "SessionCreate": {}, "LaunchdConfig": {},
+	// Windows
+	"StartType": {}, "DelayedAutoStart": {}, "Password": {},
+	"OnFailure": {}, "OnFailureDelayDuration": {}, "OnFailureResetPeriod": {},
+}
+
+// unknownServiceOptionKeys 返回 daemon 不识别的选项键，这些键会被静默忽略
+func unknownServiceOptionKeys(options ServiceOptions) []string {
+	var unknown []string
+	for key := range options {
+		if _, ok := knownServiceOptionKeys[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+var (
+	memoryMaxPattern = regexp.MustCompile(`^(infinity|[0-9]+[KMGT]?|[0-9]+(\.[0-9]+)?%)$`)
+	cpuQuotaPattern  = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?%$`)
+)
+
+// validate 校验 systemd 选项取值
+func (o *SystemdOptions) validate() []error {
+	var errs []error
+	switch o.Restart {
+	case "", "no", "always", "on-success", "on-failure", "on-abnormal", "on-abort", "on-watchdog":
+	default:
+		errs = append(errs, fmt.Errorf("systemd: invalid Restart %q", o.Restart))
+	}
+	if o.RestartSec < 0 {
+		errs = append(errs, errors.New("systemd: RestartSec must not be negative"))
+	}
+	if o.LimitNOFILE < 0 {
+		errs = append(errs, errors.New("systemd: LimitNOFILE must not be negative"))
+	}
+	switch o.ProtectSystem {
+	case "", "true", "false", "full", "strict":
+	default:
+		errs = append(errs, fmt.Errorf("systemd: invalid ProtectSystem %q", o.ProtectSystem))
+	}
+	switch o.ProtectHome {
+	case "", "true", "false", "read-only", "tmpfs":
+	default:
+		errs = append(errs, fmt.Errorf("systemd: invalid ProtectHome %q", o.ProtectHome))
+	}
+	if o.MemoryMax != "" && !memoryMaxPattern.MatchString(o.MemoryMax) {
+		errs = append(errs, fmt.Errorf("systemd: invalid MemoryMax %q", o.MemoryMax))
+	}
+	if o.CPUQuota != "" && !cpuQuotaPattern.MatchString(o.CPUQuota) {
+		errs = append(errs, fmt.Errorf("systemd: invalid CPUQuota %q", o.CPUQuota))
+	}
+	for _, path := range o.ReadWritePaths {
+		if !filepath.IsAbs(path) {
+			errs = append(errs, fmt.Errorf("systemd: ReadWritePaths entry %q must be absolute", path))
+		}
+	}
+	return errs
+}
+
+// apply 将 systemd 选项写入 daemon 选项键
+func (o *SystemdOptions) apply(options ServiceOptions) {
+	if o.Restart != "" {
+		options["Restart"] = o.Restart
+	}
+	if o.LimitNOFILE > 0 {
+		options["LimitNOFILE"] = o.LimitNOFILE
+	}
+	if o.SuccessExitStatus != "" {
+		options["SuccessExitStatus"] = o.SuccessExitStatus
+	}
+	if o.ReloadSignal != "" {
+		options["ReloadSignal"] = o.ReloadSignal
+	}
+	if o.PIDFile != "" {
+		options["PIDFile"] = o.PIDFile
+	}
+	if o.UserService {
+		options["UserService"] = true
+	}
+	if o.LogOutput {
+		options["LogOutput"] = true
+	}
+}
+
+// unitDirectives 返回需要直接写入 unit [Service] 段的指令
+func (o *SystemdOptions) unitDirectives() []string {
+	if o == nil {
+		return nil
+	}
+	var lines []string
+	if o.RestartSec > 0 {
+		lines = append(lines, "RestartSec="+strconv.FormatFloat(o.RestartSec.Seconds(), 'f', -1, 64))
+	}
+	if o.NoNewPrivileges {
+		lines = append(lines, "NoNewPrivileges=true")
+	}
+	if o.ProtectSystem != "" {
+		lines = append(lines, "ProtectSystem="+o.ProtectSystem)
+	}
+	if o.ProtectHome != "" {
+		lines = append(lines, "ProtectHome="+o.ProtectHome)
+	}
+	if o.PrivateTmp {
+		lines = append(lines, "PrivateTmp=true")
+	}
+	if o.MemoryMax != "" {
+		lines = append(lines, "MemoryMax="+o.MemoryMax)
+	}
+	if o.CPUQuota != "" {
+		lines = append(lines, "CPUQuota="+o.CPUQuota)
+	}
+	if len(o.ReadWritePaths) > 0 {
+		lines = append(lines, "ReadWritePaths="+strings.Join(o.ReadWritePaths, " "))
+	}
+	return lines
+}
+
+// apply 将 launchd 选项写入 daemon 选项键
+func (o *LaunchdOptions) apply(options ServiceOptions) {
+	options["KeepAlive"] = o.KeepAlive
+	options["RunAtLoad"] = o.RunAtLoad
+	options["SessionCreate"] = o.SessionCreate
+	if o.UserService {
+		options["UserService"] = true
+	}
+}
+
+// validate 校验 Windows 选项取值
+func (o *WindowsOptions) validate() []error {
+	var errs []error
+	switch o.StartType {
+	case "", "automatic", "manual", "disabled":
+	default:
+		errs = append(errs, fmt.Errorf("windows: invalid StartType %q", o.StartType))
+	}
+	if o.DelayedAutoStart && o.StartType != "" && o.StartType != "automatic" {
+		errs = append(errs, errors.New("windows: DelayedAutoStart requires StartType automatic"))
+	}
+	switch o.OnFailure {
+	case "", "restart", "reboot", "noaction":
+	default:
+		errs = append(errs, fmt.Errorf("windows: invalid OnFailure %q", o.OnFailure))
+	}
+	if o.OnFailureDelay < 0 || o.OnFailureResetPeriod < 0 {
+		errs = append(errs, errors.New("windows: failure durations must not be negative"))
+	}
+	return errs
+}
+
+// apply 将 Windows 选项写入 daemon 选项键
+func (o *WindowsOptions) apply(options ServiceOptions) {
+	if o.StartType != "" {
+		options["StartType"] = o.StartType
+	}
+	if o.DelayedAutoStart {
+		options["DelayedAutoStart"] = true
+	}
+	if o.Password != "" {
+		options["Password"] = o.Password
+	}
+	if o.OnFailure != "" {
+		options["OnFailure"] = o.OnFailure
+	}
+	if o.OnFailureDelay > 0 {
+		options["OnFailureDelayDuration"] = o.OnFailureDelay.String()
+	}
+	if o.OnFailureResetPeriod > 0 {
+		options["OnFailureResetPeriod"] = int(o.OnFailureResetPeriod / time.Second)
+	}
+}
+
+// validatePlatformOptions 校验类型化平台选项
+func (sc *ServiceConfig) validatePlatformOptions() []error {
+	var errs []error
+	if sc.Systemd != nil {
+		errs = append(errs, sc.Systemd.validate()...)
+	}
+	if sc.Windows != nil {
+		errs = append(errs, sc.Windows.validate()...)
+	}
+	return errs
+}
+
+// applyPlatformOptions 将类型化平台选项合并到 options，覆盖同名原始键
+func (sc *ServiceConfig) applyPlatformOptions(options ServiceOptions) {
+	if sc.Systemd != nil {
+		sc.Systemd.apply(options)
+	}
+	if sc.Launchd != nil {
+		sc.Launchd.apply(options)
+	}
+	if sc.Windows != nil {
+		sc.Windows.apply(options)
+	}
+}
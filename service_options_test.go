@@ -0,0 +1,161 @@
+package zcli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	service "github.com/darkit/daemon"
+)
+
+// executeSystemdScript 按 daemon 执行 SystemdScript 的方式渲染 unit：相同的模板函数与模板数据
+func executeSystemdScript(t *testing.T, config *service.Config) string {
+	t.Helper()
+	script, _ := config.Option["SystemdScript"].(string)
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"cmd":       func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"` },
+		"cmdEscape": func(s string) string { return strings.ReplaceAll(s, " ", `\x20`) },
+	}).Parse(script)
+	if err != nil {
+		t.Fatalf("parse SystemdScript: %v\n%s", err, script)
+	}
+	limit, ok := config.Option["LimitNOFILE"].(int)
+	if !ok {
+		limit = -1
+	}
+	restart, _ := config.Option["Restart"].(string)
+	data := struct {
+		*service.Config
+		Path                 string
+		HasOutputFileSupport bool
+		ReloadSignal         string
+		PIDFile              string
+		LimitNOFILE          int
+		Restart              string
+		SuccessExitStatus    string
+		LogOutput            bool
+		LogDirectory         string
+	}{Config: config, Path: config.Executable, HasOutputFileSupport: true, LimitNOFILE: limit, Restart: restart, LogDirectory: "/var/log"}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		t.Fatalf("execute SystemdScript: %v", err)
+	}
+	return out.String()
+}
+
+func TestBuilder_TypedPlatformOptionsValidated(t *testing.T) {
+	_, err := NewBuilder("zh").
+		WithName("typed-app").
+		WithSystemdOptions(SystemdOptions{Restart: "sometimes", MemoryMax: "lots"}).
+		WithWindowsOptions(WindowsOptions{StartType: "manual", DelayedAutoStart: true}).
+		BuildWithError()
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("expected BuildError, got %v", err)
+	}
+	if len(buildErr.Errors) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", buildErr.Errors)
+	}
+}
+
+func TestBuilder_UnknownRawOptionKeysWarn(t *testing.T) {
+	builder := NewBuilder("zh").
+		WithName("raw-app").
+		WithServiceOption("Restart", "on-failure").
+		WithServiceOption("restart", "always")
+	if _, err := builder.BuildWithError(); err != nil {
+		t.Fatalf("unknown raw keys must not fail the build: %v", err)
+	}
+
+	warnings := builder.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"restart"`) {
+		t.Fatalf("expected a single warning for the misspelled key, got %v", warnings)
+	}
+}
+
+func TestTranslateServiceConfig_AppliesTypedOptions(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	svcCfg, _ := sm.baseServiceConfig()
+	svcCfg.Executable = "/usr/bin/demo"
+	svcCfg.Options = ServiceOptions{"Restart": "always"}
+	svcCfg.Systemd = &SystemdOptions{
+		Restart:         "on-failure",
+		RestartSec:      5 * time.Second,
+		LimitNOFILE:     4096,
+		NoNewPrivileges: true,
+		ProtectSystem:   "strict",
+		ReadWritePaths:  []string{"/var/lib/demo"},
+	}
+	svcCfg.Windows = &WindowsOptions{OnFailure: "restart", OnFailureDelay: 2 * time.Second}
+
+	config, err := sm.translateServiceConfig(svcCfg)
+	if err != nil {
+		t.Fatalf("translateServiceConfig: %v", err)
+	}
+	if config.Option["Restart"] != "on-failure" || config.Option["LimitNOFILE"] != 4096 {
+		t.Fatalf("typed systemd options should override raw keys, got %#v", config.Option)
+	}
+	if config.Option["OnFailureDelayDuration"] != "2s" {
+		t.Fatalf("unexpected windows translation: %#v", config.Option)
+	}
+
+	script := executeSystemdScript(t, config)
+	for _, want := range []string{
+		"ExecStart=/usr/bin/demo run\n",
+		"Restart=on-failure\n",
+		"RestartSec=5\n",
+		"NoNewPrivileges=true\n",
+		"ProtectSystem=strict\n",
+		"ReadWritePaths=/var/lib/demo\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("generated unit missing %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestTranslateServiceConfig_EscapesSystemdValues(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	svcCfg, _ := sm.baseServiceConfig()
+	svcCfg.Executable = "/usr/bin/demo"
+	svcCfg.Arguments = []string{"run", "--greeting={{hi}}", "--rate=50%", "it's $HOME"}
+	svcCfg.EnvVars = map[string]string{"FORMAT": `{{.Name}} 100% "ok"`}
+	svcCfg.Systemd = &SystemdOptions{NoNewPrivileges: true}
+
+	config, err := sm.translateServiceConfig(svcCfg)
+	if err != nil {
+		t.Fatalf("translateServiceConfig: %v", err)
+	}
+	unit := executeSystemdScript(t, config)
+	for _, want := range []string{
+		`ExecStart=/usr/bin/demo run --greeting={{hi}} --rate=50%% "it's $$HOME"` + "\n",
+		`Environment="FORMAT={{.Name}} 100%% \"ok\""` + "\n",
+		"NoNewPrivileges=true\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Fatalf("unit missing %q:\n%s", want, unit)
+		}
+	}
+	if strings.Count(unit, "ExecStart=") != 1 {
+		t.Fatalf("the daemon template's ExecStart must be replaced, not duplicated:\n%s", unit)
+	}
+}
+
+func TestTranslateServiceConfig_KeepsCustomSystemdScript(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	svcCfg, _ := sm.baseServiceConfig()
+	svcCfg.Executable = "/usr/bin/demo"
+	svcCfg.Options = ServiceOptions{"SystemdScript": "custom"}
+	svcCfg.Systemd = &SystemdOptions{PrivateTmp: true}
+
+	config, err := sm.translateServiceConfig(svcCfg)
+	if err != nil {
+		t.Fatalf("translateServiceConfig: %v", err)
+	}
+	if config.Option["SystemdScript"] != "custom" {
+		t.Fatalf("custom SystemdScript must be preserved, got %#v", config.Option["SystemdScript"])
+	}
+}
@@ -0,0 +1,293 @@
+package zcli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// controlTimeout 控制通道单次请求的期限，包括服务执行 Pause、Resume 的时间
+const controlTimeout = 30 * time.Second
+
+// 控制通道请求与服务状态
+const (
+	controlPause  = "pause"
+	controlResume = "resume"
+	controlState  = "state"
+
+	stateRunning = "running"
+	statePaused  = "paused"
+)
+
+// Pausable ServiceRunner 可选实现的维护模式接口：pause 后进程与 Run 保持运行，服务停止接收新工作，
+// resume 后恢复。服务管理器不会感知暂停，也不会因此重启进程
+type Pausable interface {
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+}
+
+// pausedKey 运行上下文中保存的暂停状态
+type pausedKey struct{}
+
+// Paused 报告服务是否处于维护模式，ctx 为传给 Run 的上下文；健康检查与就绪探针可据此返回 paused
+func Paused(ctx context.Context) bool {
+	if ctx != nil {
+		if paused, ok := ctx.Value(pausedKey{}).(*atomic.Bool); ok {
+			return paused.Load()
+		}
+	}
+	return false
+}
+
+// pausable 报告服务是否支持维护模式
+func (sm *sManager) pausable() bool {
+	runtime := sm.commands.config.runtime
+	return runtime.Pause != nil && runtime.Resume != nil
+}
+
+// withPauseState 把暂停状态放入运行上下文，供 Paused(ctx) 读取
+func (sm *sManager) withPauseState(ctx context.Context) context.Context {
+	if !sm.pausable() {
+		return ctx
+	}
+	return context.WithValue(ctx, pausedKey{}, &sm.paused)
+}
+
+// stateName 返回运行中服务的状态名
+func (sm *sManager) stateName() string {
+	if sm.paused.Load() {
+		return statePaused
+	}
+	return stateRunning
+}
+
+// setPaused 调用服务的 Pause 或 Resume，状态未变化时直接返回；成功后更新指标并触发 pause、resume 事件
+func (sm *sManager) setPaused(ctx context.Context, paused bool) error {
+	sm.pauseMu.Lock()
+	defer sm.pauseMu.Unlock()
+
+	runtime := sm.commands.config.runtime
+	fn, operation, eventType := runtime.Resume, controlResume, LifecycleResume
+	if paused {
+		fn, operation, eventType = runtime.Pause, controlPause, LifecyclePause
+	}
+	if !sm.running.Load() {
+		return NewError(ErrServicePause).Service(sm.Name()).Operation(operation).Message("service is not running").Build()
+	}
+	if sm.paused.Load() == paused {
+		return nil
+	}
+	if err := fn(ctx); err != nil {
+		return sm.wrapServiceError(err, ErrServicePause, operation)
+	}
+	sm.paused.Store(paused)
+	sm.metrics.setPaused(paused)
+	sm.notifyLifecycle(sm.newLifecycleEvent(eventType))
+	return nil
+}
+
+// controlSocket 返回控制通道：RuntimeDir 下的 <name>.ctl，未配置时使用默认布局的 /run/<name>，
+// 不使用所有人可写的临时目录
+func (sm *sManager) controlSocket() string {
+	dir := sm.currentLayout().RuntimeDir.Path
+	if dir == "" {
+		dir = DefaultServiceLayout(sm.Name()).RuntimeDir.Path
+	}
+	return filepath.Join(dir, sm.Name()+".ctl")
+}
+
+// serveControl 运行期间在控制通道上接收 pause、resume、state 请求，并监听配置的暂停与恢复信号。
+// 返回的函数停止接收并等待进行中的请求，退出后暂停状态清零
+func (sm *sManager) serveControl(ctx context.Context) func() {
+	if !sm.pausable() {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	// 先注册信号再创建控制通道，控制通道可用时信号一定已被接管
+	runtime := sm.commands.config.runtime
+	var signals []os.Signal
+	for _, sig := range []os.Signal{runtime.PauseSignal, runtime.ResumeSignal} {
+		if sig != nil {
+			signals = append(signals, sig)
+		}
+	}
+	if len(signals) > 0 {
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, signals...)
+		wg.Go(func() {
+			defer signal.Stop(received)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case sig := <-received:
+					opCtx, opCancel := context.WithTimeout(ctx, controlTimeout)
+					if err := sm.setPaused(opCtx, sig == runtime.PauseSignal); err != nil {
+						_ = sm.handleError(err)
+					}
+					opCancel()
+				}
+			}
+		})
+	}
+
+	if listener := sm.listenControl(); listener != nil {
+		context.AfterFunc(ctx, func() { _ = listener.Close() })
+		wg.Go(func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				wg.Go(func() { sm.handleControl(ctx, conn) })
+			}
+		})
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+		sm.paused.Store(false)
+		sm.metrics.setPaused(false)
+	}
+}
+
+// listenControl 创建控制通道。已有运行中的实例占用时不接管，失败只记录警告
+func (sm *sManager) listenControl() net.Listener {
+	path := sm.controlSocket()
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		sm.localizer.LogWarning(sm.localizer.GetMessage("controlFailed"), path, "in use by another process")
+		return nil
+	}
+	// 未配置 RuntimeDir 时默认目录可能尚未创建，没有权限创建时 Listen 失败并记录警告
+	_ = os.MkdirAll(filepath.Dir(path), 0o755)
+	_ = os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		sm.localizer.LogWarning(sm.localizer.GetMessage("controlFailed"), path, err)
+		return nil
+	}
+	_ = os.Chmod(path, 0o660)
+	return listener
+}
+
+// handleControl 处理一次请求：读取一行请求，成功回复 "ok <状态>"，失败回复 "error <原因>"
+func (sm *sManager) handleControl(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, controlTimeout)
+	defer cancel()
+	switch request := strings.TrimSpace(line); request {
+	case controlPause, controlResume:
+		err = sm.setPaused(opCtx, request == controlPause)
+	case controlState:
+	default:
+		err = fmt.Errorf("unknown control request %q", request)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(conn, "error %s\n", err)
+		return
+	}
+	_, _ = fmt.Fprintf(conn, "ok %s\n", sm.stateName())
+}
+
+// errControlUnavailable 控制通道无法连接，服务未运行或未启用维护模式
+var errControlUnavailable = errors.New("control channel unavailable")
+
+// controlRequest 向运行中的服务发送控制请求，返回服务当前状态
+func (sm *sManager) controlRequest(ctx context.Context, request string) (string, error) {
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "unix", sm.controlSocket())
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errControlUnavailable, err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	if _, err := fmt.Fprintf(conn, "%s\n", request); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	reply = strings.TrimSpace(reply)
+	if state, ok := strings.CutPrefix(reply, "ok "); ok {
+		return state, nil
+	}
+	return "", errors.New(strings.TrimPrefix(reply, "error "))
+}
+
+// runningState 返回运行中服务的状态名，控制通道不可用时视为正常运行
+func (sm *sManager) runningState(ctx context.Context) string {
+	if !sm.pausable() {
+		return stateRunning
+	}
+	if state, err := sm.controlRequest(ctx, controlState); err == nil && state == statePaused {
+		return statePaused
+	}
+	return stateRunning
+}
+
+// newPauseCmd 创建 pause 或 resume 命令：通过控制通道请求运行中的服务切换维护模式，
+// 控制通道不可用且配置了信号时，向 run --detach 的后台进程发送信号
+func (sm *sManager) newPauseCmd(operation string) *cobra.Command {
+	cmd := sm.buildBaseCommand(operation, sm.localizer.GetOperation(operation))
+	cmd.RunE = sm.wrapRunE(func(cmd *cobra.Command, args []string) error {
+		state, err := sm.controlRequest(sm.commandContext(cmd), operation)
+		if errors.Is(err, errControlUnavailable) {
+			if sent, sigErr := sm.signalPause(operation); sent || sigErr != nil {
+				if sigErr != nil {
+					return sm.wrapServiceError(sigErr, ErrServicePause, operation)
+				}
+				sm.localizer.LogSuccess(sm.Name(), operation)
+				return nil
+			}
+		}
+		if err != nil {
+			return sm.wrapServiceError(err, ErrServicePause, operation)
+		}
+		sm.localizer.LogInfo(sm.Name(), state)
+		return nil
+	})
+	return cmd
+}
+
+// signalPause 向后台进程发送暂停或恢复信号，没有配置信号或找不到进程时返回 false
+func (sm *sManager) signalPause(operation string) (bool, error) {
+	sig := sm.commands.config.runtime.ResumeSignal
+	if operation == controlPause {
+		sig = sm.commands.config.runtime.PauseSignal
+	}
+	if sig == nil {
+		return false, nil
+	}
+	pid, ok := sm.detachedPID()
+	if !ok {
+		return false, nil
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false, err
+	}
+	return true, process.Signal(sig)
+}
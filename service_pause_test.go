@@ -0,0 +1,180 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+// pausableRunner 记录暂停与恢复调用，Pause 可配置为失败
+type pausableRunner struct {
+	pauses, resumes atomic.Int32
+	pauseErr        error
+	ctx             chan context.Context
+}
+
+func (r *pausableRunner) Run(ctx context.Context) error {
+	r.ctx <- ctx
+	<-ctx.Done()
+	return nil
+}
+func (r *pausableRunner) Stop() error  { return nil }
+func (r *pausableRunner) Name() string { return "pausable" }
+func (r *pausableRunner) Pause(context.Context) error {
+	r.pauses.Add(1)
+	return r.pauseErr
+}
+func (r *pausableRunner) Resume(context.Context) error {
+	r.resumes.Add(1)
+	return nil
+}
+
+// startPausableService 以 RuntimeDir 为临时目录运行可暂停服务，等待控制通道可用
+func startPausableService(t *testing.T, runner *pausableRunner) (*sManager, *taskEvents, context.Context, func()) {
+	t.Helper()
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.exitChan = make(chan struct{})
+	sm.commands.config.service.Layout.RuntimeDir.Path = t.TempDir()
+	runtime := sm.commands.config.runtime
+	runtime.Run, runtime.Stop, runtime.Pause, runtime.Resume = runner.Run, runner.Stop, runner.Pause, runner.Resume
+	events := &taskEvents{}
+	runtime.LifecycleHooks = []LifecycleHook{events.hook}
+	runner.ctx = make(chan context.Context, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sm.Run(ctx) }()
+	serviceCtx := <-runner.ctx
+	waitUntil(t, func() bool {
+		_, err := sm.controlRequest(context.Background(), controlState)
+		return err == nil
+	})
+	return sm, events, serviceCtx, func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	}
+}
+
+func TestPause_ControlChannel(t *testing.T) {
+	runner := &pausableRunner{}
+	sm, events, serviceCtx, stop := startPausableService(t, runner)
+
+	for _, step := range []struct {
+		request, want string
+		pauses        int32
+	}{
+		{controlPause, statePaused, 1},
+		{controlPause, statePaused, 1}, // 重复暂停不再调用 Pause
+		{controlState, statePaused, 1},
+		{controlResume, stateRunning, 1},
+	} {
+		state, err := sm.controlRequest(context.Background(), step.request)
+		if err != nil || state != step.want || runner.pauses.Load() != step.pauses {
+			t.Fatalf("%s: got %q/%v with %d pauses, want %q", step.request, state, err, runner.pauses.Load(), step.want)
+		}
+		if step.request == controlState && !Paused(serviceCtx) {
+			t.Fatal("Paused(ctx) should report the maintenance mode")
+		}
+	}
+	if runner.resumes.Load() != 1 || events.count(LifecyclePause, "") != 1 || events.count(LifecycleResume, "") != 1 {
+		t.Fatalf("expected one pause and one resume event, got %+v", events.events)
+	}
+	if _, err := sm.controlRequest(context.Background(), "reload"); err == nil {
+		t.Fatal("unknown requests should fail")
+	}
+
+	_, _ = sm.controlRequest(context.Background(), controlPause)
+	if state := sm.runningState(context.Background()); state != statePaused {
+		t.Fatalf("status should report paused, got %q", state)
+	}
+	stop()
+	if sm.paused.Load() {
+		t.Fatal("paused state should be cleared when the service stops")
+	}
+	if _, err := sm.controlRequest(context.Background(), controlState); !errors.Is(err, errControlUnavailable) {
+		t.Fatalf("control channel should be closed after stop, got %v", err)
+	}
+}
+
+func TestPause_FailureKeepsRunning(t *testing.T) {
+	runner := &pausableRunner{pauseErr: errors.New("broker unreachable")}
+	sm, _, serviceCtx, stop := startPausableService(t, runner)
+	defer stop()
+
+	_, err := sm.controlRequest(context.Background(), controlPause)
+	if err == nil || !strings.Contains(err.Error(), "broker unreachable") {
+		t.Fatalf("pause failure should be reported to the client, got %v", err)
+	}
+	if Paused(serviceCtx) || sm.stateName() != stateRunning {
+		t.Fatal("a failed pause must not change the state")
+	}
+}
+
+func TestPause_SkipsScheduledTasks(t *testing.T) {
+	sm, events := newSchedulerTestManager(t, "@hourly", func(context.Context) error { return nil }, TaskOptions{})
+	sm.paused.Store(true)
+	runner := &taskRunner{sm: sm, task: sm.commands.config.runtime.Tasks[0], wg: &sync.WaitGroup{}}
+	runner.trigger(context.Background())
+	if events.count(LifecycleTaskSkipped, taskSkippedPaused) != 1 {
+		t.Fatalf("tasks should be skipped while paused, got %+v", events.events)
+	}
+}
+
+func TestPause_CommandWithoutRunningService(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.commands.config.service.Layout.RuntimeDir.Path = t.TempDir()
+	cmd := sm.newPauseCmd(controlPause)
+	cmd.SetContext(context.Background())
+	if err := cmd.RunE(cmd, nil); !IsErrorCode(err, ErrServicePause) {
+		t.Fatalf("expected SERVICE_PAUSE, got %v", err)
+	}
+}
+
+func TestControlSocket_DefaultsToRunDir(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	if got, want := sm.controlSocket(), filepath.Join("/run", sm.Name(), sm.Name()+".ctl"); got != want {
+		t.Fatalf("controlSocket() = %q, want %q", got, want)
+	}
+	dir := t.TempDir()
+	sm.commands.config.service.Layout.RuntimeDir.Path = dir
+	if got := sm.controlSocket(); filepath.Dir(got) != dir {
+		t.Fatalf("controlSocket() should live in RuntimeDir, got %q", got)
+	}
+}
+
+func TestBuilder_PausableServiceRunner(t *testing.T) {
+	app, err := NewBuilder("zh").WithName("worker").WithServiceRunner(&pausableRunner{}).BuildWithError()
+	if err != nil {
+		t.Fatalf("BuildWithError: %v", err)
+	}
+	if cmd, _, _ := app.Find([]string{"pause"}); cmd == nil || cmd.Name() != "pause" {
+		t.Fatal("a Pausable runner should get pause and resume commands")
+	}
+
+	// ManagedService 转发被包装 runner 的暂停接口
+	managed := NewManagedService(&pausableRunner{}, nil)
+	app, err = NewBuilder("zh").WithName("worker").WithServiceRunner(managed).BuildWithError()
+	if err != nil {
+		t.Fatalf("BuildWithError: %v", err)
+	}
+	if cmd, _, _ := app.Find([]string{"pause"}); cmd == nil || cmd.Name() != "pause" {
+		t.Fatal("a ManagedService wrapping a Pausable runner should get pause and resume commands")
+	}
+	if _, ok := pausableOf(NewManagedService(&BaseService{}, nil)); ok {
+		t.Fatal("a ManagedService must not report pause support its runner lacks")
+	}
+
+	noop := func(context.Context) error { return nil }
+	_, err = NewBuilder("zh").WithName("worker").WithService(noop).
+		WithPauseSignals(syscall.SIGINT, nil).BuildWithError()
+	if err == nil {
+		t.Fatal("pause signals without a Pausable runner should fail")
+	}
+}
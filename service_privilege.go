@@ -0,0 +1,142 @@
+package zcli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// serviceUserEnvVar install 改变运行用户时写入服务环境，run 据此降权
+const serviceUserEnvVar = "ZCLI_SERVICE_USER"
+
+// processIdentity 描述降权目标
+type processIdentity struct {
+	user   string
+	home   string
+	uid    int
+	gid    int
+	groups []int
+}
+
+// lookupIdentity 解析运行用户的 uid、主组与附加组
+func (sm *sManager) lookupIdentity(username string) (*processIdentity, error) {
+	u, err := sm.sys.lookupUser(username)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return nil, fmt.Errorf("user %s has non-numeric uid %q", username, u.Uid)
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return nil, fmt.Errorf("user %s has non-numeric gid %q", username, u.Gid)
+	}
+
+	identity := &processIdentity{user: u.Username, home: u.HomeDir, uid: uid, gid: gid}
+	groupIDs, err := sm.sys.groupIDs(u)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range groupIDs {
+		if g, err := strconv.Atoi(id); err == nil {
+			identity.groups = append(identity.groups, g)
+		}
+	}
+	if len(identity.groups) == 0 {
+		identity.groups = []int{gid}
+	}
+	return identity, nil
+}
+
+// serviceUser 返回降权的目标用户：install 写入服务环境的用户优先，其次为当前实例配置的用户
+func (sm *sManager) serviceUser() string {
+	if username := os.Getenv(serviceUserEnvVar); username != "" {
+		return username
+	}
+	svcCfg, _ := sm.serviceConfigFor(sm.instance)
+	return svcCfg.Username
+}
+
+// privilegeDropTarget 返回 root 启动时需要切换到的用户，无需降权时 ok 为 false
+func (sm *sManager) privilegeDropTarget() (username string, ok bool) {
+	username = sm.serviceUser()
+	if sm.sys.euid() != 0 || username == "" || username == "root" {
+		return "", false
+	}
+	return username, true
+}
+
+// runPrivilegedPhase 在 root 下执行特权准备函数，然后切换到服务运行用户。
+// 非 root 启动时只执行准备函数；切换失败时返回错误，绝不以 root 继续运行。
+func (sm *sManager) runPrivilegedPhase(ctx context.Context) error {
+	for i, setup := range sm.commands.config.runtime.PrivilegedSetup {
+		if err := setup(ctx); err != nil {
+			return WrapServiceOperationError(fmt.Errorf("privileged setup %d: %w", i+1, err), ErrRuntime, "setup", sm.Name())
+		}
+	}
+
+	username, ok := sm.privilegeDropTarget()
+	if !ok {
+		return nil
+	}
+
+	identity, err := sm.lookupIdentity(username)
+	if err == nil {
+		err = sm.handOverFiles(identity)
+	}
+	if err == nil {
+		err = sm.sys.switchIdentity(identity)
+	}
+	if err != nil {
+		return NewError(ErrPermission).
+			Service(sm.Name()).
+			Operation("drop_privileges").
+			Message(sm.localizer.FormatError("dropPrivileges", username, err)).
+			Context("user", username).
+			Cause(err).
+			Build()
+	}
+
+	// 与登录会话保持一致，避免后续代码按 root 的 HOME 读写文件
+	_ = os.Setenv("USER", identity.user)
+	_ = os.Setenv("LOGNAME", identity.user)
+	if identity.home != "" {
+		_ = os.Setenv("HOME", identity.home)
+	}
+	sm.localizer.LogDetail("user", fmt.Sprintf("%s (uid=%d gid=%d)", identity.user, identity.uid, identity.gid))
+	return nil
+}
+
+// handOverFiles 把 root 阶段已写入的状态、运行与日志目录及其中的历史、调优、PID 与日志文件交给降权目标，
+// 降权后仍能追加历史、删除调优与 PID 文件并轮转日志。显式指定了其他属主的目录保持不变
+func (sm *sManager) handOverFiles(id *processIdentity) error {
+	layout := sm.currentLayout()
+	var paths []string
+	for _, dir := range []ServiceDirectory{layout.StateDir, layout.RuntimeDir, layout.LogDir} {
+		if dir.Path != "" && (dir.Owner == "" || dir.Owner == id.user) {
+			paths = append(paths, dir.Path)
+		}
+	}
+	paths = append(paths, sm.historyFile(), sm.tuningFile())
+	if detachedChild() {
+		if path, err := sm.detachPIDFile(); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	if file, _ := configuredLogSources(sm.commands.config.runtime.LogSinks); file != nil {
+		paths = append(paths, file.Path())
+	}
+
+	var errs []error
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Lchown(path, id.uid, id.gid); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return CombineErrors(errs...)
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin
+
+package zcli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// setProcessIdentity 当前平台不支持切换进程身份
+func setProcessIdentity(*processIdentity) error {
+	return fmt.Errorf("switching users is not supported on %s", runtime.GOOS)
+}
+
+// fileOwner 当前平台无法获取文件属主
+func fileOwner(os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,157 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+// fakePrivilegeDrop 模拟以 root 启动，并记录降权调用
+func fakePrivilegeDrop(t *testing.T, sm *sManager, switchErr error) *[]*processIdentity {
+	t.Helper()
+	// 降权成功后会改写这些环境变量，由 t.Setenv 负责还原
+	for _, key := range []string{"HOME", "USER", "LOGNAME"} {
+		t.Setenv(key, os.Getenv(key))
+	}
+	var calls []*processIdentity
+	sm.sys.euid = func() int { return 0 }
+	sm.sys.switchIdentity = func(id *processIdentity) error {
+		calls = append(calls, id)
+		return switchErr
+	}
+	sm.sys.lookupUser = func(name string) (*user.User, error) {
+		if name != "svc" {
+			return nil, user.UnknownUserError(name)
+		}
+		return &user.User{Username: "svc", Uid: "990", Gid: "990", HomeDir: t.TempDir()}, nil
+	}
+	sm.sys.groupIDs = func(*user.User) ([]string, error) { return []string{"990", "44"}, nil }
+	return &calls
+}
+
+func TestRun_PrivilegedSetupThenDrop(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	calls := fakePrivilegeDrop(t, sm, nil)
+	sm.commands.config.service.Username = "svc"
+
+	var order []string
+	sm.commands.config.runtime.PrivilegedSetup = []SetupFunc{
+		func(context.Context) error {
+			order = append(order, "setup")
+			if len(*calls) != 0 {
+				t.Error("setup must run before privileges are dropped")
+			}
+			return nil
+		},
+	}
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		order = append(order, "run")
+		return errors.New("done")
+	}
+	_ = sm.Run(context.Background())
+
+	if len(order) != 2 || order[0] != "setup" || order[1] != "run" {
+		t.Fatalf("unexpected call order %v", order)
+	}
+	if len(*calls) != 1 {
+		t.Fatalf("expected a single identity switch, got %d", len(*calls))
+	}
+	id := (*calls)[0]
+	if id.uid != 990 || id.gid != 990 || len(id.groups) != 2 || id.groups[1] != 44 {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+}
+
+func TestRun_RefusesToContinueAsRootWhenDropFails(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	fakePrivilegeDrop(t, sm, errors.New("setuid: operation not permitted"))
+	sm.commands.config.service.Username = "svc"
+	ran := false
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		ran = true
+		return nil
+	}
+
+	err := sm.Run(context.Background())
+	if !IsErrorCode(err, ErrPermission) {
+		t.Fatalf("expected ErrPermission, got %v", err)
+	}
+	if ran {
+		t.Fatal("user Run must not be called as root after a failed drop")
+	}
+}
+
+func TestRun_UnknownServiceUserFailsClosed(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	calls := fakePrivilegeDrop(t, sm, nil)
+	sm.commands.config.service.Username = "ghost"
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		t.Error("user Run must not be called")
+		return nil
+	}
+
+	if err := sm.Run(context.Background()); !IsErrorCode(err, ErrPermission) {
+		t.Fatalf("expected ErrPermission, got %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Fatal("identity must not be switched for an unknown user")
+	}
+}
+
+func TestRun_NoDropWhenNotRoot(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	calls := fakePrivilegeDrop(t, sm, nil)
+	sm.sys.euid = func() int { return 1000 }
+	sm.commands.config.service.Username = "svc"
+	sm.commands.config.runtime.Run = func(context.Context) error { return errors.New("done") }
+
+	_ = sm.Run(context.Background())
+	if len(*calls) != 0 {
+		t.Fatal("non-root processes must not switch identity")
+	}
+}
+
+func TestRun_DropsToInstalledUser(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	calls := fakePrivilegeDrop(t, sm, nil)
+	sm.commands.config.runtime.Run = func(context.Context) error { return errors.New("done") }
+
+	// install --user 写入服务环境的用户优先于构建期配置
+	t.Setenv(serviceUserEnvVar, "svc")
+	_ = sm.Run(context.Background())
+	if len(*calls) != 1 || (*calls)[0].user != "svc" {
+		t.Fatalf("expected the installed user to be used, got %+v", *calls)
+	}
+}
+
+func TestRun_HandsOverStateFilesBeforeDrop(t *testing.T) {
+	skipUnlessRoot(t)
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	fakePrivilegeDrop(t, sm, nil)
+	stateDir := filepath.Join(t.TempDir(), "state")
+	sm.commands.config.service.Username = "svc"
+	sm.commands.config.service.Layout.StateDir.Path = stateDir
+
+	// 历史由 root 阶段写入，降权后的追加与改写需要目录与文件都属于服务用户
+	owners := map[string]string{}
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		for _, path := range []string{stateDir, sm.historyFile()} {
+			if info, err := os.Stat(path); err == nil {
+				uid, gid, _ := fileOwner(info)
+				owners[path] = fmt.Sprintf("%d:%d", uid, gid)
+			}
+		}
+		return errors.New("done")
+	}
+	_ = sm.Run(context.Background())
+
+	for _, path := range []string{stateDir, sm.historyFile()} {
+		if owners[path] != "990:990" {
+			t.Fatalf("%s should be handed over to the service user before Run, got %q", path, owners[path])
+		}
+	}
+}
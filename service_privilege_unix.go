@@ -0,0 +1,35 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// setProcessIdentity 依次设置附加组、主组与用户；Go 运行时会把变更同步到所有线程
+func setProcessIdentity(id *processIdentity) error {
+	if err := syscall.Setgroups(id.groups); err != nil {
+		return fmt.Errorf("setgroups: %w", err)
+	}
+	if err := syscall.Setgid(id.gid); err != nil {
+		return fmt.Errorf("setgid: %w", err)
+	}
+	if err := syscall.Setuid(id.uid); err != nil {
+		return fmt.Errorf("setuid: %w", err)
+	}
+	if syscall.Geteuid() != id.uid {
+		return fmt.Errorf("effective uid is still %d", syscall.Geteuid())
+	}
+	return nil
+}
+
+// fileOwner 返回文件的属主与属组
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
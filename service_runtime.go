@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	service "github.com/darkit/daemon"
 	"github.com/spf13/cobra"
 )
 
@@ -108,7 +107,8 @@ func (sm *sManager) waitManagedRun(ctx context.Context) error {
 // Run 实现 ServiceRunner 接口。
 // 它始终保留稳定的命令级上下文，并为用户服务逻辑派生单独的运行上下文。
 // 传入的 externalCtx 仅用于显式的运行期取消，不会替代命令级上下文。
-func (sm *sManager) Run(externalCtx context.Context) error {
+func (sm *sManager) Run(externalCtx context.Context) (runErr error) {
+	begin := sm.sys.now()
 	sm.stopMu.Lock()
 	sm.mu.Lock()
 	session := sm.ensureCommandSessionLocked()
@@ -126,6 +126,9 @@ func (sm *sManager) Run(externalCtx context.Context) error {
 	sm.running.Store(true)
 	sm.mu.Unlock()
 	sm.stopMu.Unlock()
+	// 最先注册、最后执行，退出前的历史与崩溃记录仍能写入日志
+	logCtx, closeLogs := sm.withLogSink(runCtx)
+	defer closeLogs()
 	defer runCancel(nil)
 	defer sm.clearServiceContext(session)
 
@@ -159,9 +162,60 @@ func (sm *sManager) Run(externalCtx context.Context) error {
 	defer sm.running.Store(false)
 	defer sm.cancelForceExit()
 
+	// 崩溃循环时不再调用用户代码；返回的错误由 run 命令在清理完成后映射为专用退出码
+	if err := sm.recordStart(); err != nil {
+		sm.localizer.LogError("runFailed", err)
+		if session.commandCancel != nil {
+			session.commandCancel(err)
+		}
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			sm.recordExit(runCtx, fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+		sm.recordExit(runCtx, runErr)
+	}()
+	sm.metrics.started()
+	defer sm.metrics.stopped()
+	defer sm.metrics.watchStop(runCtx)()
+	// 先于特权阶段监听，降权后仍可使用特权端口
+	defer sm.serveMetrics()()
+
+	// 派生新变量而非覆盖 runCtx，上方的取消监听协程仍在读取 runCtx
+	serviceCtx, clearTuning := sm.applyResourceTuning(sm.withPauseState(sm.withShutdownBudget(logCtx)))
+	defer clearTuning()
+
+	// 特权准备与降权必须在任何用户代码之前完成，失败时不以 root 继续运行
+	if err := sm.runPrivilegedPhase(serviceCtx); err != nil {
+		if session.commandCancel != nil {
+			session.commandCancel(err)
+		}
+		return err
+	}
+
+	// 前置条件未满足前不调用用户 Run；等待期间收到停止信号视为正常退出
+	if err := sm.awaitConditions(serviceCtx); err != nil {
+		if serviceCtx.Err() != nil {
+			return nil
+		}
+		if session.commandCancel != nil {
+			session.commandCancel(err)
+		}
+		return err
+	}
+
+	// 后台子进程在调用用户 Run 前通知父进程已就绪
+	notifyDetachParent(nil)
+	sm.metrics.ready(begin)
+	// 计划任务与用户 Run 并行，退出时先停止调度并等待进行中的运行
+	defer sm.startScheduledTasks(serviceCtx)()
+	defer sm.serveControl(serviceCtx)()
+
 	if sm.commands.config.runtime.Run != nil {
-		if err := sm.commands.config.runtime.Run(runCtx); err != nil {
-			if isExpectedShutdownError(err) && runCtx.Err() != nil {
+		if err := sm.commands.config.runtime.Run(serviceCtx); err != nil {
+			if isExpectedShutdownError(err) && serviceCtx.Err() != nil {
 				return nil
 			}
 			if session.commandCancel != nil {
@@ -174,13 +228,13 @@ func (sm *sManager) Run(externalCtx context.Context) error {
 	// 等待退出信号或上下文取消
 	select {
 	case <-sm.exitChan:
-	case <-runCtx.Done():
+	case <-serviceCtx.Done():
 	}
 
 	// 交互模式下补偿停止
-	if service.Interactive() && !sm.stopExecuted.Load() {
+	if sm.sys.interactive() && !sm.stopExecuted.Load() {
 		return sm.stopWithCause(
-			shutdownCauseFromContext(runCtx, newShutdownCause(ShutdownReasonServiceStop, nil, nil)),
+			shutdownCauseFromContext(serviceCtx, newShutdownCause(ShutdownReasonServiceStop, nil, nil)),
 			true,
 		)
 	}
@@ -230,13 +284,21 @@ func (sm *sManager) stopWithCause(cause error, callUserStop bool) error {
 	return stopErr
 }
 
+// runArguments 返回 run 使用的运行参数：命令行参数优先，否则使用配置的 Arguments
+func (sm *sManager) runArguments(args []string) []string {
+	if len(args) == 0 && len(sm.commands.config.service.Arguments) > 0 {
+		return sm.commands.config.service.Arguments
+	}
+	return args
+}
+
 func (sm *sManager) rebuildServiceForRun(args []string) error {
 	if len(args) == 0 {
 		return nil
 	}
 
 	sm.config.Arguments = args
-	svc, err := service.New(sm.buildRunner(), sm.config)
+	svc, err := newDaemonService(sm.buildRunner(), sm.config)
 	if err != nil {
 		sm.localizer.LogError("createService", err)
 		return WrapError(err, ErrServiceCreate, "run")
@@ -246,19 +308,62 @@ func (sm *sManager) rebuildServiceForRun(args []string) error {
 }
 
 // executeRunCommand 执行运行命令，支持前台和服务模式
-func (sm *sManager) executeRunCommand(_ *cobra.Command, args []string) error {
+func (sm *sManager) executeRunCommand(cmd *cobra.Command, args []string) error {
 	// 如果服务正在运行，显示警告并退出
 	if sm.running.Load() {
 		sm.localizer.LogError("alreadyRunning", nil)
 		return nil
 	}
 
-	// 处理运行参数
-	serviceArgs := args
-	if len(serviceArgs) == 0 && len(sm.commands.config.service.Arguments) > 0 {
-		serviceArgs = sm.commands.config.service.Arguments
+	if sm.initSupervisorRequired() {
+		return sm.runInitSupervisor(args)
+	}
+	if sm.watchRequested(cmd) {
+		return sm.runWatched(cmd, args)
+	}
+	if sm.detachRequested(cmd) {
+		return sm.runDetached(args)
+	}
+	return sm.runService(cmd, args)
+}
+
+// runService 在当前进程中运行服务直到退出
+func (sm *sManager) runService(cmd *cobra.Command, args []string) (runErr error) {
+	// 后台子进程负责 PID 文件，并在退出前把失败原因报告给父进程
+	if detachedChild() {
+		path, err := sm.detachPIDFile()
+		release := func() {}
+		if err == nil {
+			release, err = writePIDFile(path)
+		}
+		if err != nil {
+			notifyDetachParent(err)
+			return sm.wrapServiceError(err, ErrPathInvalid, "run")
+		}
+		defer release()
+		defer func() {
+			if runErr != nil {
+				notifyDetachParent(runErr)
+			} else {
+				notifyDetachParent(errDetachExited)
+			}
+		}()
 	}
 
+	// 前台运行时确认必需依赖已运行，由服务管理器拉起时依赖由平台保证；
+	// 服务定义中的环境变量同样由服务管理器注入，前台运行需自行写入进程环境。容器中没有服务管理器，按前台处理
+	if sm.sys.interactive() || detachedChild() || sm.initMode() {
+		if err := sm.applyProcessEnv(); err != nil {
+			return sm.wrapServiceError(err, ErrConfigInvalid, "run")
+		}
+		if err := sm.checkDependencies(sm.commandContext(cmd), "run", dependencyWait(cmd)); err != nil {
+			return err
+		}
+	}
+
+	// 处理运行参数
+	serviceArgs := sm.runArguments(args)
+
 	sm.mu.Lock()
 	session := sm.newCommandSessionLocked()
 
@@ -328,6 +433,11 @@ func (sm *sManager) waitForServiceCompletion(commandCtx context.Context, runDone
 		return popRunErr()
 
 	case <-commandCtx.Done():
+		// 崩溃循环时 Run 未调用用户代码，无需等待停止，直接返回以便以专用退出码退出
+		if cause := context.Cause(commandCtx); isCrashLoop(cause) {
+			return cause
+		}
+
 		// 收到取消信号，尝试优雅停止
 
 		// 如果是交互式模式，安全地关闭退出通道
@@ -0,0 +1,216 @@
+package zcli
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// TaskFunc 计划任务函数，ctx 在服务停止或单次运行超时后取消
+type TaskFunc func(ctx context.Context) error
+
+// OverlapPolicy 上一次运行尚未结束时再次触发的处理方式
+type OverlapPolicy int
+
+const (
+	OverlapSkip       OverlapPolicy = iota // 跳过本次触发，默认
+	OverlapQueue                           // 上一次结束后立即补跑，最多排队一次
+	OverlapConcurrent                      // 并发运行
+)
+
+// MissedRunPolicy 系统挂起等原因错过触发时间后的处理方式
+type MissedRunPolicy int
+
+const (
+	MissedSkip    MissedRunPolicy = iota // 放弃错过的触发，等待下一次，默认
+	MissedRunOnce                        // 唤醒后补跑一次，无论错过多少次
+)
+
+// TaskOptions 计划任务选项
+type TaskOptions struct {
+	Overlap OverlapPolicy   // 重叠策略
+	Jitter  time.Duration   // 每次触发随机延后 [0, Jitter)，避免多实例同时执行
+	Timeout time.Duration   // 单次运行超时，0 表示不限制
+	Missed  MissedRunPolicy // 错过触发的处理方式
+}
+
+// ScheduledTask 运行期间按计划执行的任务
+type ScheduledTask struct {
+	Name     string
+	Spec     string // 原始计划表达式
+	Schedule Schedule
+	Run      TaskFunc
+	Options  TaskOptions
+}
+
+// 计划任务被跳过的原因，记录在 LifecycleEvent.Skipped
+const (
+	taskSkippedOverlap = "overlap"
+	taskSkippedMissed  = "missed"
+	taskSkippedPaused  = "paused"
+)
+
+// taskRunner 单个计划任务的调度状态
+type taskRunner struct {
+	sm      *sManager
+	task    ScheduledTask
+	wg      *sync.WaitGroup
+	mu      sync.Mutex
+	running int
+	queued  bool
+}
+
+// startScheduledTasks 在服务上下文中启动全部计划任务，返回的函数停止调度并等待进行中的运行结束，
+// 保证任务先于服务其余部分退出
+func (sm *sManager) startScheduledTasks(ctx context.Context) func() {
+	tasks := sm.commands.config.runtime.Tasks
+	if len(tasks) == 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		runner := &taskRunner{sm: sm, task: task, wg: &wg}
+		wg.Go(func() { runner.loop(ctx) })
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// loop 按计划等待并触发任务，直到 ctx 取消或计划不再有后续触发
+func (r *taskRunner) loop(ctx context.Context) {
+	next := r.task.Schedule.Next(r.sm.sys.now())
+	for !next.IsZero() {
+		fireAt := next
+		if jitter := r.task.Options.Jitter; jitter > 0 {
+			fireAt = fireAt.Add(rand.N(jitter))
+		}
+		if !r.sleepUntil(ctx, fireAt) {
+			return
+		}
+
+		now := r.sm.sys.now()
+		following := r.task.Schedule.Next(next)
+		if !following.IsZero() && !now.Before(following.Add(r.task.Options.Jitter)) {
+			// 唤醒时已越过下一次触发，说明期间错过了运行
+			next = r.task.Schedule.Next(now)
+			if r.task.Options.Missed != MissedRunOnce {
+				r.skipped(taskSkippedMissed)
+				continue
+			}
+		} else {
+			next = following
+		}
+		r.trigger(ctx)
+	}
+}
+
+// sleepUntil 分段等待至墙上时钟到达 t，ctx 取消时返回 false
+func (r *taskRunner) sleepUntil(ctx context.Context, t time.Time) bool {
+	t = t.Round(0)
+	for {
+		wait := t.Sub(r.sm.sys.now().Round(0))
+		if wait <= 0 {
+			return true
+		}
+		timer := time.NewTimer(min(wait, r.sm.sys.schedulerTick))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// trigger 按重叠策略启动一次运行，维护模式下跳过
+func (r *taskRunner) trigger(ctx context.Context) {
+	if r.sm.paused.Load() {
+		r.skipped(taskSkippedPaused)
+		return
+	}
+	r.mu.Lock()
+	switch {
+	case r.running == 0 || r.task.Options.Overlap == OverlapConcurrent:
+		r.running++
+		r.mu.Unlock()
+		r.wg.Go(func() { r.execute(ctx) })
+	case r.task.Options.Overlap == OverlapQueue && !r.queued:
+		r.queued = true
+		r.mu.Unlock()
+	default:
+		r.mu.Unlock()
+		r.skipped(taskSkippedOverlap)
+	}
+}
+
+// execute 运行任务，结束时处理排队的触发
+func (r *taskRunner) execute(ctx context.Context) {
+	for {
+		r.runOnce(ctx)
+		r.mu.Lock()
+		if r.queued && ctx.Err() == nil {
+			r.queued = false
+			r.mu.Unlock()
+			continue
+		}
+		r.queued = false
+		r.running--
+		r.mu.Unlock()
+		return
+	}
+}
+
+// runOnce 执行一次任务：失败交给错误处理器链，并以 task_run 事件报告结果
+func (r *taskRunner) runOnce(ctx context.Context) {
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout := r.task.Options.Timeout; timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	start := r.sm.sys.now()
+	err := r.call(runCtx)
+	event := r.sm.newLifecycleEvent(LifecycleTaskRun)
+	event.Task = r.task.Name
+	event.Duration = r.sm.sys.now().Sub(start)
+
+	switch {
+	case err == nil:
+	case ctx.Err() != nil && isExpectedShutdownError(err):
+		// 服务停止导致的取消不算失败
+		err = nil
+	case runCtx.Err() == context.DeadlineExceeded:
+		err = ErrScheduledTaskTimeout(r.sm.Name(), r.task.Name, r.task.Options.Timeout)
+	default:
+		err = ErrScheduledTaskFailed(r.sm.Name(), r.task.Name, err)
+	}
+	if err != nil {
+		event.Error = err.Error()
+		r.sm.localizer.LogWarning(r.sm.localizer.GetMessage("taskFailed"), r.task.Name, err)
+		_ = r.sm.handleError(err)
+	}
+	r.sm.notifyLifecycle(event)
+}
+
+// call 调用任务函数，panic 转为错误，避免单个任务拖垮服务
+func (r *taskRunner) call(ctx context.Context) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r.task.Run(ctx)
+}
+
+// skipped 报告一次被跳过的触发
+func (r *taskRunner) skipped(reason string) {
+	event := r.sm.newLifecycleEvent(LifecycleTaskSkipped)
+	event.Task = r.task.Name
+	event.Skipped = reason
+	r.sm.notifyLifecycle(event)
+}
@@ -0,0 +1,301 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// schedulerTestClock 可手动推进的调度时钟，记录读取次数以便确认调度协程已处理推进
+type schedulerTestClock struct {
+	now   atomic.Int64
+	reads atomic.Int64
+}
+
+func newSchedulerTestClock(sm *sManager) *schedulerTestClock {
+	clock := &schedulerTestClock{}
+	clock.now.Store(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	sm.sys.now = func() time.Time {
+		clock.reads.Add(1)
+		return time.Unix(0, clock.now.Load())
+	}
+	sm.sys.schedulerTick = time.Millisecond
+	return clock
+}
+
+// settle 等待调度协程至少再读取 n 次时钟
+func (c *schedulerTestClock) settle(t *testing.T, n int64) {
+	t.Helper()
+	base := c.reads.Load()
+	waitUntil(t, func() bool { return c.reads.Load() >= base+n })
+}
+
+// advance 推进时钟，并等待调度协程唤醒、处理触发后重新进入等待
+func (c *schedulerTestClock) advance(t *testing.T, d time.Duration) {
+	t.Helper()
+	c.now.Add(int64(d))
+	c.settle(t, 3)
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// taskEvents 收集计划任务事件
+type taskEvents struct {
+	mu     sync.Mutex
+	events []LifecycleEvent
+}
+
+func (e *taskEvents) hook(event LifecycleEvent) {
+	e.mu.Lock()
+	e.events = append(e.events, event)
+	e.mu.Unlock()
+}
+
+func (e *taskEvents) count(eventType LifecycleEventType, skipped string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, event := range e.events {
+		if event.Type == eventType && event.Skipped == skipped {
+			n++
+		}
+	}
+	return n
+}
+
+// newSchedulerTestManager 构造只含一个计划任务的服务管理器
+func newSchedulerTestManager(t *testing.T, spec string, fn TaskFunc, opts TaskOptions) (*sManager, *taskEvents) {
+	t.Helper()
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	events := &taskEvents{}
+	sm.commands.config.runtime.LifecycleHooks = []LifecycleHook{events.hook}
+	sm.commands.config.runtime.Tasks = []ScheduledTask{{Name: "job", Spec: spec, Schedule: schedule, Run: fn, Options: opts}}
+	return sm, events
+}
+
+// blockingTask 每次运行先通知 started，再等待 release
+func blockingTask() (TaskFunc, chan struct{}, chan struct{}, *atomic.Int32) {
+	started, release := make(chan struct{}, 8), make(chan struct{})
+	var runs atomic.Int32
+	return func(context.Context) error {
+		runs.Add(1)
+		started <- struct{}{}
+		<-release
+		return nil
+	}, started, release, &runs
+}
+
+func TestScheduledTask_OverlapPolicies(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		fn, started, release, runs := blockingTask()
+		sm, events := newSchedulerTestManager(t, "@every 1h", fn, TaskOptions{})
+		clock := newSchedulerTestClock(sm)
+		stop := sm.startScheduledTasks(context.Background())
+		clock.settle(t, 2)
+
+		clock.advance(t, time.Hour)
+		<-started
+		clock.advance(t, time.Hour)
+		close(release)
+		stop()
+
+		if runs.Load() != 1 || events.count(LifecycleTaskSkipped, taskSkippedOverlap) != 1 {
+			t.Fatalf("expected 1 run and 1 overlap skip, got %d runs and %+v", runs.Load(), events.events)
+		}
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		fn, started, release, runs := blockingTask()
+		sm, events := newSchedulerTestManager(t, "@every 1h", fn, TaskOptions{Overlap: OverlapQueue})
+		clock := newSchedulerTestClock(sm)
+		stop := sm.startScheduledTasks(context.Background())
+		clock.settle(t, 2)
+
+		clock.advance(t, time.Hour)
+		<-started
+		clock.advance(t, time.Hour) // 排队
+		clock.advance(t, time.Hour) // 队列已满，跳过
+		release <- struct{}{}
+		<-started
+		close(release)
+		stop()
+
+		if runs.Load() != 2 || events.count(LifecycleTaskSkipped, taskSkippedOverlap) != 1 {
+			t.Fatalf("expected 2 runs and 1 overlap skip, got %d runs and %+v", runs.Load(), events.events)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		fn, started, release, runs := blockingTask()
+		sm, _ := newSchedulerTestManager(t, "@every 1h", fn, TaskOptions{Overlap: OverlapConcurrent})
+		clock := newSchedulerTestClock(sm)
+		stop := sm.startScheduledTasks(context.Background())
+		clock.settle(t, 2)
+
+		clock.advance(t, time.Hour)
+		clock.advance(t, time.Hour)
+		<-started
+		<-started
+		close(release)
+		stop()
+
+		if runs.Load() != 2 {
+			t.Fatalf("expected 2 concurrent runs, got %d", runs.Load())
+		}
+	})
+}
+
+func TestScheduledTask_MissedRuns(t *testing.T) {
+	for _, tt := range []struct {
+		policy  MissedRunPolicy
+		runs    int32
+		skipped int
+		name    string
+	}{
+		{MissedSkip, 0, 1, "skip"},
+		{MissedRunOnce, 1, 0, "run once"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs atomic.Int32
+			sm, events := newSchedulerTestManager(t, "@every 1h", func(context.Context) error {
+				runs.Add(1)
+				return nil
+			}, TaskOptions{Missed: tt.policy})
+			clock := newSchedulerTestClock(sm)
+			stop := sm.startScheduledTasks(context.Background())
+			clock.settle(t, 2)
+
+			// 模拟系统挂起：唤醒时已越过多个触发时间
+			clock.advance(t, 3*time.Hour+30*time.Minute)
+			stop()
+
+			if runs.Load() != tt.runs || events.count(LifecycleTaskSkipped, taskSkippedMissed) != tt.skipped {
+				t.Fatalf("expected %d runs and %d missed skips, got %d runs and %+v", tt.runs, tt.skipped, runs.Load(), events.events)
+			}
+		})
+	}
+}
+
+func TestScheduledTask_ReportsFailuresAndTimeouts(t *testing.T) {
+	cause := errors.New("disk full")
+	var calls atomic.Int32
+	sm, events := newSchedulerTestManager(t, "@every 5ms", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			return cause
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}, TaskOptions{Timeout: 10 * time.Millisecond})
+
+	handled := make(chan error, 8)
+	sm.errorHandlers = []ErrorHandler{errorHandlerFunc(func(err error) error {
+		select {
+		case handled <- err:
+		default:
+		}
+		return err
+	})}
+	stop := sm.startScheduledTasks(context.Background())
+	failed, timedOut := <-handled, <-handled
+	stop()
+
+	var serviceErr *ServiceError
+	if !errors.As(failed, &serviceErr) || serviceErr.Code != ErrTaskFailed || serviceErr.Context["task"] != "job" || !errors.Is(failed, cause) {
+		t.Fatalf("expected TASK_FAILED wrapping the cause, got %#v", failed)
+	}
+	if !IsErrorCode(timedOut, ErrTimeout) {
+		t.Fatalf("expected TIMEOUT, got %v", timedOut)
+	}
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	if len(events.events) < 2 || events.events[0].Type != LifecycleTaskRun || events.events[0].Task != "job" || events.events[0].Error == "" {
+		t.Fatalf("task runs should be reported as lifecycle events, got %+v", events.events)
+	}
+}
+
+func TestRun_StopsScheduledTasksBeforeReturning(t *testing.T) {
+	var running, finished atomic.Bool
+	sm, events := newSchedulerTestManager(t, "@every 5ms", func(ctx context.Context) error {
+		running.Store(true)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	}, TaskOptions{})
+	sm.exitChan = make(chan struct{})
+	// 只配置计划任务、没有 Run 也可作为服务运行
+	sm.commands.config.runtime.Run = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sm.Run(ctx) }()
+	waitUntil(t, running.Load)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+	if !finished.Load() {
+		t.Fatal("Run should wait for the in-flight task run")
+	}
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	for _, event := range events.events {
+		if event.Error != "" {
+			t.Fatalf("cancellation on shutdown is not a failure: %+v", event)
+		}
+	}
+}
+
+func TestBuilder_WithScheduledTask(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	app, err := NewBuilder("zh").WithName("tasks").
+		WithScheduledTask("cleanup", "0 3 * * *", noop).
+		WithScheduledTask("sync", "@every 30s", noop, TaskOptions{Overlap: OverlapQueue}).
+		BuildWithError()
+	if err != nil {
+		t.Fatalf("BuildWithError: %v", err)
+	}
+	config := app.Config()
+	tasks := config.Runtime().Tasks
+	if len(tasks) != 2 || tasks[1].Options.Overlap != OverlapQueue {
+		t.Fatalf("unexpected tasks: %+v", tasks)
+	}
+	if cmd, _, _ := app.Find([]string{"run"}); cmd == nil || cmd.Name() != "run" {
+		t.Fatal("a service with only scheduled tasks should have service commands")
+	}
+
+	_, err = NewBuilder("zh").WithName("tasks").
+		WithScheduledTask("bad", "61 * * * *", noop).
+		WithScheduledTask("", "@hourly", noop).
+		WithScheduledTask("nil", "@hourly", nil).
+		WithScheduledTask("cleanup", "@hourly", noop).
+		WithScheduledTask("cleanup", "@daily", noop).
+		BuildWithError()
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Errors) != 4 {
+		t.Fatalf("expected 4 task errors, got %v", err)
+	}
+}
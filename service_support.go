@@ -5,8 +5,6 @@ import (
 	"math"
 	"os"
 	"time"
-
-	service "github.com/darkit/daemon"
 )
 
 // ExitWithTimeout 在指定时间后强制退出程序
@@ -24,7 +22,7 @@ func (sm *sManager) scheduleForceExit(timeout time.Duration) {
 	if timeout <= 0 {
 		return
 	}
-	if service.Interactive() {
+	if sm.sys.interactive() {
 		return
 	}
 	if sm.forceExitOnce.Swap(true) {
@@ -39,6 +37,9 @@ func (sm *sManager) scheduleForceExit(timeout time.Duration) {
 			if msg != "" {
 				_, _ = fmt.Fprintln(os.Stderr, msg)
 			}
+			event := sm.newLifecycleEvent(LifecycleForceExit)
+			event.Error = msg
+			sm.appendHistory(event)
 			exitFunc(1)
 		})
 	}
@@ -108,6 +109,7 @@ func (sm *sManager) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
+	sm.metrics.observeError(err)
 	for _, handler := range sm.errorHandlers {
 		err = handler.HandleError(err)
 	}
@@ -0,0 +1,88 @@
+package zcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"time"
+
+	service "github.com/darkit/daemon"
+)
+
+// serviceSystem 汇集服务管理器依赖的时钟、进程信息与系统操作。
+// 每个 sManager 持有一份，测试替换单个实例的字段而不修改包级状态
+type serviceSystem struct {
+	now            func() time.Time // 生命周期事件、指标与计划任务的时钟
+	interactive    func() bool      // 是否前台运行
+	pid            func() int
+	euid           func() int
+	lookupUser     func(string) (*user.User, error)
+	lookupGroup    func(string) (*user.Group, error)
+	groupIDs       func(*user.User) ([]string, error)
+	switchIdentity func(*processIdentity) error
+	runCommand     func(name string, args ...string) error // 账号管理命令
+	chosenSystem   func() service.System
+	lookJournalctl func() (string, error)
+	runJournalctl  func(ctx context.Context, path string, args []string, out, errOut io.Writer) error
+	reexec         func(executable string, env []string) error // watch 重启时替换当前进程
+	// runtimeCgroupProcs Go 运行时是否已按 cgroup CPU 配额设置 GOMAXPROCS
+	runtimeCgroupProcs func() bool
+
+	statusPollInitial time.Duration // 状态轮询的初始间隔
+	statusPollMax     time.Duration // 状态轮询的最大间隔
+	logFollowInterval time.Duration // 跟随日志文件时的轮询间隔
+	watchPollInterval time.Duration // watch 轮询文件状态的间隔
+	// schedulerTick 计划任务单次等待的最长时间。系统挂起期间单调时钟停止计时，
+	// 分段等待并对照墙上时钟才能在唤醒后及时发现错过的触发
+	schedulerTick time.Duration
+
+	proc       procPaths // cgroup 与 /proc 文件位置
+	recordRoot string    // 安装记录的根目录，仅 root 可写
+}
+
+// defaultServiceSystem 返回使用真实时钟与系统调用的实现
+func defaultServiceSystem() serviceSystem {
+	return serviceSystem{
+		now:            time.Now,
+		interactive:    service.Interactive,
+		pid:            os.Getpid,
+		euid:           os.Geteuid,
+		lookupUser:     user.Lookup,
+		lookupGroup:    user.LookupGroup,
+		groupIDs:       (*user.User).GroupIds,
+		switchIdentity: setProcessIdentity,
+		runCommand:     runSystemCommand,
+		chosenSystem:   service.ChosenSystem,
+		lookJournalctl: func() (string, error) { return exec.LookPath("journalctl") },
+		runJournalctl:  runJournalctl,
+		reexec:         reexecSelf,
+
+		runtimeCgroupProcs: runtimeTracksCgroupCPU,
+
+		statusPollInitial: 100 * time.Millisecond,
+		statusPollMax:     2 * time.Second,
+		logFollowInterval: 250 * time.Millisecond,
+		watchPollInterval: 500 * time.Millisecond,
+		schedulerTick:     time.Minute,
+
+		proc: procPaths{
+			cgroupRoot:  "/sys/fs/cgroup",
+			selfCgroup:  "/proc/self/cgroup",
+			oomScoreAdj: "/proc/self/oom_score_adj",
+		},
+		recordRoot: "/var/lib/zcli",
+	}
+}
+
+// runSystemCommand 执行命令，失败时把合并输出附在错误中
+func runSystemCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// errTuningUnsupported 当前平台不支持的调优项
+var errTuningUnsupported = errors.New("not supported on " + runtime.GOOS)
+
+// rlimitInfinity 表示不限制的资源上限，平台实现负责映射到 RLIM_INFINITY
+const rlimitInfinity = math.MaxUint64
+
+// ResourceTuning 调用 Run 前的进程级资源调优，零值字段不生效。
+// 调优失败只记录告警，不阻止服务启动。
+type ResourceTuning struct {
+	NoFile           uint64  // RLIMIT_NOFILE，同时设置软硬上限
+	Core             string  // RLIMIT_CORE："0" 禁用、"unlimited" 或字节数如 "512M"
+	CgroupGOMAXPROCS bool    // 按 cgroup CPU 配额设置 GOMAXPROCS，已设置 GOMAXPROCS 环境变量时跳过
+	CgroupMemoryRate float64 // 按 cgroup 内存上限的比例设置 debug.SetMemoryLimit，如 0.9；已设置 GOMEMLIMIT 时跳过
+	Umask            string  // 八进制文件创建掩码，如 "027"
+	OOMScoreAdj      int     // /proc/self/oom_score_adj，取值 -1000~1000
+}
+
+// TuningResult 单项调优的结果
+type TuningResult struct {
+	Name  string // nofile、core、gomaxprocs、memlimit、umask、oom_score_adj
+	Value string // 生效值
+	Err   error  // 失败原因
+}
+
+// validate 校验调优参数
+func (t *ResourceTuning) validate() []error {
+	var errs []error
+	if _, err := parseRlimitValue(t.Core); t.Core != "" && err != nil {
+		errs = append(errs, fmt.Errorf("tuning: invalid Core %q", t.Core))
+	}
+	if t.CgroupMemoryRate < 0 || t.CgroupMemoryRate > 1 {
+		errs = append(errs, fmt.Errorf("tuning: CgroupMemoryRate %v must be within (0, 1]", t.CgroupMemoryRate))
+	}
+	if _, err := parseUmask(t.Umask); t.Umask != "" && err != nil {
+		errs = append(errs, fmt.Errorf("tuning: invalid Umask %q", t.Umask))
+	}
+	if t.OOMScoreAdj < -1000 || t.OOMScoreAdj > 1000 {
+		errs = append(errs, fmt.Errorf("tuning: OOMScoreAdj %d must be within -1000..1000", t.OOMScoreAdj))
+	}
+	return errs
+}
+
+// parseRlimitValue 解析 "unlimited" 或带 K/M/G/T 后缀（1024 进制）的字节数
+func parseRlimitValue(text string) (uint64, error) {
+	if text == "unlimited" || text == "infinity" {
+		return rlimitInfinity, nil
+	}
+	shift := 0
+	switch {
+	case strings.HasSuffix(text, "K"):
+		shift = 10
+	case strings.HasSuffix(text, "M"):
+		shift = 20
+	case strings.HasSuffix(text, "G"):
+		shift = 30
+	case strings.HasSuffix(text, "T"):
+		shift = 40
+	}
+	if shift > 0 {
+		text = text[:len(text)-1]
+	}
+	n, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxUint64>>shift {
+		return 0, strconv.ErrRange
+	}
+	return n << shift, nil
+}
+
+// parseUmask 解析八进制 umask
+func parseUmask(text string) (int, error) {
+	mask, err := strconv.ParseUint(text, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if mask > 0o777 {
+		return 0, strconv.ErrRange
+	}
+	return int(mask), nil
+}
+
+// formatRlimit 以可读形式展示资源上限
+func formatRlimit(v uint64) string {
+	if v == rlimitInfinity {
+		return "unlimited"
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// apply 依次执行各项调优并返回结果，cgroup 与 /proc 文件从 sys.proc 读取
+func (t *ResourceTuning) apply(sys *serviceSystem) []TuningResult {
+	proc := sys.proc
+	var results []TuningResult
+	add := func(name, value string, err error) {
+		results = append(results, TuningResult{Name: name, Value: value, Err: err})
+	}
+
+	if t.NoFile > 0 {
+		applied, err := setRlimit(rlimitNoFile, t.NoFile)
+		add("nofile", formatRlimit(applied), err)
+	}
+	if t.Core != "" {
+		limit, _ := parseRlimitValue(t.Core)
+		applied, err := setRlimit(rlimitCore, limit)
+		add("core", formatRlimit(applied), err)
+	}
+	if t.CgroupGOMAXPROCS {
+		value, err := tuneGOMAXPROCS(proc, sys.runtimeCgroupProcs())
+		add("gomaxprocs", value, err)
+	}
+	if t.CgroupMemoryRate > 0 {
+		value, err := tuneMemoryLimit(proc, t.CgroupMemoryRate)
+		add("memlimit", value, err)
+	}
+	if t.Umask != "" {
+		mask, _ := parseUmask(t.Umask)
+		err := setUmask(mask)
+		add("umask", fmt.Sprintf("%04o", mask), err)
+	}
+	if t.OOMScoreAdj != 0 {
+		add("oom_score_adj", strconv.Itoa(t.OOMScoreAdj), proc.setOOMScoreAdj(t.OOMScoreAdj))
+	}
+	return results
+}
+
+// tuneGOMAXPROCS 将 GOMAXPROCS 设为 cgroup CPU 配额向上取整。
+// runtimeManaged 为 true 时运行时已按配额设置默认值并会随配额变化更新，
+// 此时不再覆盖，否则 runtime.GOMAXPROCS 会关闭运行时的自动更新
+func tuneGOMAXPROCS(proc procPaths, runtimeManaged bool) (string, error) {
+	if env := os.Getenv("GOMAXPROCS"); env != "" {
+		return env + " (env)", nil
+	}
+	quota, err := proc.cgroupCPULimit()
+	if err != nil {
+		return strconv.Itoa(runtime.GOMAXPROCS(0)), err
+	}
+	if runtimeManaged {
+		return strconv.Itoa(runtime.GOMAXPROCS(0)) + " (runtime)", nil
+	}
+	procs := runtime.NumCPU()
+	if quota > 0 {
+		procs = min(procs, max(1, int(math.Ceil(quota))))
+	}
+	runtime.GOMAXPROCS(procs)
+	return strconv.Itoa(procs), nil
+}
+
+// runtimeTracksCgroupCPU 报告 Go 运行时是否按 cgroup CPU 配额设置 GOMAXPROCS。
+// Go 1.25 起默认开启，主模块 go 版本较低或 GODEBUG=cgroupgomaxprocs=0 时关闭，后出现的设置优先
+func runtimeTracksCgroupCPU() bool {
+	settings := ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "DefaultGODEBUG" {
+				settings = s.Value
+			}
+		}
+	}
+	enabled := true
+	for setting := range strings.SplitSeq(settings+","+os.Getenv("GODEBUG"), ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(setting), "cgroupgomaxprocs="); ok {
+			enabled = value != "0"
+		}
+	}
+	return enabled
+}
+
+// tuneMemoryLimit 按 cgroup 内存上限的比例设置 Go 运行时软内存上限
+func tuneMemoryLimit(proc procPaths, rate float64) (string, error) {
+	if env := os.Getenv("GOMEMLIMIT"); env != "" {
+		return env + " (env)", nil
+	}
+	limit, err := proc.cgroupMemoryLimit()
+	if err != nil {
+		return "", err
+	}
+	if limit == 0 {
+		return "unlimited", nil
+	}
+	soft := int64(float64(limit) * rate)
+	debug.SetMemoryLimit(soft)
+	return strconv.FormatInt(soft, 10), nil
+}
+
+// procPaths 资源调优读写的 cgroup 与 /proc 文件位置
+type procPaths struct {
+	cgroupRoot  string // cgroup 文件系统挂载点
+	selfCgroup  string // 当前进程的 cgroup 归属
+	oomScoreAdj string // 当前进程的 OOM 调整值文件
+}
+
+// tuningResultsKey 是调优结果在运行上下文中的键
+type tuningResultsKey struct{}
+
+// AppliedTuning 返回本次启动应用的资源调优结果，ctx 为传给 Run 的上下文
+func AppliedTuning(ctx context.Context) []TuningResult {
+	if ctx == nil {
+		return nil
+	}
+	results, _ := ctx.Value(tuningResultsKey{}).([]TuningResult)
+	return append([]TuningResult(nil), results...)
+}
+
+// tuningFile 返回记录本次调优结果的文件：RuntimeDir 下的 <name>.tuning，其次为 StateDir，都未配置时返回空
+func (sm *sManager) tuningFile() string {
+	layout := sm.currentLayout()
+	dir := layout.RuntimeDir.Path
+	if dir == "" {
+		dir = layout.StateDir.Path
+	}
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, sm.Name()+".tuning")
+}
+
+// writeTuningResults 每行写入一项结果：名称、生效值与失败原因，以制表符分隔
+func writeTuningResults(path string, results []TuningResult) error {
+	var b strings.Builder
+	for _, result := range results {
+		reason := ""
+		if result.Err != nil {
+			reason = strings.ReplaceAll(result.Err.Error(), "\n", " ")
+		}
+		_, _ = fmt.Fprintf(&b, "%s\t%s\t%s\n", result.Name, result.Value, reason)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// readTuningResults 读取 writeTuningResults 写入的结果，文件不存在时返回空
+func readTuningResults(path string) []TuningResult {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var results []TuningResult
+	for line := range strings.Lines(string(data)) {
+		fields := strings.SplitN(strings.TrimRight(line, "\n"), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		result := TuningResult{Name: fields[0], Value: fields[1]}
+		if fields[2] != "" {
+			result.Err = errors.New(fields[2])
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// logAppliedTuning 在 status 中展示运行中服务的调优结果
+func (sm *sManager) logAppliedTuning() {
+	path := sm.tuningFile()
+	if path == "" {
+		return
+	}
+	for _, result := range readTuningResults(path) {
+		value := result.Value
+		if result.Err != nil {
+			value = strings.TrimSpace(value + " (" + result.Err.Error() + ")")
+		}
+		sm.localizer.LogDetail(tuningLabels[result.Name], value)
+	}
+}
+
+// tuningLabels 调优项对应的展示标签
+var tuningLabels = map[string]string{
+	"nofile":        "noFile",
+	"core":          "coreLimit",
+	"gomaxprocs":    "maxProcs",
+	"memlimit":      "memoryLimit",
+	"umask":         "umask",
+	"oom_score_adj": "oomScore",
+}
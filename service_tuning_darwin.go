@@ -0,0 +1,8 @@
+//go:build darwin
+
+package zcli
+
+import "syscall"
+
+// platformRlimInfinity macOS 的 RLIM_INFINITY
+const platformRlimInfinity = uint64(syscall.RLIM_INFINITY)
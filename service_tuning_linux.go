@@ -0,0 +1,161 @@
+//go:build linux
+
+package zcli
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// platformRlimInfinity Linux 的 RLIM_INFINITY
+const platformRlimInfinity = ^uint64(0)
+
+// cgroupV1Unlimited v1 内存上限超过该值时视为不限制
+const cgroupV1Unlimited = 1 << 62
+
+// cgroupPaths 解析 /proc/self/cgroup，返回 v2 路径与 v1 各控制器路径
+func (p procPaths) cgroupPaths() (string, map[string]string, error) {
+	f, err := os.Open(p.selfCgroup)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	v2 := ""
+	v1 := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			v2 = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			v1[controller] = parts[2]
+		}
+	}
+	return v2, v1, scanner.Err()
+}
+
+// readCgroupFile 依次在进程所属 cgroup 与挂载根目录下查找文件，
+// 容器内 cgroup 通常挂载在根目录
+func (p procPaths) readCgroupFile(controllerDir, path, name string) (string, error) {
+	candidates := []string{
+		filepath.Join(p.cgroupRoot, controllerDir, path, name),
+		filepath.Join(p.cgroupRoot, controllerDir, name),
+	}
+	var lastErr error
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// cgroupCPULimit 返回 cgroup CPU 配额折算的 CPU 数，未限制时为 0
+func (p procPaths) cgroupCPULimit() (float64, error) {
+	v2, v1, err := p.cgroupPaths()
+	if err != nil {
+		return 0, err
+	}
+
+	if limit, found, err := p.cgroupV2CPULimit(v2); found || err != nil {
+		return limit, err
+	}
+
+	path := v1["cpu"]
+	quota, err := p.readCgroupFile("cpu", path, "cpu.cfs_quota_us")
+	if err != nil {
+		return 0, err
+	}
+	if strings.HasPrefix(quota, "-") {
+		return 0, nil
+	}
+	period, err := p.readCgroupFile("cpu", path, "cpu.cfs_period_us")
+	if err != nil {
+		return 0, err
+	}
+	return cpuQuota(quota, period)
+}
+
+// cgroupV2CPULimit 从进程所属 cgroup 逐级向上读取 cpu.max，取各级配额中最小的一个，
+// 上级 cgroup 的配额同样约束当前进程。容器内 cgroup 挂载在根目录时由根目录的 cpu.max 兜底
+func (p procPaths) cgroupV2CPULimit(path string) (limit float64, found bool, err error) {
+	dir := filepath.Join("/", path)
+	for {
+		data, readErr := os.ReadFile(filepath.Join(p.cgroupRoot, dir, "cpu.max"))
+		if readErr == nil {
+			found = true
+			fields := strings.Fields(string(data))
+			if len(fields) == 2 && fields[0] != "max" {
+				quota, err := cpuQuota(fields[0], fields[1])
+				if err != nil {
+					return 0, true, err
+				}
+				if limit == 0 || quota < limit {
+					limit = quota
+				}
+			}
+		}
+		if dir == "/" {
+			return limit, found, nil
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// cpuQuota 计算 quota/period
+func cpuQuota(quota, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, err
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p <= 0 {
+		return 0, errors.New("invalid cgroup cpu period")
+	}
+	return q / p, nil
+}
+
+// cgroupMemoryLimit 返回 cgroup 内存上限字节数，未限制时为 0
+func (p procPaths) cgroupMemoryLimit() (uint64, error) {
+	v2, v1, err := p.cgroupPaths()
+	if err != nil {
+		return 0, err
+	}
+
+	if text, err := p.readCgroupFile("", v2, "memory.max"); err == nil {
+		if text == "max" {
+			return 0, nil
+		}
+		return strconv.ParseUint(text, 10, 64)
+	}
+
+	text, err := p.readCgroupFile("memory", v1["memory"], "memory.limit_in_bytes")
+	if err != nil {
+		return 0, err
+	}
+	limit, err := strconv.ParseUint(text, 10, 64)
+	if err != nil || limit >= cgroupV1Unlimited {
+		return 0, err
+	}
+	return limit, nil
+}
+
+// setOOMScoreAdj 写入 OOM killer 调整值；降低该值需要 CAP_SYS_RESOURCE
+func (p procPaths) setOOMScoreAdj(score int) error {
+	return os.WriteFile(p.oomScoreAdj, []byte(strconv.Itoa(score)), 0o644)
+}
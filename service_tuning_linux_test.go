@@ -0,0 +1,97 @@
+//go:build linux
+
+package zcli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// fakeCgroup 在临时目录中构造 cgroup 文件与 /proc/self/cgroup
+func fakeCgroup(t *testing.T, selfCgroup string, files map[string]string) procPaths {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	self := filepath.Join(root, "self-cgroup")
+	if err := os.WriteFile(self, []byte(selfCgroup), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	return procPaths{cgroupRoot: root, selfCgroup: self}
+}
+
+func TestCgroupLimits_V2(t *testing.T) {
+	proc := fakeCgroup(t, "0::/system.slice/demo.service\n", map[string]string{
+		"system.slice/demo.service/cpu.max":    "150000 100000\n",
+		"system.slice/demo.service/memory.max": "536870912\n",
+	})
+
+	if cpus, err := proc.cgroupCPULimit(); err != nil || cpus != 1.5 {
+		t.Fatalf("cgroupCPULimit = %v, %v", cpus, err)
+	}
+	if mem, err := proc.cgroupMemoryLimit(); err != nil || mem != 512<<20 {
+		t.Fatalf("cgroupMemoryLimit = %v, %v", mem, err)
+	}
+}
+
+func TestCgroupLimits_V2ParentQuota(t *testing.T) {
+	proc := fakeCgroup(t, "0::/kubepods/pod1/app\n", map[string]string{
+		"kubepods/pod1/app/cpu.max": "max 100000\n",
+		"kubepods/pod1/cpu.max":     "50000 100000\n",
+		"kubepods/cpu.max":          "400000 100000\n",
+	})
+	if cpus, err := proc.cgroupCPULimit(); err != nil || cpus != 0.5 {
+		t.Fatalf("the tightest ancestor quota should apply, got %v, %v", cpus, err)
+	}
+}
+
+func TestTuneGOMAXPROCS_KeepsRuntimeDefault(t *testing.T) {
+	t.Setenv("GOMAXPROCS", "")
+	prev := runtime.GOMAXPROCS(0)
+	t.Cleanup(func() { runtime.GOMAXPROCS(prev) })
+	proc := fakeCgroup(t, "0::/demo\n", map[string]string{"demo/cpu.max": "100000 100000\n"})
+
+	value, err := tuneGOMAXPROCS(proc, true)
+	if err != nil || value != strconv.Itoa(prev)+" (runtime)" || runtime.GOMAXPROCS(0) != prev {
+		t.Fatalf("runtime default should be kept, got %q, %v", value, err)
+	}
+	if value, err := tuneGOMAXPROCS(proc, false); err != nil || value != "1" || runtime.GOMAXPROCS(0) != 1 {
+		t.Fatalf("quota should be applied without the runtime default, got %q, %v", value, err)
+	}
+}
+
+func TestCgroupLimits_V1Unlimited(t *testing.T) {
+	proc := fakeCgroup(t, "4:cpu,cpuacct:/docker/abc\n7:memory:/docker/abc\n", map[string]string{
+		"cpu/cpu.cfs_quota_us":         "-1\n",
+		"cpu/cpu.cfs_period_us":        "100000\n",
+		"memory/memory.limit_in_bytes": "9223372036854771712\n",
+	})
+
+	if cpus, err := proc.cgroupCPULimit(); err != nil || cpus != 0 {
+		t.Fatalf("cgroupCPULimit = %v, %v", cpus, err)
+	}
+	if mem, err := proc.cgroupMemoryLimit(); err != nil || mem != 0 {
+		t.Fatalf("cgroupMemoryLimit = %v, %v", mem, err)
+	}
+}
+
+func TestSetOOMScoreAdj(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oom_score_adj")
+	if err := (procPaths{oomScoreAdj: path}).setOOMScoreAdj(-500); err != nil {
+		t.Fatalf("setOOMScoreAdj: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "-500" {
+		t.Fatalf("unexpected oom_score_adj %q", data)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package zcli
+
+// cgroupCPULimit 非 Linux 平台没有 cgroup
+func (procPaths) cgroupCPULimit() (float64, error) {
+	return 0, errTuningUnsupported
+}
+
+// cgroupMemoryLimit 非 Linux 平台没有 cgroup
+func (procPaths) cgroupMemoryLimit() (uint64, error) {
+	return 0, errTuningUnsupported
+}
+
+// setOOMScoreAdj 非 Linux 平台没有 OOM killer 调整值
+func (procPaths) setOOMScoreAdj(int) error {
+	return errTuningUnsupported
+}
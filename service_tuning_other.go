@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package zcli
+
+const (
+	rlimitNoFile = iota
+	rlimitCore
+)
+
+// setRlimit 当前平台不支持资源上限
+func setRlimit(int, uint64) (uint64, error) {
+	return 0, errTuningUnsupported
+}
+
+// setUmask 当前平台不支持 umask
+func setUmask(int) error {
+	return errTuningUnsupported
+}
@@ -0,0 +1,126 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	service "github.com/darkit/daemon"
+)
+
+func TestParseRlimitValue(t *testing.T) {
+	cases := map[string]uint64{
+		"0":         0,
+		"4096":      4096,
+		"512M":      512 << 20,
+		"2G":        2 << 30,
+		"unlimited": rlimitInfinity,
+	}
+	for input, want := range cases {
+		got, err := parseRlimitValue(input)
+		if err != nil || got != want {
+			t.Fatalf("parseRlimitValue(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	if _, err := parseRlimitValue("lots"); err == nil {
+		t.Fatal("expected error for non-numeric limit")
+	}
+}
+
+func TestBuilder_RejectsInvalidResourceTuning(t *testing.T) {
+	_, err := NewBuilder("zh").
+		WithName("tuned-app").
+		WithResourceTuning(ResourceTuning{
+			Core:             "huge",
+			CgroupMemoryRate: 1.5,
+			Umask:            "0999",
+			OOMScoreAdj:      2000,
+		}).
+		BuildWithError()
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Errors) != 4 {
+		t.Fatalf("expected 4 tuning errors, got %v", err)
+	}
+}
+
+func TestRun_ReportsAppliedTuning(t *testing.T) {
+	t.Setenv("GOMAXPROCS", "3")
+	t.Setenv("GOMEMLIMIT", "256MiB")
+
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	var out bytes.Buffer
+	sm.localizer.ConfigureOutput(&out, &out, false, false)
+	sm.commands.config.runtime.Tuning = &ResourceTuning{CgroupGOMAXPROCS: true, CgroupMemoryRate: 0.9}
+
+	var seen []TuningResult
+	sm.commands.config.runtime.Run = func(ctx context.Context) error {
+		seen = AppliedTuning(ctx)
+		return errors.New("done")
+	}
+	_ = sm.Run(context.Background())
+
+	if len(seen) != 2 || seen[0].Value != "3 (env)" || seen[1].Value != "256MiB (env)" {
+		t.Fatalf("unexpected tuning results: %+v", seen)
+	}
+	if !strings.Contains(out.String(), "GOMAXPROCS") || !strings.Contains(out.String(), "256MiB (env)") {
+		t.Fatalf("tuning should be reported in the startup log:\n%s", out.String())
+	}
+}
+
+func TestStatus_ShowsAppliedTuning(t *testing.T) {
+	t.Setenv("GOMAXPROCS", "3")
+
+	sm := newTestServiceManager(t, &fakeDaemonService{status: service.StatusRunning})
+	sm.exitChan = make(chan struct{})
+	var out bytes.Buffer
+	sm.localizer.ConfigureOutput(&out, &out, false, false)
+	sm.commands.config.service.Layout.RuntimeDir.Path = t.TempDir()
+	sm.commands.config.runtime.Tuning = &ResourceTuning{CgroupGOMAXPROCS: true}
+
+	status := func() string {
+		out.Reset()
+		cmd := sm.newStatusCmd()
+		cmd.SetContext(context.Background())
+		if err := cmd.RunE(cmd, nil); err != nil {
+			t.Fatalf("status: %v", err)
+		}
+		return out.String()
+	}
+
+	started := make(chan struct{})
+	sm.commands.config.runtime.Run = func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sm.Run(ctx) }()
+	<-started
+
+	if text := status(); !strings.Contains(text, "GOMAXPROCS") || !strings.Contains(text, "3 (env)") {
+		t.Fatalf("status should show the applied tuning:\n%s", text)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, err := os.Stat(sm.tuningFile()); !os.IsNotExist(err) {
+		t.Fatalf("tuning file should be removed when Run exits, stat err=%v", err)
+	}
+}
+
+func TestRuntimeTracksCgroupCPU_FollowsGODEBUG(t *testing.T) {
+	t.Setenv("GODEBUG", "cgroupgomaxprocs=0")
+	if runtimeTracksCgroupCPU() {
+		t.Fatal("GODEBUG=cgroupgomaxprocs=0 should disable the runtime default")
+	}
+	t.Setenv("GODEBUG", "cgroupgomaxprocs=0,cgroupgomaxprocs=1")
+	if !runtimeTracksCgroupCPU() {
+		t.Fatal("the last GODEBUG setting should win")
+	}
+}
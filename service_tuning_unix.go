@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package zcli
+
+import "syscall"
+
+const (
+	rlimitNoFile = syscall.RLIMIT_NOFILE
+	rlimitCore   = syscall.RLIMIT_CORE
+)
+
+// setRlimit 设置资源软硬上限；无权提高硬上限时退回到当前硬上限
+func setRlimit(resource int, limit uint64) (uint64, error) {
+	if limit == rlimitInfinity {
+		limit = platformRlimInfinity
+	}
+	if err := syscall.Setrlimit(resource, &syscall.Rlimit{Cur: limit, Max: limit}); err == nil {
+		return toRlimitValue(limit), nil
+	}
+
+	var current syscall.Rlimit
+	if err := syscall.Getrlimit(resource, &current); err != nil {
+		return 0, err
+	}
+	soft := min(limit, current.Max)
+	if err := syscall.Setrlimit(resource, &syscall.Rlimit{Cur: soft, Max: current.Max}); err != nil {
+		return toRlimitValue(current.Cur), err
+	}
+	return toRlimitValue(soft), nil
+}
+
+// toRlimitValue 将平台的 RLIM_INFINITY 映射为 rlimitInfinity
+func toRlimitValue(v uint64) uint64 {
+	if v == platformRlimInfinity {
+		return rlimitInfinity
+	}
+	return v
+}
+
+// setUmask 设置进程文件创建掩码
+func setUmask(mask int) error {
+	syscall.Umask(mask)
+	return nil
+}
@@ -0,0 +1,111 @@
+package zcli
+
+import (
+	"context"
+	"time"
+
+	service "github.com/darkit/daemon"
+	"github.com/spf13/cobra"
+)
+
+// defaultWaitTimeout 在未配置任何超时时使用的等待上限
+const defaultWaitTimeout = 30 * time.Second
+
+// waitOptions 描述 start/stop/restart 的等待语义。
+type waitOptions struct {
+	wait    bool
+	timeout time.Duration
+}
+
+// bindWaitFlags 为服务控制命令注册 --wait / --timeout 参数。
+func (sm *sManager) bindWaitFlags(cmd *cobra.Command, o *waitOptions) {
+	flags := cmd.Flags()
+	flags.BoolVar(&o.wait, "wait", false, sm.localizer.GetFlag("wait"))
+	flags.DurationVar(&o.timeout, "timeout", 0, sm.localizer.GetFlag("timeout"))
+}
+
+// effectiveTimeout 返回等待目标状态时使用的超时：
+// 优先使用 --timeout，其次是 Builder 配置的启动/停止超时，最后回退到默认值。
+func (sm *sManager) effectiveTimeout(o *waitOptions, target service.Status) time.Duration {
+	if o != nil && o.timeout > 0 {
+		return o.timeout
+	}
+	runtime := sm.commands.config.runtime
+	switch target {
+	case service.StatusRunning:
+		if runtime.StartTimeout > 0 {
+			return runtime.StartTimeout
+		}
+	case service.StatusStopped:
+		if runtime.StopTimeout > 0 {
+			return runtime.StopTimeout
+		}
+	}
+	return defaultWaitTimeout
+}
+
+// commandContext 返回命令执行上下文，直接调用 RunE 时回退到配置上下文。
+func (sm *sManager) commandContext(cmd *cobra.Command) context.Context {
+	if cmd != nil && cmd.Context() != nil {
+		return cmd.Context()
+	}
+	return sm.commands.config.Context()
+}
+
+// waitForStatus 以指数退避轮询服务状态，直到达到目标状态或超时。
+// 等待停止时，服务未安装同样视为已到达目标状态。只比较服务管理器报告的状态，不探测服务就绪与健康。
+func (sm *sManager) waitForStatus(ctx context.Context, target service.Status, timeout time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deadline := time.Now().Add(timeout)
+	interval := sm.sys.statusPollInitial
+
+	var lastErr error
+	for {
+		status, err := sm.queryServiceStatus()
+		switch {
+		case err == nil && status == target:
+			return nil
+		case err != nil && target == service.StatusStopped && isNotInstalled(err):
+			return nil
+		case err != nil:
+			lastErr = err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return sm.waitTimeoutError(target, timeout, lastErr)
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return WrapServiceOperationError(ctx.Err(), ErrContextCancelled, "wait", sm.Name())
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > sm.sys.statusPollMax {
+			interval = sm.sys.statusPollMax
+		}
+	}
+}
+
+// waitTimeoutError 构造等待超时错误，并保留最后一次状态查询失败的原因。
+func (sm *sManager) waitTimeoutError(target service.Status, timeout time.Duration, cause error) error {
+	var err *ServiceError
+	if target == service.StatusStopped {
+		err = ErrServiceStopTimeout(sm.Name(), timeout)
+	} else {
+		err = ErrServiceStartTimeout(sm.Name(), timeout)
+	}
+	if cause != nil {
+		err.WithCause(cause)
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package zcli
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	service "github.com/darkit/daemon"
+)
+
+// sequenceDaemonService 按顺序返回预设状态，序列耗尽后保持最后一个状态。
+type sequenceDaemonService struct {
+	fakeDaemonService
+	mu       sync.Mutex
+	statuses []service.Status
+	events   []string
+}
+
+func (s *sequenceDaemonService) Status() (service.Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, "status")
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	return status, nil
+}
+
+func (s *sequenceDaemonService) Start() error {
+	s.mu.Lock()
+	s.events = append(s.events, "start")
+	s.mu.Unlock()
+	return s.fakeDaemonService.Start()
+}
+
+func (s *sequenceDaemonService) Stop() error {
+	s.mu.Lock()
+	s.events = append(s.events, "stop")
+	s.mu.Unlock()
+	return s.fakeDaemonService.Stop()
+}
+
+func shortenStatusPolling(sm *sManager) {
+	sm.sys.statusPollInitial = time.Millisecond
+	sm.sys.statusPollMax = 5 * time.Millisecond
+}
+
+func TestStartCommand_WaitPollsUntilRunning(t *testing.T) {
+	stub := &sequenceDaemonService{statuses: []service.Status{
+		service.StatusStopped,
+		service.StatusStopped,
+		service.StatusStopped,
+		service.StatusRunning,
+	}}
+	sm := newTestServiceManager(t, stub)
+	shortenStatusPolling(sm)
+
+	cmd := sm.newStartCmd()
+	if err := cmd.ParseFlags([]string{"--wait", "--timeout", "1s"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("start --wait: %v", err)
+	}
+	if stub.startCalled != 1 {
+		t.Fatalf("expected a single start call, got %d", stub.startCalled)
+	}
+}
+
+func TestStartCommand_WaitTimesOut(t *testing.T) {
+	stub := &sequenceDaemonService{statuses: []service.Status{service.StatusStopped}}
+	sm := newTestServiceManager(t, stub)
+	shortenStatusPolling(sm)
+
+	cmd := sm.newStartCmd()
+	if err := cmd.ParseFlags([]string{"--wait", "--timeout", "20ms"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	err := cmd.RunE(cmd, nil)
+	if !IsErrorCode(err, ErrServiceTimeout) {
+		t.Fatalf("expected ErrServiceTimeout, got %v", err)
+	}
+	if serviceErr, _ := GetServiceError(err); serviceErr.Operation != "start" {
+		t.Fatalf("expected start timeout, got operation %q", serviceErr.Operation)
+	}
+}
+
+func TestStopCommand_WaitTreatsNotInstalledAsStopped(t *testing.T) {
+	stub := &fakeDaemonService{status: service.StatusRunning}
+	sm := newTestServiceManager(t, stub)
+	shortenStatusPolling(sm)
+
+	if err := sm.waitForStatus(context.Background(), service.StatusStopped, 50*time.Millisecond); !IsErrorCode(err, ErrServiceTimeout) {
+		t.Fatalf("expected stop timeout while still running, got %v", err)
+	}
+
+	stub.statusErr = service.ErrNotInstalled
+	if err := sm.waitForStatus(context.Background(), service.StatusStopped, 50*time.Millisecond); err != nil {
+		t.Fatalf("expected not-installed to satisfy stopped, got %v", err)
+	}
+}
+
+func TestRestartCommand_WaitsForStopBeforeStart(t *testing.T) {
+	stub := &sequenceDaemonService{statuses: []service.Status{
+		service.StatusRunning, // 初始状态
+		service.StatusRunning, // 停止后首次轮询仍在运行
+		service.StatusStopped,
+		service.StatusRunning,
+	}}
+	sm := newTestServiceManager(t, stub)
+	shortenStatusPolling(sm)
+
+	cmd := sm.newRestartCmd()
+	if err := cmd.ParseFlags([]string{"--wait", "--timeout", "1s"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("restart --wait: %v", err)
+	}
+
+	want := []string{"status", "stop", "status", "status", "start", "status"}
+	if len(stub.events) != len(want) {
+		t.Fatalf("unexpected call sequence: %v", stub.events)
+	}
+	for i := range want {
+		if stub.events[i] != want[i] {
+			t.Fatalf("unexpected call sequence: %v", stub.events)
+		}
+	}
+}
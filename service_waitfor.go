@@ -0,0 +1,181 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// WaitCondition 描述调用 Run 前需要满足的前置条件。
+// Check 返回 nil 表示条件已满足，否则按退避间隔重试直到 Timeout。
+type WaitCondition struct {
+	Name     string                          // 展示名称
+	Check    func(ctx context.Context) error // 条件检查
+	Timeout  time.Duration                   // 等待上限，0 使用默认 30s
+	Interval time.Duration                   // 初始重试间隔，0 使用默认值
+
+	err error // 构造时的参数错误，构建期报告
+}
+
+// WaitForTCP 等待 TCP 地址可连接
+func WaitForTCP(address string, timeout time.Duration) WaitCondition {
+	cond := WaitCondition{Name: "tcp " + address, Timeout: timeout}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		cond.err = err
+	}
+	cond.Check = func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	return cond
+}
+
+// WaitForSocket 等待 unix socket 文件出现
+func WaitForSocket(path string, timeout time.Duration) WaitCondition {
+	cond := WaitCondition{Name: "socket " + path, Timeout: timeout}
+	if path == "" {
+		cond.err = errors.New("socket path is required")
+	}
+	cond.Check = func(context.Context) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s is not a socket", path)
+		}
+		return nil
+	}
+	return cond
+}
+
+// WaitForFile 等待文件或目录存在
+func WaitForFile(path string, timeout time.Duration) WaitCondition {
+	cond := WaitCondition{Name: "file " + path, Timeout: timeout}
+	if path == "" {
+		cond.err = errors.New("file path is required")
+	}
+	cond.Check = func(context.Context) error {
+		_, err := os.Stat(path)
+		return err
+	}
+	return cond
+}
+
+// WaitForHTTP 等待 HTTP(S) 地址返回 2xx
+func WaitForHTTP(rawURL string, timeout time.Duration) WaitCondition {
+	cond := WaitCondition{Name: "http " + rawURL, Timeout: timeout}
+	if u, err := url.Parse(rawURL); err != nil {
+		cond.err = err
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		cond.err = fmt.Errorf("invalid http url %q", rawURL)
+	}
+	cond.Check = func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	}
+	return cond
+}
+
+// WaitForFunc 使用自定义检查函数作为前置条件
+func WaitForFunc(name string, check func(ctx context.Context) error, timeout time.Duration) WaitCondition {
+	return WaitCondition{Name: name, Check: check, Timeout: timeout}
+}
+
+// validate 校验前置条件定义
+func (c *WaitCondition) validate() error {
+	switch {
+	case c.err != nil:
+		return fmt.Errorf("wait condition %s: %w", c.Name, c.err)
+	case c.Name == "":
+		return errors.New("wait condition name is required")
+	case c.Check == nil:
+		return fmt.Errorf("wait condition %s: check function is required", c.Name)
+	case c.Timeout < 0 || c.Interval < 0:
+		return fmt.Errorf("wait condition %s: durations must not be negative", c.Name)
+	}
+	return nil
+}
+
+// awaitConditions 依次等待所有前置条件满足；ctx 取消时返回 ctx 的错误
+func (sm *sManager) awaitConditions(ctx context.Context) error {
+	for _, cond := range sm.commands.config.runtime.WaitFor {
+		if err := sm.awaitCondition(ctx, cond); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// awaitCondition 以指数退避重试单个前置条件，首次失败时通过本地化器提示等待
+func (sm *sManager) awaitCondition(ctx context.Context, cond WaitCondition) error {
+	timeout := cond.Timeout
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+	interval := cond.Interval
+	if interval <= 0 {
+		interval = sm.sys.statusPollInitial
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reported := false
+	for {
+		lastErr := cond.Check(waitCtx)
+		if lastErr == nil {
+			if reported {
+				sm.localizer.LogDetail("ready", cond.Name)
+			}
+			return nil
+		}
+		if !reported {
+			sm.localizer.LogWarning(sm.localizer.GetMessage("waitingFor"), cond.Name, lastErr)
+			reported = true
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return NewError(ErrServiceTimeout).
+				Service(sm.Name()).
+				Operation("wait").
+				Messagef("condition %s not met within %v", cond.Name, timeout).
+				Context("condition", cond.Name).
+				Context("timeout", timeout.String()).
+				Cause(lastErr).
+				Build()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > sm.sys.statusPollMax {
+			interval = sm.sys.statusPollMax
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package zcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAwaitConditions_RetriesUntilSatisfied(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	shortenStatusPolling(sm)
+	var out bytes.Buffer
+	sm.localizer.ConfigureOutput(&out, &out, false, false)
+
+	var calls atomic.Int32
+	sm.commands.config.runtime.WaitFor = []WaitCondition{
+		WaitForFunc("db", func(context.Context) error {
+			if calls.Add(1) < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}, time.Second),
+	}
+
+	if err := sm.awaitConditions(context.Background()); err != nil {
+		t.Fatalf("awaitConditions: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 checks, got %d", calls.Load())
+	}
+	if strings.Count(out.String(), "connection refused") != 1 {
+		t.Fatalf("waiting should be reported once:\n%s", out.String())
+	}
+}
+
+func TestWaitForTCPAndHTTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	shortenStatusPolling(sm)
+	sm.commands.config.runtime.WaitFor = []WaitCondition{
+		WaitForTCP(ln.Addr().String(), time.Second),
+		WaitForHTTP(srv.URL+"/healthz", time.Second),
+	}
+	if err := sm.awaitConditions(context.Background()); err != nil {
+		t.Fatalf("awaitConditions: %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("expected the 503 to be retried, got %d requests", hits.Load())
+	}
+}
+
+func TestRun_ConditionTimeoutSkipsUserRun(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	shortenStatusPolling(sm)
+	var ran atomic.Bool
+	sm.commands.config.runtime.Run = func(context.Context) error {
+		ran.Store(true)
+		return nil
+	}
+	sm.commands.config.runtime.WaitFor = []WaitCondition{
+		WaitForFile(t.TempDir()+"/missing.sock", 20*time.Millisecond),
+	}
+
+	err := sm.Run(context.Background())
+	if !IsErrorCode(err, ErrServiceTimeout) {
+		t.Fatalf("expected ErrServiceTimeout, got %v", err)
+	}
+	if ran.Load() {
+		t.Fatal("user Run must not be called when a condition is not met")
+	}
+}
+
+func TestRun_CancelWhileWaitingIsGraceful(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.commands.config.runtime.WaitFor = []WaitCondition{
+		WaitForFunc("never", func(context.Context) error { return errors.New("not yet") }, time.Minute),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := sm.Run(ctx); err != nil {
+		t.Fatalf("cancel during wait should exit cleanly, got %v", err)
+	}
+}
+
+func TestBuilder_RejectsInvalidWaitConditions(t *testing.T) {
+	_, err := NewBuilder("zh").
+		WithName("wait-app").
+		WithWaitFor(
+			WaitForHTTP("localhost:8080/health", time.Second),
+			WaitForTCP("no-port", time.Second),
+			WaitCondition{Name: "empty"},
+		).
+		BuildWithError()
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Errors) != 3 {
+		t.Fatalf("expected 3 wait condition errors, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package zcli
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchDebounce 最后一次变更后等待的静默时长，避免构建过程中多次写入触发多次重启
+const defaultWatchDebounce = 500 * time.Millisecond
+
+// watchOptions run --watch 的参数
+type watchOptions struct {
+	executable string
+	paths      []string // 含可执行文件
+	rebuild    string
+	debounce   time.Duration
+	environ    []string // 写入环境变量文件之前的进程环境，重新执行时使用
+}
+
+// fileStamp 判断文件是否变更的状态
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// bindWatchFlags 为 run 命令注册 --watch、--rebuild、--debounce 参数
+func (sm *sManager) bindWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("watch", false, sm.localizer.GetFlag("watch"))
+	cmd.Flags().String("rebuild", "", sm.localizer.GetFlag("rebuild"))
+	cmd.Flags().Duration("debounce", defaultWatchDebounce, sm.localizer.GetFlag("debounce"))
+}
+
+// watchRequested 返回本次 run 是否以 watch 模式运行，只在终端前台生效
+func (sm *sManager) watchRequested(cmd *cobra.Command) bool {
+	if cmd == nil || detachedChild() || !sm.sys.interactive() || sm.initMode() {
+		return false
+	}
+	watch, _ := cmd.Flags().GetBool("watch")
+	return watch
+}
+
+// runWatched 以 watch 模式运行：-- 之前的参数为监视路径，之后的为运行参数
+func (sm *sManager) runWatched(cmd *cobra.Command, args []string) error {
+	if sm.detachRequested(cmd) {
+		return NewError(ErrConfigInvalid).
+			Service(sm.Name()).
+			Operation("run").
+			Message("--watch cannot be combined with --detach").
+			Build()
+	}
+	// 降权后重新执行的进程不再是 root，无法再次执行特权准备与降权
+	if username, ok := sm.privilegeDropTarget(); ok {
+		return NewError(ErrConfigInvalid).
+			Service(sm.Name()).
+			Operation("run").
+			Message("--watch cannot be combined with dropping privileges to "+username).
+			Context("user", username).
+			Build()
+	}
+	exe, err := executablePath()
+	if err != nil {
+		return sm.wrapServiceError(err, ErrExecutableInvalid, "run")
+	}
+
+	paths, serviceArgs := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		paths, serviceArgs = args[:dash], args[dash:]
+	}
+	// 此时环境变量文件尚未写入进程环境，重新执行时由新进程重新读取，修改后的值才能生效
+	opts := watchOptions{executable: exe, paths: append([]string{exe}, paths...), environ: os.Environ()}
+	opts.rebuild, _ = cmd.Flags().GetString("rebuild")
+	opts.debounce, _ = cmd.Flags().GetDuration("debounce")
+	return sm.watch(opts, func() error { return sm.runService(cmd, serviceArgs) })
+}
+
+// watch 运行服务并在监视路径变更时重启：以 ShutdownReasonReload 完整地优雅停止，执行重建命令后重新执行自身。
+// 服务的 Runner、HTTP 服务与特权准备都不能在同一进程中再次运行，因此重启总是替换进程。
+// 服务自行退出时返回，失败退出则等待变更后重启
+func (sm *sManager) watch(opts watchOptions, run func() error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := watchChanges(ctx, opts.paths, opts.debounce, sm.sys.watchPollInterval)
+	done := make(chan error, 1)
+	go func() { done <- run() }()
+
+	select {
+	case changed := <-changes:
+		cancel()
+		sm.localizer.LogWarning(sm.localizer.GetMessage("watchRestart"), changed)
+		if err := sm.stopForReload(done); err != nil {
+			sm.localizer.LogError("runFailed", err)
+		}
+	case err := <-done:
+		cancel()
+		if err == nil {
+			return nil
+		}
+		sm.localizer.LogError("runFailed", err)
+		sm.localizer.LogWarning("%s", sm.localizer.GetMessage("watchWaiting"))
+		changed, ok := sm.awaitChange(opts)
+		if !ok {
+			return err
+		}
+		sm.localizer.LogWarning(sm.localizer.GetMessage("watchRestart"), changed)
+	}
+
+	// 重建失败时不启动旧版本，等待下一次变更后重试
+	for opts.rebuild != "" {
+		err := rebuildCommand(opts.rebuild).Run()
+		if err == nil {
+			break
+		}
+		sm.localizer.LogWarning(sm.localizer.GetMessage("rebuildFailed"), err)
+		if _, ok := sm.awaitChange(opts); !ok {
+			return nil
+		}
+	}
+
+	if err := sm.sys.reexec(opts.executable, opts.environ); err != nil {
+		return sm.wrapServiceError(err, ErrServiceRestart, "run")
+	}
+	return nil
+}
+
+// stopForReload 以 ShutdownReasonReload 停止服务并等待 run 返回。
+// 变更可能在 Run 重置停止状态之前到达，此时停止请求会被覆盖，因此在 run 返回前按轮询间隔重试
+func (sm *sManager) stopForReload(done <-chan error) error {
+	ticker := time.NewTicker(sm.sys.watchPollInterval)
+	defer ticker.Stop()
+	for {
+		_ = sm.stopWithCause(newShutdownCause(ShutdownReasonReload, nil, nil), true)
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// awaitChange 服务未运行时等待变更，收到中断信号时返回 false
+func (sm *sManager) awaitChange(opts watchOptions) (string, bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	changed, ok := <-watchChanges(ctx, opts.paths, opts.debounce, sm.sys.watchPollInterval)
+	return changed, ok
+}
+
+// watchChanges 以启动时的状态为基准每隔 interval 轮询 paths，检测到变更并静默 debounce 后报告一次变更的路径；
+// ctx 取消时关闭通道
+func watchChanges(ctx context.Context, paths []string, debounce, interval time.Duration) <-chan string {
+	changes := make(chan string, 1)
+	prev := snapshotFiles(paths)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var pending string
+		var last time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				current := snapshotFiles(paths)
+				if changed := changedPath(prev, current); changed != "" {
+					prev, last = current, now
+					if pending == "" {
+						pending = changed
+					}
+					continue
+				}
+				if pending != "" && now.Sub(last) >= debounce {
+					changes <- pending
+					return
+				}
+			}
+		}
+	}()
+	return changes
+}
+
+// snapshotFiles 记录 paths 下所有文件的状态，目录递归展开并跳过隐藏目录，不存在的路径忽略
+func snapshotFiles(paths []string) map[string]fileStamp {
+	files := make(map[string]fileStamp)
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if path != root && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := entry.Info(); err == nil {
+				files[path] = fileStamp{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// changedPath 返回两次快照间新增、删除或修改的文件中按字典序最小的一个，没有变化时返回空串
+func changedPath(prev, current map[string]fileStamp) string {
+	var changed []string
+	for path, stamp := range current {
+		if old, ok := prev[path]; !ok || old != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+	return slices.Min(changed)
+}
+
+// rebuildCommand 通过系统 shell 执行重建命令，输出到当前终端
+func rebuildCommand(line string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", line)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", line)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
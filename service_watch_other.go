@@ -0,0 +1,29 @@
+//go:build !linux && !darwin
+
+package zcli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// reexecSelf 当前平台不能原地替换进程，以相同参数与给定环境在当前终端运行新的可执行文件，并以其退出码退出
+func reexecSelf(exe string, env []string) error {
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitFunc(exitErr.ExitCode())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	exitFunc(0)
+	return nil
+}
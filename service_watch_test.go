@@ -0,0 +1,212 @@
+package zcli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shortWatchPoll 是测试使用的文件轮询间隔
+const shortWatchPoll = 5 * time.Millisecond
+
+func writeWatchFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestWatchChanges_DebouncesBurstOfWrites(t *testing.T) {
+	dir := t.TempDir()
+	changes := watchChanges(context.Background(), []string{dir}, 50*time.Millisecond, shortWatchPoll)
+
+	for i := range 3 {
+		writeWatchFile(t, filepath.Join(dir, "conf", strconv.Itoa(i)+".yaml"), "v")
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case changed := <-changes:
+		if changed != filepath.Join(dir, "conf", "0.yaml") {
+			t.Fatalf("expected the first changed file, got %q", changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+	if _, ok := <-changes; ok {
+		t.Fatal("a burst of writes should be reported once")
+	}
+}
+
+func TestWatchChanges_IgnoresHiddenDirs(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := watchChanges(ctx, []string{dir}, time.Millisecond, shortWatchPoll)
+
+	writeWatchFile(t, filepath.Join(dir, ".git", "index"), "v")
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if changed, ok := <-changes; ok {
+		t.Fatalf("hidden directories should not be watched, got %q", changed)
+	}
+}
+
+// useWatchReexec 记录重新执行的可执行文件，代替替换当前进程
+func useWatchReexec(sm *sManager) <-chan watchExec {
+	execs := make(chan watchExec, 1)
+	sm.sys.reexec = func(exe string, env []string) error {
+		execs <- watchExec{exe: exe, env: env}
+		return nil
+	}
+	return execs
+}
+
+// watchExec 一次重新执行的可执行文件与环境
+type watchExec struct {
+	exe string
+	env []string
+}
+
+func newWatchTestManager(t *testing.T) (*sManager, chan struct{}, func() []ShutdownReason) {
+	t.Helper()
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.exitChan = make(chan struct{})
+	sm.sys.watchPollInterval = shortWatchPoll
+	started := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var reasons []ShutdownReason
+	sm.commands.config.runtime.Run = func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		cause, _ := GetShutdownCause(ctx)
+		mu.Lock()
+		reasons = append(reasons, cause.Reason)
+		mu.Unlock()
+		return nil
+	}
+	return sm, started, func() []ShutdownReason {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(reasons)
+	}
+}
+
+func awaitWatch(t *testing.T, done <-chan error, execs <-chan watchExec, exe string) watchExec {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("watch: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not return")
+	}
+	select {
+	case got := <-execs:
+		if got.exe != exe {
+			t.Fatalf("reexec %q, want %q", got.exe, exe)
+		}
+		return got
+	default:
+		t.Fatal("a restart must re-execute the process")
+	}
+	return watchExec{}
+}
+
+func TestWatch_RestartsWithReloadCause(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "bin", "app")
+	writeWatchFile(t, exe, "binary")
+	sm, started, reasons := newWatchTestManager(t)
+	execs := useWatchReexec(sm)
+
+	marker := filepath.Join(dir, "rebuilt")
+	opts := watchOptions{
+		executable: exe,
+		paths:      []string{exe, filepath.Join(dir, "src")},
+		rebuild:    "touch " + marker,
+		debounce:   10 * time.Millisecond,
+		environ:    []string{"PATH=/usr/bin"},
+	}
+	done := make(chan error, 1)
+	go func() { done <- sm.watch(opts, func() error { return sm.Run(nil) }) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not start")
+	}
+	// 可执行文件未变化时同样重新执行，不在当前进程中再次运行服务
+	writeWatchFile(t, filepath.Join(dir, "src", "main.go"), "package main")
+	// 使用写入环境变量文件之前的环境，新进程才能读到修改后的文件
+	if got := awaitWatch(t, done, execs, exe); !slices.Equal(got.env, opts.environ) {
+		t.Fatalf("reexec env %v, want %v", got.env, opts.environ)
+	}
+
+	if got := reasons(); len(got) != 1 || got[0] != ShutdownReasonReload {
+		t.Fatalf("the restart should stop the service with the reload reason, got %v", got)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("rebuild should run before re-executing: %v", err)
+	}
+}
+
+func TestWatch_ChangeBeforeRunStartsStillStops(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "app")
+	writeWatchFile(t, exe, "binary")
+	sm, _, reasons := newWatchTestManager(t)
+	execs := useWatchReexec(sm)
+
+	// 变更在 Run 重置停止状态之前到达
+	src := filepath.Join(dir, "src", "main.go")
+	run := func() error {
+		writeWatchFile(t, src, "package main")
+		time.Sleep(100 * time.Millisecond)
+		return sm.Run(nil)
+	}
+	opts := watchOptions{executable: exe, paths: []string{exe, filepath.Dir(src)}, debounce: time.Millisecond}
+	done := make(chan error, 1)
+	go func() { done <- sm.watch(opts, run) }()
+
+	awaitWatch(t, done, execs, exe)
+	if got := reasons(); len(got) != 1 || got[0] != ShutdownReasonReload {
+		t.Fatalf("the late Run should still be stopped for the reload, got %v", got)
+	}
+}
+
+func TestRunWatched_RejectsDetach(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.sys.interactive = func() bool { return true }
+	cmd := sm.newRunCmd()
+	if err := cmd.ParseFlags([]string{"--watch", "--detach"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if !sm.watchRequested(cmd) {
+		t.Fatal("--watch should be honoured in an interactive terminal")
+	}
+	if err := sm.runWatched(cmd, nil); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("expected CONFIG_INVALID, got %v", err)
+	}
+}
+
+func TestRunWatched_RejectsPrivilegeDrop(t *testing.T) {
+	sm := newTestServiceManager(t, &fakeDaemonService{})
+	sm.sys.interactive = func() bool { return true }
+	sm.sys.euid = func() int { return 0 }
+	t.Setenv(serviceUserEnvVar, "svc")
+	cmd := sm.newRunCmd()
+	if err := cmd.ParseFlags([]string{"--watch"}); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if err := sm.runWatched(cmd, nil); !IsErrorCode(err, ErrConfigInvalid) {
+		t.Fatalf("expected CONFIG_INVALID, got %v", err)
+	}
+}
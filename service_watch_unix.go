@@ -0,0 +1,13 @@
+//go:build linux || darwin
+
+package zcli
+
+import (
+	"os"
+	"syscall"
+)
+
+// reexecSelf 以相同参数与给定环境原地执行新的可执行文件，成功时不返回
+func reexecSelf(exe string, env []string) error {
+	return syscall.Exec(exe, os.Args, env)
+}
@@ -14,6 +14,7 @@ const (
 	ShutdownReasonSignal         ShutdownReason = "signal"
 	ShutdownReasonServiceStop    ShutdownReason = "service_stop"
 	ShutdownReasonExternalCancel ShutdownReason = "external_cancel"
+	ShutdownReasonReload         ShutdownReason = "reload"
 )
 
 // ShutdownCause 表示传递给 Run(ctx) 的统一关闭原因。
@@ -37,6 +38,8 @@ func (c *ShutdownCause) Error() string {
 		return "service shutdown requested by signal"
 	case ShutdownReasonServiceStop:
 		return "service stop requested"
+	case ShutdownReasonReload:
+		return "service reload requested"
 	case ShutdownReasonExternalCancel:
 		if c.Cause != nil && !errors.Is(c.Cause, context.Canceled) {
 			return fmt.Sprintf("service canceled by parent context: %v", c.Cause)
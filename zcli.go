@@ -19,9 +19,15 @@ var systemCmdOrder = map[string]int{
 	"start":     2,
 	"stop":      3,
 	"status":    4,
-	"restart":   5,
-	"install":   6,
-	"uninstall": 7,
+	"logs":      5,
+	"restart":   6,
+	"install":   7,
+	"uninstall": 8,
+	"export":    9,
+	"list":      10,
+	"doctor":    11,
+	"pause":     12,
+	"resume":    13,
 }
 
 // applyBuilderAssembly 统一收束 Builder 到 App/Cli 的装配顺序。